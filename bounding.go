@@ -0,0 +1,226 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Like the hulls, the bounding shapes are built from every vertex of a geometry with arcs linearized with the default
+// options, take the SRID of g and drop any Z and M values. Each returns ErrNoGeometry when g is nil.
+
+// MinimumBoundingRadius returns the centre and radius of the smallest circle holding every point of g. An empty
+// geometry has an empty centre and a radius of 0.
+func MinimumBoundingRadius(g Geometry) (*Point, float64, error) {
+	pts, err := hullPoints(g)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(pts) == 0 {
+		return emptyPoint(g.SRID()), 0, nil
+	}
+
+	c, r := boundingCircle(convexHull(pts))
+
+	return &Point{Hdr{XY, g.SRID()}, Coordinate{c[0], c[1]}}, r, nil
+}
+
+// MinimumBoundingCircle returns the smallest circle holding every point of g as a Polygon, linearized with the
+// options given. The circle of a single point is that Point and that of an empty geometry an empty Polygon.
+func MinimumBoundingCircle(g Geometry, opts ...LinearizeOption) (Geometry, error) {
+	c, r, err := MinimumBoundingRadius(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case math.IsNaN(c.Coordinate[0]):
+		return &Polygon{c.Hdr, nil}, nil
+	case r == 0:
+		return c, nil
+	}
+
+	x, y := c.Coordinate[0], c.Coordinate[1]
+	ring := &CircularString{c.Hdr, []Coordinate{{x + r, y}, {x - r, y}, {x + r, y}}}
+
+	return Linearize(&CurvePolygon{c.Hdr, []Geometry{ring}}, opts...)
+}
+
+// MinimumRotatedRectangle returns the rectangle of least area holding every point of g, which may be turned to any
+// angle. One side of the rectangle lies along an edge of the convex hull and its shell is counter-clockwise.
+func MinimumRotatedRectangle(g Geometry) (Geometry, error) {
+	pts, err := hullPoints(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hull := convexHull(pts)
+
+	if len(hull) < 3 {
+		return hullGeometry(g.SRID(), hull), nil
+	}
+
+	var rect []xy
+
+	area := math.Inf(1)
+
+	calipers(hull, func(o, u, v xy, lo, hi, width float64, far int) {
+		if a := (hi - lo) * width; a < area {
+			area = a
+			c0 := xy{o[0] + u[0]*lo, o[1] + u[1]*lo}
+			c1 := xy{o[0] + u[0]*hi, o[1] + u[1]*hi}
+			rect = []xy{c0, c1, {c1[0] + v[0]*width, c1[1] + v[1]*width}, {c0[0] + v[0]*width, c0[1] + v[1]*width}}
+		}
+	})
+
+	return hullGeometry(g.SRID(), rect), nil
+}
+
+// MinimumDiameter returns the narrowest width of g as a line from a side of its convex hull to the point of the hull
+// farthest from that side. The line has no length when the points of g lie on a line and no points when g is empty.
+func MinimumDiameter(g Geometry) (*LineString, error) {
+	pts, err := hullPoints(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := Hdr{XY, g.SRID()}
+	hull := convexHull(pts)
+
+	switch len(hull) {
+	case 0:
+		return &LineString{hdr, nil}, nil
+	case 1, 2:
+		return &LineString{hdr, []Coordinate{{hull[0][0], hull[0][1]}, {hull[0][0], hull[0][1]}}}, nil
+	}
+
+	var line []Coordinate
+
+	min := math.Inf(1)
+
+	calipers(hull, func(o, u, v xy, lo, hi, width float64, far int) {
+		if width < min {
+			min = width
+			p := hull[far]
+			line = []Coordinate{{p[0] - v[0]*width, p[1] - v[1]*width}, {p[0], p[1]}}
+		}
+	})
+
+	return &LineString{hdr, line}, nil
+}
+
+// boundingCircle returns the centre and radius of the smallest circle around the points by Welzl's algorithm, taking
+// the points in a shuffled but repeatable order
+func boundingCircle(pts []xy) (xy, float64) {
+	pts = append([]xy{}, pts...)
+	rnd := rand.New(rand.NewSource(1))
+
+	rnd.Shuffle(len(pts), func(i, j int) {
+		pts[i], pts[j] = pts[j], pts[i]
+	})
+
+	c, r := pts[0], 0.0
+
+	outside := func(p xy) bool {
+		return math.Hypot(p[0]-c[0], p[1]-c[1]) > r*(1+1e-12)
+	}
+
+	for i := 1; i < len(pts); i++ {
+		if !outside(pts[i]) {
+			continue
+		}
+
+		c, r = pts[i], 0
+
+		for j := 0; j < i; j++ {
+			if !outside(pts[j]) {
+				continue
+			}
+
+			c = xy{(pts[i][0] + pts[j][0]) / 2, (pts[i][1] + pts[j][1]) / 2}
+			r = math.Hypot(pts[i][0]-c[0], pts[i][1]-c[1])
+
+			for k := 0; k < j; k++ {
+				if outside(pts[k]) {
+					c[0], c[1] = circumcentre(pts[i], pts[j], pts[k])
+					r = math.Hypot(pts[i][0]-c[0], pts[i][1]-c[1])
+				}
+			}
+		}
+	}
+
+	return c, r
+}
+
+// calipers calls fn for each edge of a counter-clockwise convex hull with the start o of the edge, its direction u
+// and the normal v pointing into the hull. lo and hi give the extent of the hull along u from o and width its extent
+// along v, reached at the point far. The extreme points are found by rotating calipers, each moving on around the
+// hull as the edges turn.
+func calipers(hull []xy, fn func(o, u, v xy, lo, hi, width float64, far int)) {
+	n := len(hull)
+	dot := func(k int, o, d xy) float64 {
+		p := hull[k%n]
+		return (p[0]-o[0])*d[0] + (p[1]-o[1])*d[1]
+	}
+
+	// advance moves k on while the next point lies farther along d
+	advance := func(k int, o, d xy) int {
+		for steps := 0; steps < n && dot(k+1, o, d) > dot(k, o, d); steps++ {
+			k++
+		}
+
+		return k % n
+	}
+
+	lo, hi, far := 0, 0, 0
+
+	for i := 0; i < n; i++ {
+		o, q := hull[i], hull[(i+1)%n]
+		l := math.Hypot(q[0]-o[0], q[1]-o[1])
+		u := xy{(q[0] - o[0]) / l, (q[1] - o[1]) / l}
+		v := xy{-u[1], u[0]}
+
+		// the first edge finds the extreme points by looking at them all
+		if i == 0 {
+			for k := range hull {
+				if dot(k, o, u) > dot(hi, o, u) {
+					hi = k
+				}
+
+				if dot(k, o, v) > dot(far, o, v) {
+					far = k
+				}
+
+				if dot(k, o, u) < dot(lo, o, u) {
+					lo = k
+				}
+			}
+		}
+
+		hi = advance(hi, o, u)
+		far = advance(far, o, v)
+		lo = advance(lo, o, xy{-u[0], -u[1]})
+
+		fn(o, u, v, dot(lo, o, u), dot(hi, o, u), dot(far, o, v), far)
+	}
+}
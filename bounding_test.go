@@ -0,0 +1,110 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinimumBoundingRadius(t *testing.T) {
+	datasets := []struct {
+		name   string
+		g      Geometry
+		centre Coordinate
+		radius float64
+	}{
+		{"square", box(0, 0, 2, 2), Coordinate{1, 1}, math.Sqrt2},
+		{"obtuse", multiPoint(Coordinate{0, 0}, Coordinate{4, 0}, Coordinate{2, 1}), Coordinate{2, 0}, 2},
+		{"point", multiPoint(Coordinate{3, 4}), Coordinate{3, 4}, 0},
+	}
+
+	for _, dataset := range datasets {
+		c, r, err := MinimumBoundingRadius(dataset.g)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.centre, c.Coordinate, dataset.name)
+		assert.Equal(t, dataset.radius, r, dataset.name)
+	}
+
+	c, r, err := MinimumBoundingRadius(multiPoint())
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(c.Coordinate[0]))
+	assert.Equal(t, 0.0, r)
+
+	_, _, err = MinimumBoundingRadius(nil)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestMinimumBoundingCircle(t *testing.T) {
+	g, err := MinimumBoundingCircle(multiPoint(Coordinate{0, 0}, Coordinate{4, 0}, Coordinate{2, 1}), WithSegmentsPerQuadrant(4))
+
+	if assert.NoError(t, err) && assert.IsType(t, &Polygon{}, g) {
+		ring := g.(*Polygon).Rings[0].Coordinates
+		assert.Len(t, ring, 17)
+
+		for _, c := range ring {
+			assert.InDelta(t, 2, math.Hypot(c[0]-2, c[1]), 1e-12)
+		}
+	}
+
+	g, err = MinimumBoundingCircle(multiPoint(Coordinate{3, 4}))
+	assert.NoError(t, err)
+	assert.Equal(t, &Point{Hdr{XY, 0}, Coordinate{3, 4}}, g)
+
+	g, err = MinimumBoundingCircle(multiPoint())
+	assert.NoError(t, err)
+	assert.Equal(t, &Polygon{Hdr{XY, 0}, nil}, g)
+}
+
+func TestMinimumRotatedRectangle(t *testing.T) {
+	g, err := MinimumRotatedRectangle(box(0, 0, 4, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, box(0, 0, 4, 1), g)
+
+	g, err = MinimumRotatedRectangle(multiPoint(Coordinate{1, 0}, Coordinate{2, 1}, Coordinate{1, 2}, Coordinate{0, 1}, Coordinate{1, 1}))
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, Area(g), 1e-12)
+	assert.InDelta(t, 4*math.Sqrt2, Perimeter(g), 1e-12)
+
+	g, err = MinimumRotatedRectangle(multiPoint(Coordinate{0, 0}, Coordinate{1, 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}}}, g)
+}
+
+func TestMinimumDiameter(t *testing.T) {
+	datasets := []struct {
+		name     string
+		g        Geometry
+		expected []Coordinate
+	}{
+		{"box", box(0, 0, 4, 1), []Coordinate{{4, 0}, {4, 1}}},
+		{"line", multiPoint(Coordinate{0, 0}, Coordinate{1, 1}), []Coordinate{{0, 0}, {0, 0}}},
+		{"empty", multiPoint(), nil},
+	}
+
+	for _, dataset := range datasets {
+		l, err := MinimumDiameter(dataset.g)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, l.Coordinates, dataset.name)
+	}
+
+	l, err := MinimumDiameter(multiPoint(Coordinate{1, 0}, Coordinate{2, 1}, Coordinate{1, 2}, Coordinate{0, 1}))
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt2, Length(l), 1e-12)
+}
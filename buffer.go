@@ -0,0 +1,692 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// CapStyle is the shape Buffer gives to the ends of lines
+type CapStyle int
+
+const (
+	// CapRound ends lines with a half circle
+	CapRound CapStyle = iota
+	// CapFlat ends lines square at their end points, so points have no buffer
+	CapFlat
+	// CapSquare ends lines square, extended past their end points by the distance
+	CapSquare
+)
+
+// JoinStyle is the shape Buffer and OffsetCurve give to the outside of corners
+type JoinStyle int
+
+const (
+	// JoinRound rounds corners with an arc around the vertex
+	JoinRound JoinStyle = iota
+	// JoinMitre extends the sides to meet at a point, cut square when that lies further from the vertex than the
+	// mitre limit times the distance
+	JoinMitre
+	// JoinBevel cuts corners with a straight segment
+	JoinBevel
+)
+
+// DefaultQuadrantSegments is the number of segments used for each quarter circle of a buffer, matching Postgis
+const DefaultQuadrantSegments = 8
+
+// DefaultMitreLimit is the furthest a mitre may reach from its vertex as a multiple of the distance, matching Postgis
+const DefaultMitreLimit = 5.0
+
+// bufferer holds the style of a buffer and the distance being applied
+type bufferer struct {
+	segments   int
+	cap        CapStyle
+	join       JoinStyle
+	mitreLimit float64
+	d          float64
+}
+
+// BufferOption configures Buffer and OffsetCurve
+type BufferOption func(*bufferer)
+
+// WithQuadrantSegments approximates round caps and joins using n segments for every quarter circle
+func WithQuadrantSegments(n int) BufferOption {
+	return func(b *bufferer) {
+		b.segments = n
+	}
+}
+
+// WithCapStyle sets the shape of the ends of lines, CapRound by default
+func WithCapStyle(c CapStyle) BufferOption {
+	return func(b *bufferer) {
+		b.cap = c
+	}
+}
+
+// WithJoinStyle sets the shape of the outside of corners, JoinRound by default
+func WithJoinStyle(j JoinStyle) BufferOption {
+	return func(b *bufferer) {
+		b.join = j
+	}
+}
+
+// WithMitreLimit sets the furthest a mitre join may reach from its vertex as a multiple of the distance
+func WithMitreLimit(limit float64) BufferOption {
+	return func(b *bufferer) {
+		b.mitreLimit = limit
+	}
+}
+
+func newBufferer(distance float64, opts []BufferOption) *bufferer {
+	b := &bufferer{segments: DefaultQuadrantSegments, mitreLimit: DefaultMitreLimit, d: math.Abs(distance)}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.segments < 1 {
+		b.segments = 1
+	}
+
+	return b
+}
+
+// Buffer returns the area within distance of g. A negative distance shrinks polygons by removing the area within
+// that distance of their boundary, and leaves points and lines with no area. The buffer is built from a rectangle
+// along each segment, a cap at the ends of lines and a join at the outside of each corner, merged with the polygons
+// in a cascaded union, so overlapping parts of the input are dissolved. A line that closes on itself is joined where
+// it closes rather than capped.
+//
+// The result is a Polygon, a MultiPolygon or an empty Polygon. Arcs are linearized with the default options, Z and M
+// values are dropped and the result keeps the SRID. Returns ErrNoGeometry when g is nil.
+func Buffer(g Geometry, distance float64, opts ...BufferOption) (Geometry, error) {
+	o, err := newOperand(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBufferer(distance, opts)
+
+	var parts []*operand
+
+	switch {
+	case distance > 0:
+		for _, p := range o.points {
+			parts = append(parts, b.point(p)...)
+		}
+
+		for _, l := range o.lines {
+			parts = append(parts, b.line(l, len(l) > 3 && l[0] == l[len(l)-1])...)
+		}
+
+		for _, polygon := range o.polygons {
+			parts = append(parts, b.polygon(polygon))
+
+			for _, r := range polygon {
+				parts = append(parts, b.line(r, true)...)
+			}
+		}
+	case distance < 0:
+		for _, polygon := range o.polygons {
+			var edge []*operand
+
+			for _, r := range polygon {
+				edge = append(edge, b.line(r, true)...)
+			}
+
+			parts = append(parts, overlayOperands(b.polygon(polygon), unionAll(edge), difference))
+		}
+	default:
+		for _, polygon := range o.polygons {
+			parts = append(parts, b.polygon(polygon))
+		}
+	}
+
+	area := unionAll(parts)
+	area.points, area.lines = nil, nil
+
+	return area.geometry(g.SRID(), 2), nil
+}
+
+func (b *bufferer) polygon(rings [][]xy) *operand {
+	o := &operand{polygons: [][][]xy{rings}}
+	o.setExtent()
+
+	return o
+}
+
+// offset returns the point at the distance from p in the direction of the unit vector n
+func (b *bufferer) offset(p, n xy) xy {
+	return xy{p[0] + n[0]*b.d, p[1] + n[1]*b.d}
+}
+
+// point returns the buffer of a lone point, a circle or a square by the cap style
+func (b *bufferer) point(p xy) []*operand {
+	var ring []xy
+
+	switch b.cap {
+	case CapRound:
+		n := 4 * b.segments
+
+		for k := 0; k < n; k++ {
+			a := 2 * math.Pi * float64(k) / float64(n)
+			ring = append(ring, b.offset(p, xy{math.Cos(a), math.Sin(a)}))
+		}
+
+		ring = append(ring, ring[0])
+	case CapSquare:
+		ring = []xy{b.offset(p, xy{1, -1}), b.offset(p, xy{1, 1}), b.offset(p, xy{-1, 1}),
+			b.offset(p, xy{-1, -1}), b.offset(p, xy{1, -1})}
+	default:
+		return nil
+	}
+
+	return []*operand{b.polygon([][]xy{ring})}
+}
+
+// line returns the parts of the buffer of a line, or of a ring when it is closed, which is joined where it closes
+// rather than capped
+func (b *bufferer) line(l []xy, closed bool) []*operand {
+	var parts []*operand
+
+	add := func(ring []xy) {
+		if len(ring) > 3 && ringArea(ring) != 0 {
+			parts = append(parts, b.polygon([][]xy{ring}))
+		}
+	}
+
+	dirs := directions(l)
+
+	for i, u := range dirs {
+		n := xy{-u[1], u[0]}
+		s := xy{u[1], -u[0]}
+		add([]xy{b.offset(l[i], s), b.offset(l[i+1], s), b.offset(l[i+1], n), b.offset(l[i], n), b.offset(l[i], s)})
+	}
+
+	for i := 1; i < len(dirs); i++ {
+		add(b.joinRing(l[i], dirs[i-1], dirs[i]))
+	}
+
+	last := dirs[len(dirs)-1]
+
+	if closed {
+		add(b.joinRing(l[0], last, dirs[0]))
+	} else {
+		add(b.capRing(l[0], xy{-dirs[0][0], -dirs[0][1]}))
+		add(b.capRing(l[len(l)-1], last))
+	}
+
+	return parts
+}
+
+// directions returns the unit vector along each segment of the line
+func directions(l []xy) []xy {
+	dirs := make([]xy, len(l)-1)
+
+	for i := range dirs {
+		dx, dy := l[i+1][0]-l[i][0], l[i+1][1]-l[i][1]
+		h := math.Hypot(dx, dy)
+		dirs[i] = xy{dx / h, dy / h}
+	}
+
+	return dirs
+}
+
+// turn returns the normals either side of the outside of the corner where direction u turns into v, with the
+// signed angle swept from one to the other. The outside of a corner that turns back on itself is taken to the right,
+// sweeping forward. The sweep is zero when the line runs straight on.
+func turn(u, v xy) (xy, xy, float64) {
+	cross, dot := u[0]*v[1]-u[1]*v[0], u[0]*v[0]+u[1]*v[1]
+
+	if cross == 0 && dot < 0 {
+		return xy{u[1], -u[0]}, xy{v[1], -v[0]}, math.Pi
+	} else if cross > 0 {
+		return xy{u[1], -u[0]}, xy{v[1], -v[0]}, math.Atan2(cross, dot)
+	}
+
+	return xy{-u[1], u[0]}, xy{-v[1], v[0]}, math.Atan2(cross, dot)
+}
+
+// wedge returns the points between the offsets of p along normals n1 and n2, which are swept by the angle, for the
+// join style. The offsets themselves are not included.
+func (b *bufferer) wedge(p, n1, n2 xy, sweep float64) []xy {
+	switch b.join {
+	case JoinRound:
+		var pts []xy
+
+		step := math.Pi / 2 / float64(b.segments)
+		m := int(math.Ceil(math.Abs(sweep)/step - 1e-9))
+		a := math.Atan2(n1[1], n1[0])
+
+		for k := 1; k < m; k++ {
+			t := a + sweep*float64(k)/float64(m)
+			pts = append(pts, b.offset(p, xy{math.Cos(t), math.Sin(t)}))
+		}
+
+		return pts
+	case JoinMitre:
+		cos, sin := math.Cos(sweep/2), math.Abs(math.Sin(sweep/2))
+
+		if cos > 0 && 1/cos <= b.mitreLimit {
+			f := 1 + n1[0]*n2[0] + n1[1]*n2[1]
+			return []xy{b.offset(p, xy{(n1[0] + n2[0]) / f, (n1[1] + n2[1]) / f})}
+		}
+
+		// cut the mitre across the bisector at the limit, moving along each side from its offset
+		t := math.Max(0, (b.mitreLimit-cos)/sin)
+		w1, w2 := xy{-n1[1], n1[0]}, xy{n2[1], -n2[0]}
+
+		if sweep < 0 {
+			w1, w2 = xy{n1[1], -n1[0]}, xy{-n2[1], n2[0]}
+		}
+
+		return []xy{b.offset(b.offset(p, n1), xy{w1[0] * t, w1[1] * t}), b.offset(b.offset(p, n2), xy{w2[0] * t, w2[1] * t})}
+	default:
+		return nil
+	}
+}
+
+// joinRing returns the counter-clockwise ring filling the outside of the corner at p, or nil when there is none
+func (b *bufferer) joinRing(p, u, v xy) []xy {
+	n1, n2, sweep := turn(u, v)
+
+	if sweep == 0 {
+		return nil
+	}
+
+	ring := append(append([]xy{p, b.offset(p, n1)}, b.wedge(p, n1, n2, sweep)...), b.offset(p, n2), p)
+
+	if sweep < 0 {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+
+	return ring
+}
+
+// capRing returns the counter-clockwise ring of the cap at the end p of a line leaving in direction u, or nil when
+// the cap is flat
+func (b *bufferer) capRing(p, u xy) []xy {
+	r, l := xy{u[1], -u[0]}, xy{-u[1], u[0]}
+
+	switch b.cap {
+	case CapRound:
+		round := &bufferer{segments: b.segments, join: JoinRound, d: b.d}
+		return append(append([]xy{p, b.offset(p, r)}, round.wedge(p, r, l, math.Pi)...), b.offset(p, l), p)
+	case CapSquare:
+		return []xy{b.offset(p, r), b.offset(b.offset(p, r), u), b.offset(b.offset(p, l), u), b.offset(p, l),
+			b.offset(p, r)}
+	default:
+		return nil
+	}
+}
+
+// OffsetCurve returns the line at distance from each LineString of g, on its left when the distance is positive and
+// its right when negative, running in the same direction. The outside of corners is shaped by the join style. The
+// parts of the offset that come nearer the line than the distance, inside tight corners and where the line doubles
+// back on itself, are trimmed away, which may leave several lines for each. A closed line of at least four points
+// gives closed offsets.
+//
+// The result is a LineString, a MultiLineString or an empty LineString. Arcs are linearized with the default options,
+// Z and M values are dropped and the result keeps the SRID. Returns ErrNoGeometry when g is nil and
+// ErrUnsupportedOperation when it is not a LineString, MultiLineString or curve.
+func OffsetCurve(g Geometry, distance float64, opts ...BufferOption) (Geometry, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]Coordinate
+
+	switch lg := lg.(type) {
+	case *LineString:
+		lines = append(lines, lg.Coordinates)
+	case *MultiLineString:
+		for _, ls := range lg.LineStrings {
+			lines = append(lines, ls.Coordinates)
+		}
+	default:
+		return nil, ErrUnsupportedOperation
+	}
+
+	b := newBufferer(distance, opts)
+	out := &operand{}
+
+	for _, coords := range lines {
+		switch l := toXY(coords); {
+		case len(l) < 2:
+		case distance == 0:
+			out.lines = append(out.lines, l)
+		default:
+			out.lines = append(out.lines, b.offsetCurve(l, distance > 0)...)
+		}
+	}
+
+	return out.geometry(g.SRID(), 1), nil
+}
+
+// offsetZone is a convex region within the distance of a line: the strip along a segment, or a join around the
+// outside of the corner between two segments. The pieces of the offset curve lie on the boundary of their own zones.
+type offsetZone struct {
+	poly   []xy
+	extent Envelope
+	seg    int
+	join   [2]int
+}
+
+func newOffsetZone(poly []xy, seg int, join [2]int) offsetZone {
+	z := offsetZone{poly: poly, extent: emptyEnvelope(), seg: seg, join: join}
+
+	for _, p := range poly {
+		z.extent = z.extent.addXY(p[0], p[1])
+	}
+
+	return z
+}
+
+// skip reports whether the zone is one that a piece offset from the segments near lies on
+func (z offsetZone) skip(near [2]int) bool {
+	if z.seg >= 0 {
+		return near[0] == z.seg || near[1] == z.seg
+	}
+
+	return near == z.join
+}
+
+// convexRange returns the range of t from 0 to 1 over which p + t(q - p) lies further than eps inside the convex
+// polygon, clipping the segment by each of its edges in turn
+func convexRange(p, q xy, poly []xy, eps float64) (float64, float64, bool) {
+	var area float64
+
+	for i, a := range poly {
+		c := poly[(i+1)%len(poly)]
+		area += a[0]*c[1] - c[0]*a[1]
+	}
+
+	lo, hi := 0.0, 1.0
+
+	for i, a := range poly {
+		c := poly[(i+1)%len(poly)]
+		h := math.Hypot(c[0]-a[0], c[1]-a[1])
+
+		if h == 0 {
+			continue
+		}
+
+		// the normal of the edge into the polygon, whichever way it winds
+		n := xy{(a[1] - c[1]) / h, (c[0] - a[0]) / h}
+
+		if area < 0 {
+			n = xy{-n[0], -n[1]}
+		}
+
+		f := (p[0]-a[0])*n[0] + (p[1]-a[1])*n[1] - eps
+		df := (q[0]-p[0])*n[0] + (q[1]-p[1])*n[1]
+
+		switch {
+		case df > 0:
+			lo = math.Max(lo, -f/df)
+		case df < 0:
+			hi = math.Min(hi, -f/df)
+		case f <= 0:
+			return 0, 0, false
+		}
+	}
+
+	return lo, hi, lo < hi
+}
+
+// discRange returns the range of t from 0 to 1 over which p + t(q - p) lies nearer than r to v, solving
+// |p + t(q - p) - v|² = r²
+func discRange(p, q, v xy, r float64) (float64, float64, bool) {
+	w, e := xy{q[0] - p[0], q[1] - p[1]}, xy{p[0] - v[0], p[1] - v[1]}
+	qa, qb, qc := w[0]*w[0]+w[1]*w[1], 2*(e[0]*w[0]+e[1]*w[1]), e[0]*e[0]+e[1]*e[1]-r*r
+	det := qb*qb - 4*qa*qc
+
+	if det <= 0 {
+		return 0, 0, false
+	}
+
+	lo, hi := math.Max((-qb-math.Sqrt(det))/(2*qa), 0), math.Min((-qb+math.Sqrt(det))/(2*qa), 1)
+
+	return lo, hi, lo < hi
+}
+
+// offsetPiece is a segment of an untrimmed offset curve with the segments of the line it lies at the distance from,
+// which are -1 for the pieces that run through a vertex
+type offsetPiece struct {
+	s    *segment
+	near [2]int
+}
+
+// offsetCurve returns the offset of the line to one side. The untrimmed curve is the offset of each segment, joined
+// around the outside of corners and through the vertex on the inside. It is noded with itself and the pieces nearer
+// to the line than the distance dropped, leaving the pieces that meet at the nodes between them.
+func (b *bufferer) offsetCurve(l []xy, left bool) [][]xy {
+	closed := len(l) > 3 && l[0] == l[len(l)-1]
+	dirs := directions(l)
+	normal := func(u xy) xy {
+		if left {
+			return xy{-u[1], u[0]}
+		}
+
+		return xy{u[1], -u[0]}
+	}
+
+	scale := &operand{extent: emptyEnvelope()}
+
+	for _, p := range l {
+		scale.extent = scale.extent.addXY(p[0], p[1])
+	}
+
+	scale.extent = scale.extent.Expand(b.d * (b.mitreLimit + 1))
+	sn := newSnapper(scale, scale)
+
+	var pieces []offsetPiece
+
+	// the zones within the distance of the line: a strip along each segment and the join around the outside of each
+	// corner, on whichever side it lies, which together with the discs around the vertices cover every point nearer
+	// than the distance
+	zones := make([]offsetZone, 0, 2*len(dirs))
+
+	for j, u := range dirs {
+		n := xy{-u[1], u[0]}
+		zones = append(zones, newOffsetZone([]xy{b.offset(l[j], n), b.offset(l[j+1], n),
+			b.offset(l[j+1], xy{-n[0], -n[1]}), b.offset(l[j], xy{-n[0], -n[1]})}, j, [2]int{-1, -1}))
+	}
+
+	// the radius of the disc around each vertex within which the line is nearer than the distance
+	radius := make([]float64, len(l))
+
+	for k := range radius {
+		radius[k] = b.d
+	}
+
+	last := sn.snap(b.offset(l[0], normal(dirs[0])))
+
+	// add extends the curve to p with a piece at the distance from the segments near
+	add := func(p xy, near [2]int) {
+		if p = sn.snap(p); p != last {
+			pieces = append(pieces, offsetPiece{newSegment(last, p, 0, lineSegment, false), near})
+		}
+
+		last = p
+	}
+
+	// corner turns the curve from the offset of segment i to that of segment j around vertex v
+	corner := func(v xy, i, j int) {
+		n1, n2, sweep := turn(dirs[i], dirs[j])
+
+		var wedge []xy
+
+		if sweep != 0 {
+			wedge = b.wedge(v, n1, n2, sweep)
+			poly := append(append([]xy{v, b.offset(v, n1)}, wedge...), b.offset(v, n2))
+			zones = append(zones, newOffsetZone(poly, -1, [2]int{i, j}))
+
+			// the chords of the join come nearer the vertex than the distance, so the disc around it is shrunk to fit
+			// inside them and what lies between is left to the join's zone
+			for k := 2; k < len(poly); k++ {
+				radius[j] = math.Min(radius[j], math.Sqrt(dist2(v, nearestOnSegment(v, poly[k-1], poly[k], 1))))
+			}
+		}
+
+		switch {
+		case sweep == 0:
+		case left != (sweep > 0):
+			for _, p := range wedge {
+				add(p, [2]int{i, j})
+			}
+		default:
+			add(v, [2]int{-1, -1})
+			add(b.offset(v, normal(dirs[j])), [2]int{-1, -1})
+
+			return
+		}
+
+		add(b.offset(v, normal(dirs[j])), [2]int{i, j})
+	}
+
+	for k := 1; k < len(l); k++ {
+		add(b.offset(l[k], normal(dirs[k-1])), [2]int{k - 1, k - 1})
+
+		if k < len(dirs) {
+			corner(l[k], k-1, k)
+		}
+	}
+
+	if closed {
+		corner(l[0], len(dirs)-1, 0)
+	}
+
+	segs := make([]*segment, len(pieces))
+
+	for i, p := range pieces {
+		segs[i] = p.s
+	}
+
+	nodeSegments(segs, sn)
+
+	eps := b.d * 1e-9
+	vertices := l
+
+	if closed {
+		vertices = l[:len(dirs)]
+	}
+
+	// keep adds the part of the piece pq from t0 to t1 along it to the lines, continuing the last when they meet
+	var lines [][]xy
+
+	keep := func(p, q xy, t0, t1 float64) {
+		a, c := p, q
+
+		if t0 > 0 {
+			a = xy{p[0] + t0*(q[0]-p[0]), p[1] + t0*(q[1]-p[1])}
+		}
+
+		if t1 < 1 {
+			c = xy{p[0] + t1*(q[0]-p[0]), p[1] + t1*(q[1]-p[1])}
+		}
+
+		// slivers left by the tolerance where a cut ends at a node are dropped
+		if math.Hypot(c[0]-a[0], c[1]-a[1]) <= b.d*1e-6 {
+			return
+		}
+
+		if n := len(lines); n > 0 && lines[n-1][len(lines[n-1])-1] == a {
+			lines[n-1] = append(lines[n-1], c)
+		} else {
+			lines = append(lines, []xy{a, c})
+		}
+	}
+
+	for _, piece := range pieces {
+		pts := piece.s.split()
+
+		for i := 1; i < len(pts); i++ {
+			p, q := pts[i-1], pts[i]
+
+			if p == q {
+				continue
+			}
+
+			// the parts of pq inside a zone other than those it was offset from, or nearer a vertex than the distance
+			var cuts [][2]float64
+
+			extent := emptyEnvelope().addXY(p[0], p[1]).addXY(q[0], q[1])
+
+			for _, z := range zones {
+				if z.skip(piece.near) || !z.extent.Intersects(extent) {
+					continue
+				}
+
+				if t0, t1, ok := convexRange(p, q, z.poly, eps); ok {
+					cuts = append(cuts, [2]float64{t0, t1})
+				}
+			}
+
+			for k, v := range vertices {
+				join := [2]int{k - 1, k}
+
+				if k == 0 {
+					join = [2]int{len(dirs) - 1, 0}
+				}
+
+				// the join around a vertex comes nearer it than the distance between its points
+				if piece.near == join {
+					continue
+				}
+
+				if t0, t1, ok := discRange(p, q, v, radius[k]-eps); ok {
+					cuts = append(cuts, [2]float64{t0, t1})
+				}
+			}
+
+			sort.Slice(cuts, func(x, y int) bool { return cuts[x][0] < cuts[y][0] })
+
+			t := 0.0
+
+			for _, cut := range cuts {
+				if cut[0] > t {
+					keep(p, q, t, cut[0])
+				}
+
+				t = math.Max(t, cut[1])
+			}
+
+			if t < 1 {
+				keep(p, q, t, 1)
+			}
+		}
+	}
+
+	// the line split where a closed offset began rejoins
+	if n := len(lines); closed && n > 1 && lines[0][0] == lines[n-1][len(lines[n-1])-1] {
+		lines[0] = append(lines[n-1], lines[0][1:]...)
+		lines = lines[:n-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,144 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	pt := &Point{Hdr{XY, 0}, Coordinate{0, 0}}
+	line := &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}}}
+	corner := &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}, {10, 10}}}
+
+	// the area of a circle of radius 1 approximated with 8 segments to the quadrant
+	circle := 16 * math.Sin(math.Pi/16)
+
+	datasets := []struct {
+		name     string
+		g        Geometry
+		distance float64
+		opts     []BufferOption
+		area     float64
+	}{
+		{"point", pt, 1, nil, circle},
+		{"point square", pt, 1, []BufferOption{WithCapStyle(CapSquare)}, 4},
+		{"point flat", pt, 1, []BufferOption{WithCapStyle(CapFlat)}, 0},
+		{"point segments", pt, 1, []BufferOption{WithQuadrantSegments(1)}, 2},
+		{"line", line, 1, nil, 20 + circle},
+		{"line flat", line, 1, []BufferOption{WithCapStyle(CapFlat)}, 20},
+		{"line square", line, 1, []BufferOption{WithCapStyle(CapSquare)}, 24},
+		{"line negative", line, -1, nil, 0},
+		{"corner mitre", corner, 1, []BufferOption{WithJoinStyle(JoinMitre), WithCapStyle(CapFlat)}, 40},
+		{"corner bevel", corner, 1, []BufferOption{WithJoinStyle(JoinBevel), WithCapStyle(CapFlat)}, 39.5},
+		{"spike mitre", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}, {0, 0}}}, 1,
+			[]BufferOption{WithJoinStyle(JoinMitre), WithMitreLimit(2)}, 24 + circle/2},
+		{"polygon", box(0, 0, 10, 10), 1, nil, 140 + circle},
+		{"polygon mitre", box(0, 0, 10, 10), 1, []BufferOption{WithJoinStyle(JoinMitre)}, 144},
+		{"polygon bevel", box(0, 0, 10, 10), 1, []BufferOption{WithJoinStyle(JoinBevel)}, 142},
+		{"polygon zero", square, 0, nil, 96},
+		{"polygon negative", square, -0.5, nil, 81 - 8 - circle/4},
+		{"polygon collapsed", square, -6, nil, 0},
+		{"overlapping", &MultiPoint{Hdr{XY, 0}, []Point{{Hdr{XY, 0}, Coordinate{0, 0}}, {Hdr{XY, 0}, Coordinate{0, 1}}}}, 1,
+			[]BufferOption{WithCapStyle(CapSquare)}, 6},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Buffer(dataset.g, dataset.distance, dataset.opts...)
+		assert.NoError(t, err, dataset.name)
+		assert.NoError(t, ValidationReason(g), dataset.name)
+		assert.InDelta(t, dataset.area, Area(g), 1e-9, dataset.name)
+	}
+
+	g, err := Buffer(&LineString{Hdr{XYZ, 4326}, []Coordinate{{0, 0, 1}, {10, 0, 1}}}, 1, WithCapStyle(CapFlat))
+	assert.NoError(t, err)
+	assert.Equal(t, &Polygon{Hdr{XY, 4326}, []LinearRing{{[]Coordinate{{0, -1}, {10, -1}, {10, 1}, {0, 1}, {0, -1}}}}}, g)
+
+	g, err = Buffer(line, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, &Polygon{Hdr{XY, 0}, nil}, g)
+
+	_, err = Buffer(nil, 1)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestOffsetCurve(t *testing.T) {
+	corner := &LineString{Hdr{XY, 4326}, []Coordinate{{0, 0}, {10, 0}, {10, 10}}}
+	ring := &LineString{Hdr{XY, 0}, []Coordinate{{5, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}, {5, 0}}}
+	mitre := WithJoinStyle(JoinMitre)
+
+	datasets := []struct {
+		name     string
+		g        Geometry
+		distance float64
+		opts     []BufferOption
+		expected Geometry
+	}{
+		{"inside corner", corner, 1, nil, &LineString{Hdr{XY, 4326}, []Coordinate{{0, 1}, {9, 1}, {9, 10}}}},
+		{"mitre", corner, -1, []BufferOption{mitre},
+			&LineString{Hdr{XY, 4326}, []Coordinate{{0, -1}, {10, -1}, {11, -1}, {11, 0}, {11, 10}}}},
+		{"bevel", corner, -1, []BufferOption{WithJoinStyle(JoinBevel)},
+			&LineString{Hdr{XY, 4326}, []Coordinate{{0, -1}, {10, -1}, {11, 0}, {11, 10}}}},
+		{"round", corner, -1, []BufferOption{WithQuadrantSegments(2)},
+			&LineString{Hdr{XY, 4326}, []Coordinate{{0, -1}, {10, -1}, {10 + math.Cos(math.Pi/4), -math.Sin(math.Pi / 4)}, {11, 0}, {11, 10}}}},
+		{"ring inside", ring, 1, nil,
+			&LineString{Hdr{XY, 0}, []Coordinate{{5, 1}, {9, 1}, {9, 9}, {1, 9}, {1, 1}, {5, 1}}}},
+		{"ring outside", ring, -1, []BufferOption{mitre},
+			&LineString{Hdr{XY, 0}, []Coordinate{{5, -1}, {10, -1}, {11, -1}, {11, 0}, {11, 10}, {11, 11}, {10, 11}, {0, 11},
+				{-1, 11}, {-1, 10}, {-1, 0}, {-1, -1}, {0, -1}, {5, -1}}}},
+		{"ring collapsed", ring, 6, nil, &LineString{Hdr{XY, 0}, nil}},
+		{"narrow", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}, {10, 1}, {0, 1}}}, 1, nil,
+			&LineString{Hdr{XY, 0}, nil}},
+		{"zero", corner, 0, nil, &LineString{Hdr{XY, 4326}, []Coordinate{{0, 0}, {10, 0}, {10, 10}}}},
+		{"multi", &MultiLineString{Hdr{XY, 0}, []LineString{
+			{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}}},
+			{Hdr{XY, 0}, []Coordinate{{0, 5}, {10, 5}}},
+		}}, 1, nil, &MultiLineString{Hdr{XY, 0}, []LineString{
+			{Hdr{XY, 0}, []Coordinate{{0, 1}, {10, 1}}},
+			{Hdr{XY, 0}, []Coordinate{{0, 6}, {10, 6}}},
+		}}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := OffsetCurve(dataset.g, dataset.distance, dataset.opts...)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	// pieces are trimmed where they come within the distance, not kept or dropped whole
+	hook := &LineString{Hdr{XY, 0}, []Coordinate{{4, 5}, {3, 5}, {3, 8}, {8, 3}}}
+	g, err := OffsetCurve(hook, -1)
+	assert.NoError(t, err)
+
+	if assert.IsType(t, &LineString{}, g) {
+		assertCoords(t, []Coordinate{{4.936487470942148, 4.649298966684757}, {7.292893218813452, 2.2928932188134525}},
+			g.(*LineString).Coordinates)
+	}
+
+	d, err := Distance(hook, g)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, d, 1e-6)
+
+	_, err = OffsetCurve(box(0, 0, 1, 1), 1)
+	assert.Equal(t, ErrUnsupportedOperation, err)
+
+	_, err = OffsetCurve(nil, 1)
+	assert.Equal(t, ErrNoGeometry, err)
+}
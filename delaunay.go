@@ -0,0 +1,335 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// triangulation is a Delaunay triangulation of a set of points, built by a radial sweep out from a seed triangle
+// near their centre. Triangle t has the points triangles[3t], triangles[3t+1] and triangles[3t+2], turning
+// clockwise. halfedges[e] is the edge of the neighbouring triangle running the other way along edge e, from
+// triangles[e] to the next point of its triangle, or -1 when e lies on the convex hull.
+type triangulation struct {
+	pts       []xy
+	triangles []int
+	halfedges []int
+
+	hullPrev, hullNext, hullTri, hullHash []int
+	hullStart                             int
+	cx, cy                                float64
+	stack                                 []int
+}
+
+// triangulate returns the Delaunay triangulation of the points, which must be distinct. There are no triangles when
+// all the points lie on a line.
+func triangulate(pts []xy) *triangulation {
+	n := len(pts)
+	t := &triangulation{pts: pts}
+
+	if n < 3 {
+		return t
+	}
+
+	extent := emptyEnvelope()
+
+	for _, p := range pts {
+		extent = extent.addXY(p[0], p[1])
+	}
+
+	// the seed triangle is the smallest around the point nearest the centre of the extent
+	centre := xy{(extent.MinX + extent.MaxX) / 2, (extent.MinY + extent.MaxY) / 2}
+	i0, i1, i2 := 0, -1, -1
+
+	for i, p := range pts {
+		if dist2(p, centre) < dist2(pts[i0], centre) {
+			i0 = i
+		}
+	}
+
+	for i, p := range pts {
+		if i != i0 && (i1 < 0 || dist2(p, pts[i0]) < dist2(pts[i1], pts[i0])) {
+			i1 = i
+		}
+	}
+
+	radius := math.Inf(1)
+
+	for i, p := range pts {
+		if i == i0 || i == i1 {
+			continue
+		}
+
+		if r := circumradius2(pts[i0], pts[i1], p); r < radius {
+			i2, radius = i, r
+		}
+	}
+
+	if i2 < 0 || orient(pts[i0], pts[i1], pts[i2]) == 0 {
+		return t
+	}
+
+	// triangles turn clockwise
+	if orient(pts[i0], pts[i1], pts[i2]) > 0 {
+		i1, i2 = i2, i1
+	}
+
+	t.cx, t.cy = circumcentre(pts[i0], pts[i1], pts[i2])
+	dists := make([]float64, n)
+	ids := make([]int, n)
+
+	for i, p := range pts {
+		ids[i] = i
+		dists[i] = dist2(p, xy{t.cx, t.cy})
+	}
+
+	sort.Slice(ids, func(a, b int) bool {
+		return dists[ids[a]] < dists[ids[b]]
+	})
+
+	size := int(math.Ceil(math.Sqrt(float64(n))))
+	t.hullPrev, t.hullNext, t.hullTri = make([]int, n), make([]int, n), make([]int, n)
+	t.hullHash = make([]int, size)
+
+	for i := range t.hullHash {
+		t.hullHash[i] = -1
+	}
+
+	t.hullStart = i0
+	t.hullNext[i0], t.hullPrev[i2] = i1, i1
+	t.hullNext[i1], t.hullPrev[i0] = i2, i2
+	t.hullNext[i2], t.hullPrev[i1] = i0, i0
+	t.hullTri[i0], t.hullTri[i1], t.hullTri[i2] = 0, 1, 2
+	t.hullHash[t.hashKey(pts[i0])] = i0
+	t.hullHash[t.hashKey(pts[i1])] = i1
+	t.hullHash[t.hashKey(pts[i2])] = i2
+
+	t.addTriangle(i0, i1, i2, -1, -1, -1)
+
+	for _, i := range ids {
+		if i == i0 || i == i1 || i == i2 {
+			continue
+		}
+
+		p := pts[i]
+
+		// find an edge of the hull visible from the point, starting near it by angle around the centre
+		key := t.hashKey(p)
+		start := -1
+
+		for j := 0; j < size; j++ {
+			start = t.hullHash[(key+j)%size]
+
+			if start != -1 && start != t.hullNext[start] {
+				break
+			}
+		}
+
+		start = t.hullPrev[start]
+		e := start
+
+		for orient(p, pts[e], pts[t.hullNext[e]]) <= 0 {
+			if e = t.hullNext[e]; e == start {
+				e = -1
+				break
+			}
+		}
+
+		if e == -1 {
+			continue
+		}
+
+		// add the triangle on the first visible edge, then those on the visible edges either side of it
+		tri := t.addTriangle(e, i, t.hullNext[e], -1, -1, t.hullTri[e])
+		t.hullTri[i] = t.legalize(tri + 2)
+		t.hullTri[e] = tri
+
+		next := t.hullNext[e]
+
+		for q := t.hullNext[next]; orient(p, pts[next], pts[q]) > 0; q = t.hullNext[next] {
+			tri = t.addTriangle(next, i, q, t.hullTri[i], -1, t.hullTri[next])
+			t.hullTri[i] = t.legalize(tri + 2)
+			t.hullNext[next] = next
+			next = q
+		}
+
+		if e == start {
+			for q := t.hullPrev[e]; orient(p, pts[q], pts[e]) > 0; q = t.hullPrev[e] {
+				tri = t.addTriangle(q, i, e, -1, t.hullTri[e], t.hullTri[q])
+				t.legalize(tri + 2)
+				t.hullTri[q] = tri
+				t.hullNext[e] = e
+				e = q
+			}
+		}
+
+		t.hullStart = e
+		t.hullPrev[i], t.hullNext[e] = e, i
+		t.hullPrev[next], t.hullNext[i] = i, next
+		t.hullHash[t.hashKey(p)] = i
+		t.hullHash[t.hashKey(pts[e])] = e
+	}
+
+	return t
+}
+
+// hashKey buckets a point by its angle around the centre of the seed triangle
+func (t *triangulation) hashKey(p xy) int {
+	dx, dy := p[0]-t.cx, p[1]-t.cy
+
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+
+	a := dx / (math.Abs(dx) + math.Abs(dy))
+
+	if dy > 0 {
+		a = 3 - a
+	} else {
+		a = 1 + a
+	}
+
+	return int(math.Floor(a/4*float64(len(t.hullHash)))) % len(t.hullHash)
+}
+
+func (t *triangulation) addTriangle(i0, i1, i2, a, b, c int) int {
+	tri := len(t.triangles)
+	t.triangles = append(t.triangles, i0, i1, i2)
+	t.halfedges = append(t.halfedges, -1, -1, -1)
+	t.link(tri, a)
+	t.link(tri+1, b)
+	t.link(tri+2, c)
+
+	return tri
+}
+
+func (t *triangulation) link(a, b int) {
+	t.halfedges[a] = b
+
+	if b != -1 {
+		t.halfedges[b] = a
+	}
+}
+
+// legalize flips edge a and the edges behind it until every triangle around them has an empty circumcircle,
+// returning the edge that ends up in place of the one after a
+func (t *triangulation) legalize(a int) int {
+	t.stack = t.stack[:0]
+
+	var ar int
+
+	for {
+		b := t.halfedges[a]
+		a0 := a - a%3
+		ar = a0 + (a+2)%3
+
+		if b == -1 {
+			if len(t.stack) == 0 {
+				break
+			}
+
+			a, t.stack = t.stack[len(t.stack)-1], t.stack[:len(t.stack)-1]
+
+			continue
+		}
+
+		b0 := b - b%3
+		al := a0 + (a+1)%3
+		bl := b0 + (b+2)%3
+
+		p0, pr, pl, p1 := t.triangles[ar], t.triangles[a], t.triangles[al], t.triangles[bl]
+
+		if !inCircle(t.pts[p0], t.pts[pr], t.pts[pl], t.pts[p1]) {
+			if len(t.stack) == 0 {
+				break
+			}
+
+			a, t.stack = t.stack[len(t.stack)-1], t.stack[:len(t.stack)-1]
+
+			continue
+		}
+
+		t.triangles[a], t.triangles[b] = p1, p0
+		hbl := t.halfedges[bl]
+
+		// the flipped edge was on the hull, so the hull must point to the triangle that now holds it
+		if hbl == -1 {
+			e := t.hullStart
+
+			for {
+				if t.hullTri[e] == bl {
+					t.hullTri[e] = a
+					break
+				}
+
+				if e = t.hullPrev[e]; e == t.hullStart {
+					break
+				}
+			}
+		}
+
+		t.link(a, hbl)
+		t.link(b, t.halfedges[ar])
+		t.link(ar, bl)
+
+		t.stack = append(t.stack, b0+(b+1)%3)
+	}
+
+	return ar
+}
+
+// inCircle reports whether p lies inside the circle through the clockwise triangle a, b, c
+func inCircle(a, b, c, p xy) bool {
+	dx, dy := a[0]-p[0], a[1]-p[1]
+	ex, ey := b[0]-p[0], b[1]-p[1]
+	fx, fy := c[0]-p[0], c[1]-p[1]
+
+	ap := dx*dx + dy*dy
+	bp := ex*ex + ey*ey
+	cp := fx*fx + fy*fy
+
+	return dx*(ey*cp-bp*fy)-dy*(ex*cp-bp*fx)+ap*(ex*fy-ey*fx) < 0
+}
+
+func dist2(a, b xy) float64 {
+	dx, dy := a[0]-b[0], a[1]-b[1]
+	return dx*dx + dy*dy
+}
+
+// circumradius2 returns the square of the radius of the circle through the points, infinite when they are collinear
+func circumradius2(a, b, c xy) float64 {
+	cx, cy := circumcentre(a, b, c)
+	r := dist2(a, xy{cx, cy})
+
+	if math.IsNaN(r) {
+		return math.Inf(1)
+	}
+
+	return r
+}
+
+func circumcentre(a, b, c xy) (float64, float64) {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	ex, ey := c[0]-a[0], c[1]-a[1]
+
+	bl := dx*dx + dy*dy
+	cl := ex*ex + ey*ey
+	d := 0.5 / (dx*ey - dy*ex)
+
+	return a[0] + (ey*bl-dy*cl)*d, a[1] + (dx*cl-ex*bl)*d
+}
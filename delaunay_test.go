@@ -0,0 +1,56 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriangulate(t *testing.T) {
+	datasets := []struct {
+		name      string
+		pts       []xy
+		triangles int
+	}{
+		{"triangle", []xy{{0, 0}, {1, 0}, {0, 1}}, 1},
+		{"square with centre", []xy{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {1, 1}}, 4},
+		{"collinear", []xy{{0, 0}, {1, 1}, {2, 2}}, 0},
+		{"too few", []xy{{0, 0}, {1, 1}}, 0},
+	}
+
+	for _, dataset := range datasets {
+		tr := triangulate(dataset.pts)
+		assert.Len(t, tr.triangles, 3*dataset.triangles, dataset.name)
+
+		for k := 0; k < len(tr.triangles); k += 3 {
+			a, b, c := dataset.pts[tr.triangles[k]], dataset.pts[tr.triangles[k+1]], dataset.pts[tr.triangles[k+2]]
+			assert.Equal(t, -1, orient(a, b, c), dataset.name)
+
+			for _, p := range dataset.pts {
+				assert.False(t, inCircle(a, b, c, p), dataset.name)
+			}
+		}
+
+		for e, o := range tr.halfedges {
+			if o != -1 {
+				assert.Equal(t, e, tr.halfedges[o], dataset.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,393 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// The distances are planar, measured between the linework and areas of the geometries after arcs are linearized with
+// the default options, ignoring Z and M values. Each returns ErrNoGeometry when either geometry is nil and, apart from
+// DWithin, ErrEmptyGeometry when either has no coordinates.
+
+// Distance returns the shortest distance between a point of a and a point of b, zero when they intersect
+func Distance(a, b Geometry) (float64, error) {
+	oa, ob, err := nonEmptyOperands(a, b)
+
+	if err != nil {
+		return 0, err
+	}
+
+	_, _, d := nearestPoints(oa, ob, 0)
+
+	return d, nil
+}
+
+// NearestPoints returns the point of a and the point of b that are nearest to one another, the same point when they
+// intersect. Each point takes the SRID of its geometry.
+func NearestPoints(a, b Geometry) (*Point, *Point, error) {
+	oa, ob, err := nonEmptyOperands(a, b)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, q, _ := nearestPoints(oa, ob, 0)
+
+	return &Point{Hdr{XY, a.SRID()}, Coordinate{p[0], p[1]}}, &Point{Hdr{XY, b.SRID()}, Coordinate{q[0], q[1]}}, nil
+}
+
+// DWithin reports whether a and b lie within the distance of one another. Geometries whose extents are further
+// apart are rejected without looking at their parts, and the search stops at the first pair of points close enough.
+// An empty geometry is not within any distance of another.
+func DWithin(a, b Geometry, distance float64) (bool, error) {
+	oa, ob, err := operands(a, b)
+
+	if err != nil {
+		return false, err
+	}
+
+	if distance < 0 || oa.extent.IsEmpty() || ob.extent.IsEmpty() || !oa.extent.Expand(distance).Intersects(ob.extent) {
+		return false, nil
+	}
+
+	_, _, d := nearestPoints(oa, ob, distance)
+
+	return d <= distance, nil
+}
+
+// HausdorffDistance returns the largest distance from a vertex of either geometry to the nearest point of the
+// other, approximating the greatest distance from any point of one to the other. Polygons are measured to their
+// rings, so a vertex inside a polygon of the other geometry is not at zero distance.
+func HausdorffDistance(a, b Geometry) (float64, error) {
+	return DensifiedHausdorffDistance(a, b, 1)
+}
+
+// DensifiedHausdorffDistance returns the Hausdorff distance with every segment split into equal parts no longer than
+// the fraction of its length, which brings the result closer to the exact distance for long segments. Returns
+// ErrFraction when the fraction is not greater than 0 and at most 1.
+func DensifiedHausdorffDistance(a, b Geometry, fraction float64) (float64, error) {
+	oa, ob, err := nonEmptyOperands(a, b)
+
+	if err != nil {
+		return 0, err
+	} else if !(fraction > 0 && fraction <= 1) {
+		return 0, ErrFraction
+	}
+
+	directed := func(from, to *operand) float64 {
+		ix := newSegmentIndex(linework(to))
+
+		var d float64
+
+		// a vertex with a point of the other no further than the largest distance so far cannot raise it
+		for _, p := range densify(vertices(from), fraction) {
+			_, _, n := ix.nearest(newSegment(p, p, 0, pointSegment, false), math.Inf(1), d)
+			d = math.Max(d, n)
+		}
+
+		return d
+	}
+
+	return math.Max(directed(oa, ob), directed(ob, oa)), nil
+}
+
+// FrechetDistance returns the discrete Fréchet distance between the vertices of a and b taken in order, the shortest
+// leash that lets a walk along the vertices of each, never stepping back, stay joined. Unlike the Hausdorff distance
+// it takes the direction of lines into account, so tracks the same shape travelled in opposite directions are far
+// apart.
+func FrechetDistance(a, b Geometry) (float64, error) {
+	return DensifiedFrechetDistance(a, b, 1)
+}
+
+// DensifiedFrechetDistance returns the discrete Fréchet distance with every segment split into equal parts no longer
+// than the fraction of its length. Returns ErrFraction when the fraction is not greater than 0 and at most 1.
+func DensifiedFrechetDistance(a, b Geometry, fraction float64) (float64, error) {
+	oa, ob, err := nonEmptyOperands(a, b)
+
+	if err != nil {
+		return 0, err
+	} else if !(fraction > 0 && fraction <= 1) {
+		return 0, ErrFraction
+	}
+
+	p, q := densify(vertices(oa), fraction), densify(vertices(ob), fraction)
+
+	// each row holds the square of the shortest leash reaching each vertex of q with the walk along p at one vertex
+	prev, row := make([]float64, len(q)), make([]float64, len(q))
+
+	for i := range p {
+		for j := range q {
+			dx, dy := p[i][0]-q[j][0], p[i][1]-q[j][1]
+			d := dx*dx + dy*dy
+
+			switch {
+			case i == 0 && j == 0:
+			case i == 0:
+				d = math.Max(d, row[j-1])
+			case j == 0:
+				d = math.Max(d, prev[j])
+			default:
+				d = math.Max(d, math.Min(math.Min(prev[j], prev[j-1]), row[j-1]))
+			}
+
+			row[j] = d
+		}
+
+		prev, row = row, prev
+	}
+
+	return math.Sqrt(prev[len(q)-1]), nil
+}
+
+func operands(a, b Geometry) (*operand, *operand, error) {
+	oa, err := newOperand(a)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ob, err := newOperand(b)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oa, ob, nil
+}
+
+// nonEmptyOperands returns the operands of a and b, failing with ErrEmptyGeometry when either has no coordinates
+func nonEmptyOperands(a, b Geometry) (*operand, *operand, error) {
+	oa, ob, err := operands(a, b)
+
+	if err != nil {
+		return nil, nil, err
+	} else if oa.extent.IsEmpty() || ob.extent.IsEmpty() {
+		return nil, nil, ErrEmptyGeometry
+	}
+
+	return oa, ob, nil
+}
+
+// vertices returns the points of the operand followed by the vertices of its lines and rings, in order, with each
+// line or ring a separate run
+func vertices(o *operand) [][]xy {
+	var runs [][]xy
+
+	for _, p := range o.points {
+		runs = append(runs, []xy{p})
+	}
+
+	runs = append(runs, o.lines...)
+
+	for _, polygon := range o.polygons {
+		runs = append(runs, polygon...)
+	}
+
+	return runs
+}
+
+// densify returns the vertices of the runs in order with each segment split into equal parts no longer than the
+// fraction of its length
+func densify(runs [][]xy, fraction float64) []xy {
+	var pts []xy
+
+	n := int(math.Ceil(1/fraction - 1e-9))
+
+	for _, run := range runs {
+		pts = append(pts, run[0])
+
+		for i := 1; i < len(run); i++ {
+			p, q := run[i-1], run[i]
+
+			for k := 1; k < n; k++ {
+				f := float64(k) / float64(n)
+				pts = append(pts, xy{p[0] + f*(q[0]-p[0]), p[1] + f*(q[1]-p[1])})
+			}
+
+			pts = append(pts, q)
+		}
+	}
+
+	return pts
+}
+
+// linework returns the points of the operand as segments of zero length, followed by the segments of its lines and
+// rings
+func linework(o *operand) []*segment {
+	var segs []*segment
+
+	for _, run := range vertices(o) {
+		if len(run) == 1 {
+			segs = append(segs, newSegment(run[0], run[0], 0, pointSegment, false))
+		}
+
+		for i := 1; i < len(run); i++ {
+			segs = append(segs, newSegment(run[i-1], run[i], 0, lineSegment, false))
+		}
+	}
+
+	return segs
+}
+
+// nearestPoints returns the nearest points of a and b and the distance between them, stopping as soon as it finds a
+// pair no further apart than stop. The distance is +Inf when either is empty.
+func nearestPoints(a, b *operand, stop float64) (xy, xy, float64) {
+	// a part of one that crosses no ring of the other is either wholly inside one of its polygons or wholly outside,
+	// so testing one vertex of each part finds those inside. Any other part that is inside meets a ring.
+	for _, o := range [][2]*operand{{a, b}, {b, a}} {
+		for _, run := range vertices(o[1]) {
+			if o[0].inArea(run[0]) {
+				return run[0], run[0], 0
+			}
+		}
+	}
+
+	var p, q xy
+
+	best := math.Inf(1)
+	ix := newSegmentIndex(linework(b))
+
+	for _, s := range linework(a) {
+		if sp, tq, d := ix.nearest(s, best, stop); d < best {
+			p, q, best = sp, tq, d
+
+			if best <= stop {
+				break
+			}
+		}
+	}
+
+	return p, q, best
+}
+
+// segmentIndex holds segments sorted by their least X, with the widest span in X, to find those near another
+type segmentIndex struct {
+	segs  []*segment
+	width float64
+}
+
+func newSegmentIndex(segs []*segment) *segmentIndex {
+	ix := &segmentIndex{segs: segs}
+
+	sort.Slice(segs, func(i, j int) bool {
+		return segs[i].minX < segs[j].minX
+	})
+
+	for _, s := range segs {
+		ix.width = math.Max(ix.width, s.maxX-s.minX)
+	}
+
+	return ix
+}
+
+// nearest returns the nearest points of s and the indexed segments when they are closer than best, otherwise best.
+// The search stops at the first pair no further apart than stop.
+func (ix *segmentIndex) nearest(s *segment, best, stop float64) (xy, xy, float64) {
+	var p, q xy
+
+	if len(ix.segs) == 0 {
+		return p, q, best
+	}
+
+	// start from the segment with the nearest least X, so the search is bounded from the start
+	if math.IsInf(best, 1) {
+		i := sort.Search(len(ix.segs), func(i int) bool {
+			return ix.segs[i].minX >= s.minX
+		})
+		t := ix.segs[minInt(i, len(ix.segs)-1)]
+		p, q = segmentNearest(s.p, s.q, t.p, t.q)
+
+		if best = math.Hypot(p[0]-q[0], p[1]-q[1]); best <= stop {
+			return p, q, best
+		}
+	}
+
+	lo := sort.Search(len(ix.segs), func(i int) bool {
+		return ix.segs[i].minX >= s.minX-best-ix.width
+	})
+
+	for _, t := range ix.segs[lo:] {
+		if t.minX > s.maxX+best {
+			break
+		}
+
+		if t.maxX < s.minX-best || t.minY > s.maxY+best || t.maxY < s.minY-best {
+			continue
+		}
+
+		if sp, tq := segmentNearest(s.p, s.q, t.p, t.q); math.Hypot(sp[0]-tq[0], sp[1]-tq[1]) < best {
+			p, q, best = sp, tq, math.Hypot(sp[0]-tq[0], sp[1]-tq[1])
+
+			if best <= stop {
+				break
+			}
+		}
+	}
+
+	return p, q, best
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// segmentNearest returns the nearest points of the segments ab and cd, which are the same point when they meet
+func segmentNearest(a, b, c, d xy) (xy, xy) {
+	o1, o2 := orient(a, b, c), orient(a, b, d)
+	o3, o4 := orient(c, d, a), orient(c, d, b)
+
+	if o1*o2 < 0 && o3*o4 < 0 {
+		x := crossing(newSegment(a, b, 0, lineSegment, false), newSegment(c, d, 0, lineSegment, false))
+		return x, x
+	}
+
+	// otherwise one of the end points is nearest to the other segment
+	p, q := a, nearestOnSegment(a, c, d, o3)
+	best := math.Hypot(p[0]-q[0], p[1]-q[1])
+
+	for _, pair := range [][2]xy{{b, nearestOnSegment(b, c, d, o4)}, {nearestOnSegment(c, a, b, o1), c},
+		{nearestOnSegment(d, a, b, o2), d}} {
+		if dist := math.Hypot(pair[0][0]-pair[1][0], pair[0][1]-pair[1][1]); dist < best {
+			p, q, best = pair[0], pair[1], dist
+		}
+	}
+
+	return p, q
+}
+
+// nearestOnSegment returns the point of the segment ab nearest to p, whose orientation to the segment is o, which is
+// p itself when it lies on the segment
+func nearestOnSegment(p, a, b xy, o int) xy {
+	if o == 0 && between(p, a, b) {
+		return p
+	}
+
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	f := 0.0
+
+	if l := dx*dx + dy*dy; l > 0 {
+		f = math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/l))
+	}
+
+	return xy{a[0] + f*dx, a[1] + f*dy}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	pt := func(x, y float64) *Point {
+		return &Point{Hdr{XY, 0}, Coordinate{x, y}}
+	}
+	line := func(coords ...Coordinate) *LineString {
+		return &LineString{Hdr{XY, 0}, coords}
+	}
+
+	datasets := []struct {
+		name     string
+		a, b     Geometry
+		expected float64
+		p, q     Coordinate
+	}{
+		{"points", pt(0, 0), pt(3, 4), 5, Coordinate{0, 0}, Coordinate{3, 4}},
+		{"point line", line(Coordinate{0, 0}, Coordinate{10, 0}), pt(5, 3), 3, Coordinate{5, 0}, Coordinate{5, 3}},
+		{"point on line", pt(1, 1), line(Coordinate{0, 0}, Coordinate{3, 3}), 0, Coordinate{1, 1}, Coordinate{1, 1}},
+		{"crossing", line(Coordinate{0, 0}, Coordinate{2, 2}), line(Coordinate{0, 2}, Coordinate{2, 0}), 0,
+			Coordinate{1, 1}, Coordinate{1, 1}},
+		{"lines", line(Coordinate{0, 0}, Coordinate{2, 0}), line(Coordinate{3, 1}, Coordinate{5, 1}), math.Sqrt2,
+			Coordinate{2, 0}, Coordinate{3, 1}},
+		{"point in polygon", box(0, 0, 10, 10), pt(5, 5), 0, Coordinate{5, 5}, Coordinate{5, 5}},
+		{"point in hole", square, pt(7, 7.5), 0.5, Coordinate{7, 8}, Coordinate{7, 7.5}},
+		{"polygon in polygon", box(2, 2, 3, 3), box(0, 0, 10, 10), 0, Coordinate{2, 2}, Coordinate{2, 2}},
+		{"polygons", box(0, 0, 1, 1), box(3, 0, 4, 1), 2, Coordinate{1, 0}, Coordinate{3, 0}},
+		{"collection", &GeometryCollection{Hdr{XY, 0}, []Geometry{pt(10, 10), line(Coordinate{0, 4}, Coordinate{4, 4})}},
+			ushape, 1, Coordinate{0, 4}, Coordinate{0, 3}},
+	}
+
+	for _, dataset := range datasets {
+		d, err := Distance(dataset.a, dataset.b)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, d, dataset.name)
+
+		p, q, err := NearestPoints(dataset.a, dataset.b)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.p, p.Coordinate, dataset.name)
+		assert.Equal(t, dataset.q, q.Coordinate, dataset.name)
+
+		within, err := DWithin(dataset.a, dataset.b, dataset.expected)
+		assert.NoError(t, err, dataset.name)
+		assert.True(t, within, dataset.name)
+
+		if dataset.expected > 0 {
+			within, err = DWithin(dataset.a, dataset.b, dataset.expected*0.99)
+			assert.NoError(t, err, dataset.name)
+			assert.False(t, within, dataset.name)
+		}
+	}
+
+	for _, empty := range []Geometry{&MultiPoint{Hdr{XY, 4326}, nil}, emptyPoint(4326), &Polygon{Hdr{XY, 0}, nil}} {
+		_, err := Distance(empty, pt(0, 0))
+		assert.Equal(t, ErrEmptyGeometry, err)
+
+		_, err = Distance(pt(0, 0), empty)
+		assert.Equal(t, ErrEmptyGeometry, err)
+
+		_, _, err = NearestPoints(empty, pt(0, 0))
+		assert.Equal(t, ErrEmptyGeometry, err)
+
+		within, err := DWithin(empty, pt(0, 0), 10)
+		assert.NoError(t, err)
+		assert.False(t, within)
+	}
+
+	within, err := DWithin(pt(0, 0), pt(0, 0), -1)
+	assert.NoError(t, err)
+	assert.False(t, within)
+
+	_, err = Distance(nil, pt(0, 0))
+	assert.Equal(t, ErrNoGeometry, err)
+
+	_, err = DWithin(pt(0, 0), nil, 1)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestHausdorffDistance(t *testing.T) {
+	datasets := []struct {
+		name     string
+		a, b     []Coordinate
+		fraction float64
+		expected float64
+	}{
+		{"lines", []Coordinate{{0, 0}, {2, 0}}, []Coordinate{{0, 1}, {1, 2}, {2, 1}}, 1, 2},
+		{"vertices", []Coordinate{{130, 0}, {0, 0}, {0, 150}}, []Coordinate{{10, 10}, {10, 150}, {130, 10}}, 1,
+			14.142135623730951},
+		{"densified", []Coordinate{{130, 0}, {0, 0}, {0, 150}}, []Coordinate{{10, 10}, {10, 150}, {130, 10}}, 0.5, 70},
+	}
+
+	for _, dataset := range datasets {
+		d, err := DensifiedHausdorffDistance(&LineString{Hdr{XY, 0}, dataset.a}, &LineString{Hdr{XY, 0}, dataset.b},
+			dataset.fraction)
+		assert.NoError(t, err, dataset.name)
+		assert.InDelta(t, dataset.expected, d, 1e-9, dataset.name)
+	}
+
+	d, err := HausdorffDistance(box(0, 0, 10, 10), &Point{Hdr{XY, 0}, Coordinate{5, 5}})
+	assert.NoError(t, err)
+	assert.Equal(t, 5*math.Sqrt2, d)
+
+	_, err = HausdorffDistance(box(0, 0, 1, 1), &LineString{Hdr{XY, 0}, nil})
+	assert.Equal(t, ErrEmptyGeometry, err)
+
+	_, err = DensifiedHausdorffDistance(box(0, 0, 1, 1), box(0, 0, 1, 1), 0)
+	assert.Equal(t, ErrFraction, err)
+}
+
+func TestFrechetDistance(t *testing.T) {
+	datasets := []struct {
+		name     string
+		a, b     []Coordinate
+		fraction float64
+		expected float64
+	}{
+		{"lines", []Coordinate{{0, 0}, {100, 0}}, []Coordinate{{0, 0}, {50, 50}, {100, 0}}, 1, 70.71067811865476},
+		{"densified", []Coordinate{{0, 0}, {100, 0}}, []Coordinate{{0, 0}, {50, 50}, {100, 0}}, 0.5, 50},
+		{"same", []Coordinate{{0, 0}, {1, 1}, {2, 0}}, []Coordinate{{0, 0}, {1, 1}, {2, 0}}, 1, 0},
+		{"reversed", []Coordinate{{0, 0}, {1, 1}, {2, 0}}, []Coordinate{{2, 0}, {1, 1}, {0, 0}}, 1, 2},
+	}
+
+	for _, dataset := range datasets {
+		d, err := DensifiedFrechetDistance(&LineString{Hdr{XY, 0}, dataset.a}, &LineString{Hdr{XY, 0}, dataset.b},
+			dataset.fraction)
+		assert.NoError(t, err, dataset.name)
+		assert.InDelta(t, dataset.expected, d, 1e-9, dataset.name)
+	}
+
+	_, err := FrechetDistance(&LineString{Hdr{XY, 0}, nil}, box(0, 0, 1, 1))
+	assert.Equal(t, ErrEmptyGeometry, err)
+
+	_, err = DensifiedFrechetDistance(box(0, 0, 1, 1), box(0, 0, 1, 1), 1.5)
+	assert.Equal(t, ErrFraction, err)
+}
@@ -0,0 +1,306 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+)
+
+// Envelope is the axis aligned bounding box of a geometry, with the range of Z and M values when the geometry has
+// them. An empty geometry has an empty envelope, whose minimums are greater than its maximums.
+type Envelope struct {
+	MinX, MinY, MaxX, MaxY float64
+	MinZ, MaxZ             float64
+	MinM, MaxM             float64
+	HasZ, HasM             bool
+}
+
+// emptyEnvelope returns an envelope containing nothing, which any coordinate will expand
+func emptyEnvelope() Envelope {
+	inf := math.Inf(1)
+
+	return Envelope{
+		MinX: inf, MinY: inf, MaxX: -inf, MaxY: -inf,
+		MinZ: inf, MaxZ: -inf,
+		MinM: inf, MaxM: -inf,
+	}
+}
+
+// Extent returns the envelope of g. Arcs in curved geometries are bounded exactly rather than by their control
+// points. HasZ and HasM are set when any coordinate visited has a Z or M value, so a collection takes them from its
+// members.
+func Extent(g Geometry) Envelope {
+	return emptyEnvelope().addGeometry(g)
+}
+
+func (e Envelope) addGeometry(g Geometry) Envelope {
+	switch g := g.(type) {
+	case *Point:
+		e = e.addCoord(g.Coordinate, g.Dimension())
+	case *MultiPoint:
+		for _, p := range g.Points {
+			e = e.addCoord(p.Coordinate, g.Dimension())
+		}
+	case *LineString:
+		e = e.addCoords(g.Coordinates, g.Dimension())
+	case *MultiLineString:
+		for _, ls := range g.LineStrings {
+			e = e.addCoords(ls.Coordinates, g.Dimension())
+		}
+	case *Polygon:
+		e = e.addRings(g.Rings, g.Dimension())
+	case *MultiPolygon:
+		for _, p := range g.Polygons {
+			e = e.addRings(p.Rings, g.Dimension())
+		}
+	case *Triangle:
+		e = e.addRings(g.Rings, g.Dimension())
+	case *PolyhedralSurface:
+		for _, p := range g.Polygons {
+			e = e.addRings(p.Rings, g.Dimension())
+		}
+	case *TIN:
+		for _, t := range g.Triangles {
+			e = e.addRings(t.Rings, g.Dimension())
+		}
+	case *CircularString:
+		e = e.addCoords(g.Coordinates, g.Dimension())
+
+		for idx := 0; idx+2 < len(g.Coordinates); idx += 2 {
+			e = e.addArc(g.Coordinates[idx], g.Coordinates[idx+1], g.Coordinates[idx+2])
+		}
+	case *CompoundCurve:
+		e = e.addMembers(g.Curves)
+	case *CurvePolygon:
+		e = e.addMembers(g.Rings)
+	case *MultiCurve:
+		e = e.addMembers(g.Curves)
+	case *MultiSurface:
+		e = e.addMembers(g.Surfaces)
+	case *GeometryCollection:
+		e = e.addMembers(g.Geometries)
+	}
+
+	return e
+}
+
+func (e Envelope) addMembers(geoms []Geometry) Envelope {
+	for _, g := range geoms {
+		e = e.addGeometry(g)
+	}
+
+	return e
+}
+
+func (e Envelope) addRings(rings []LinearRing, d Dimension) Envelope {
+	for _, r := range rings {
+		e = e.addCoords(r.Coordinates, d)
+	}
+
+	return e
+}
+
+func (e Envelope) addCoords(coords []Coordinate, d Dimension) Envelope {
+	for _, c := range coords {
+		e = e.addCoord(c, d)
+	}
+
+	return e
+}
+
+// addCoord returns the envelope expanded to include c, whose ordinates are laid out according to d. NaN ordinates,
+// as used for an empty point, are ignored.
+func (e Envelope) addCoord(c Coordinate, d Dimension) Envelope {
+	if len(c) < 2 || math.IsNaN(c[0]) || math.IsNaN(c[1]) {
+		return e
+	}
+
+	e = e.addXY(c[0], c[1])
+
+	z, m := -1, -1
+
+	switch d {
+	case XYZ:
+		z = 2
+	case XYM:
+		m = 2
+	case XYZM:
+		z, m = 2, 3
+	}
+
+	if z > 0 && z < len(c) && !math.IsNaN(c[z]) {
+		e.MinZ = math.Min(e.MinZ, c[z])
+		e.MaxZ = math.Max(e.MaxZ, c[z])
+		e.HasZ = true
+	}
+
+	if m > 0 && m < len(c) && !math.IsNaN(c[m]) {
+		e.MinM = math.Min(e.MinM, c[m])
+		e.MaxM = math.Max(e.MaxM, c[m])
+		e.HasM = true
+	}
+
+	return e
+}
+
+// addXY returns the envelope expanded to include the point x, y
+func (e Envelope) addXY(x, y float64) Envelope {
+	e.MinX = math.Min(e.MinX, x)
+	e.MinY = math.Min(e.MinY, y)
+	e.MaxX = math.Max(e.MaxX, x)
+	e.MaxY = math.Max(e.MaxY, y)
+
+	return e
+}
+
+// addArc returns the envelope expanded to include the points where the arc from p0 through p1 to p2 crosses the
+// axes of its circle, which together with the end points bound the arc
+func (e Envelope) addArc(p0, p1, p2 Coordinate) Envelope {
+	ca, ok := newArc(p0, p1, p2)
+
+	if !ok {
+		return e
+	}
+
+	// walk clockwise arcs backwards from p2
+	start, sweep := ca.a0, ca.sweep
+
+	if sweep < 0 {
+		start, sweep = ca.a0+sweep, -sweep
+	}
+
+	for q := 0; q < 4; q++ {
+		a := float64(q) * math.Pi / 2
+
+		if ccwAngle(start, a) <= sweep {
+			e = e.addXY(ca.cx+ca.r*math.Cos(a), ca.cy+ca.r*math.Sin(a))
+		}
+	}
+
+	return e
+}
+
+// IsEmpty reports whether the envelope contains nothing
+func (e Envelope) IsEmpty() bool {
+	return !(e.MinX <= e.MaxX && e.MinY <= e.MaxY)
+}
+
+// Width returns the extent of the envelope along the X axis
+func (e Envelope) Width() float64 {
+	if e.IsEmpty() {
+		return 0
+	}
+
+	return e.MaxX - e.MinX
+}
+
+// Height returns the extent of the envelope along the Y axis
+func (e Envelope) Height() float64 {
+	if e.IsEmpty() {
+		return 0
+	}
+
+	return e.MaxY - e.MinY
+}
+
+// Union returns the smallest envelope containing both e and o
+func (e Envelope) Union(o Envelope) Envelope {
+	if o.IsEmpty() {
+		return e
+	}
+
+	if e.IsEmpty() {
+		return o
+	}
+
+	u := Envelope{
+		MinX: math.Min(e.MinX, o.MinX),
+		MinY: math.Min(e.MinY, o.MinY),
+		MaxX: math.Max(e.MaxX, o.MaxX),
+		MaxY: math.Max(e.MaxY, o.MaxY),
+		MinZ: math.Min(e.MinZ, o.MinZ),
+		MaxZ: math.Max(e.MaxZ, o.MaxZ),
+		MinM: math.Min(e.MinM, o.MinM),
+		MaxM: math.Max(e.MaxM, o.MaxM),
+		HasZ: e.HasZ || o.HasZ,
+		HasM: e.HasM || o.HasM,
+	}
+
+	// a missing range must not contribute its zero values
+	switch {
+	case !e.HasZ && o.HasZ:
+		u.MinZ, u.MaxZ = o.MinZ, o.MaxZ
+	case e.HasZ && !o.HasZ:
+		u.MinZ, u.MaxZ = e.MinZ, e.MaxZ
+	}
+
+	switch {
+	case !e.HasM && o.HasM:
+		u.MinM, u.MaxM = o.MinM, o.MaxM
+	case e.HasM && !o.HasM:
+		u.MinM, u.MaxM = e.MinM, e.MaxM
+	}
+
+	return u
+}
+
+// Intersects reports whether e and o share at least one point in the XY plane
+func (e Envelope) Intersects(o Envelope) bool {
+	if e.IsEmpty() || o.IsEmpty() {
+		return false
+	}
+
+	return e.MinX <= o.MaxX && o.MinX <= e.MaxX && e.MinY <= o.MaxY && o.MinY <= e.MaxY
+}
+
+// Contains reports whether o lies entirely within e in the XY plane, including its boundary
+func (e Envelope) Contains(o Envelope) bool {
+	if e.IsEmpty() || o.IsEmpty() {
+		return false
+	}
+
+	return e.MinX <= o.MinX && o.MaxX <= e.MaxX && e.MinY <= o.MinY && o.MaxY <= e.MaxY
+}
+
+// Expand returns the envelope grown by d in each direction of the XY plane. A negative d shrinks the envelope,
+// which becomes empty once its width or height is used up.
+func (e Envelope) Expand(d float64) Envelope {
+	if e.IsEmpty() {
+		return e
+	}
+
+	e.MinX -= d
+	e.MinY -= d
+	e.MaxX += d
+	e.MaxY += d
+
+	return e
+}
+
+// BBox returns the envelope as a GeoJSON bbox member: the minimums followed by the maximums of X, Y and, when
+// present, Z. An empty envelope has no bbox.
+func (e Envelope) BBox() []float64 {
+	if e.IsEmpty() {
+		return nil
+	}
+
+	if e.HasZ {
+		return []float64{e.MinX, e.MinY, e.MinZ, e.MaxX, e.MaxY, e.MaxZ}
+	}
+
+	return []float64{e.MinX, e.MinY, e.MaxX, e.MaxY}
+}
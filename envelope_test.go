@@ -0,0 +1,160 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtent(t *testing.T) {
+	datasets := []struct {
+		name     string
+		data     Geometry
+		expected []float64
+	}{
+		{"point", &Point{Hdr{XY, 0}, Coordinate{1, 2}}, []float64{1, 2, 1, 2}},
+		{"linestring", &LineString{Hdr{XY, 0}, []Coordinate{{30, 10}, {10, 30}, {40, 40}}}, []float64{10, 10, 40, 40}},
+		{
+			"polygon z",
+			&Polygon{Hdr{XYZ, 0}, []LinearRing{{[]Coordinate{{0, 0, 1}, {4, 0, 2}, {4, 3, 5}, {0, 0, 1}}}}},
+			[]float64{0, 0, 1, 4, 3, 5},
+		},
+		{
+			"multipolygon",
+			&MultiPolygon{Hdr{XY, 0}, []Polygon{
+				{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+				{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{5, 5}, {6, 5}, {6, 7}, {5, 5}}}}},
+			}},
+			[]float64{0, 0, 6, 7},
+		},
+		{
+			"nested collection",
+			&GeometryCollection{Hdr{XY, 0}, []Geometry{
+				&Point{Hdr{XY, 0}, Coordinate{-1, -1}},
+				&GeometryCollection{Hdr{XY, 0}, []Geometry{
+					&MultiPoint{Hdr{XY, 0}, []Point{{Hdr{XY, 0}, Coordinate{3, 4}}}},
+				}},
+			}},
+			[]float64{-1, -1, 3, 4},
+		},
+		{
+			"semicircle",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}},
+			[]float64{-1, 0, 1, 1},
+		},
+		{
+			"major arc",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{0, 1}, {-1, 0}, {1, 0}}},
+			[]float64{-1, -1, 1, 1},
+		},
+		{
+			"minor arc",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{0, 1}, {math.Sqrt2 / 2, math.Sqrt2 / 2}, {1, 0}}},
+			[]float64{0, 0, 1, 1},
+		},
+		{
+			"full circle",
+			&CurvePolygon{Hdr{XY, 0}, []Geometry{&CircularString{Hdr{XY, 0}, []Coordinate{{3, 2}, {1, 2}, {3, 2}}}}},
+			[]float64{1, 1, 3, 3},
+		},
+		{
+			"tin",
+			&TIN{Hdr{XYZ, 0}, []Triangle{{Hdr{XYZ, 0}, []LinearRing{{[]Coordinate{{0, 0, 0}, {0, 1, 2}, {1, 1, 1}, {0, 0, 0}}}}}}},
+			[]float64{0, 0, 0, 1, 1, 2},
+		},
+		{"empty", &LineString{Hdr{XY, 0}, nil}, nil},
+		{"empty point", &Point{Hdr{XY, 0}, Coordinate{math.NaN(), math.NaN()}}, nil},
+	}
+
+	for _, dataset := range datasets {
+		e := Extent(dataset.data)
+
+		if dataset.expected == nil {
+			assert.True(t, e.IsEmpty(), dataset.name)
+			assert.Nil(t, e.BBox(), dataset.name)
+			continue
+		}
+
+		assert.InDeltaSlice(t, dataset.expected, e.BBox(), 1e-12, dataset.name)
+	}
+}
+
+func TestExtentM(t *testing.T) {
+	e := Extent(&LineString{Hdr{XYZM, 0}, []Coordinate{{0, 0, 5, 10}, {1, 1, 3, 20}}})
+
+	assert.True(t, e.HasZ)
+	assert.True(t, e.HasM)
+	assert.Equal(t, Envelope{0, 0, 1, 1, 3, 5, 10, 20, true, true}, e)
+
+	e = Extent(&Point{Hdr{XYM, 0}, Coordinate{1, 2, 3}})
+
+	assert.False(t, e.HasZ)
+	assert.True(t, e.HasM)
+	assert.Equal(t, 3.0, e.MinM)
+	assert.Equal(t, 3.0, e.MaxM)
+
+	// the flags follow the members of a collection rather than its own dimension
+	e = Extent(&GeometryCollection{Hdr{XY, 0}, []Geometry{
+		&Point{Hdr{XY, 0}, Coordinate{0, 0}},
+		&LineString{Hdr{XYZ, 0}, []Coordinate{{1, 1, 7}, {2, 2, 9}}},
+	}})
+
+	assert.Equal(t, Envelope{0, 0, 2, 2, 7, 9, math.Inf(1), math.Inf(-1), true, false}, e)
+	assert.Equal(t, []float64{0, 0, 7, 2, 2, 9}, e.BBox())
+
+	e = Extent(&Point{Hdr{XYZ, 0}, Coordinate{math.NaN(), math.NaN(), math.NaN()}})
+
+	assert.True(t, e.IsEmpty())
+	assert.False(t, e.HasZ)
+}
+
+func TestEnvelopeOperations(t *testing.T) {
+	a := Envelope{MinX: 0, MinY: 0, MaxX: 2, MaxY: 2}
+	b := Envelope{MinX: 1, MinY: 1, MaxX: 3, MaxY: 3}
+	c := Envelope{MinX: 5, MinY: 5, MaxX: 6, MaxY: 6}
+	inner := Envelope{MinX: 0.5, MinY: 0.5, MaxX: 1, MaxY: 2}
+	empty := Extent(nil)
+
+	assert.Equal(t, []float64{0, 0, 3, 3}, a.Union(b).BBox())
+	assert.Equal(t, a, a.Union(empty))
+	assert.Equal(t, a, empty.Union(a))
+
+	assert.True(t, a.Intersects(b))
+	assert.False(t, a.Intersects(c))
+	assert.True(t, a.Intersects(Envelope{MinX: 2, MinY: 2, MaxX: 4, MaxY: 4}), "touching envelopes intersect")
+	assert.False(t, a.Intersects(empty))
+
+	assert.True(t, a.Contains(inner))
+	assert.True(t, a.Contains(a))
+	assert.False(t, a.Contains(b))
+	assert.False(t, a.Contains(empty))
+
+	assert.Equal(t, []float64{-1, -1, 3, 3}, a.Expand(1).BBox())
+	assert.True(t, a.Expand(-1.5).IsEmpty())
+	assert.True(t, empty.Expand(1).IsEmpty())
+
+	assert.Equal(t, 2.0, a.Width())
+	assert.Equal(t, 0.0, empty.Height())
+
+	z := Envelope{MinX: 0, MinY: 0, MaxX: 1, MaxY: 1, MinZ: 5, MaxZ: 6, HasZ: true}
+	u := a.Union(z)
+	assert.True(t, u.HasZ)
+	assert.Equal(t, []float64{0, 0, 5, 2, 2, 6}, u.BBox())
+}
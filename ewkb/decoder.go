@@ -23,13 +23,72 @@ import (
 	"github.com/devork/geom"
 )
 
+// DefaultMaxDepth is the nesting depth allowed by a Decoder unless WithMaxDepth is given
+const DefaultMaxDepth = 64
+
+// maxPrealloc caps the number of elements allocated up front when the length of the input is not known, so a
+// hostile element count cannot allocate more than the input that actually follows
+const maxPrealloc = 1024
+
 // wraps the byte order and reader into a single struct for easier mainpulation
 type decoder struct {
 	order  binary.ByteOrder
 	reader io.Reader
+	src    *source
 	dim    dimension
 	gtype  geomtype
 	srid   uint32
+	format Format
+	limits limits
+	coords int
+	depth  int
+	code   uint32
+	path   path
+}
+
+// limits holds the resource limits applied while decoding, zero meaning unlimited
+type limits struct {
+	coords int
+	depth  int
+	bytes  int64
+}
+
+// source counts the bytes read from the underlying reader and enforces the byte limit
+type source struct {
+	r         io.Reader
+	offset    int64
+	remaining int64 // -1 when the length of the input is not known
+	max       int64
+}
+
+func newSource(r io.Reader, max int64) *source {
+	s := &source{r: r, remaining: -1, max: max}
+
+	if l, ok := r.(interface{ Len() int }); ok {
+		s.remaining = int64(l.Len())
+	}
+
+	return s
+}
+
+func (s *source) Read(p []byte) (int, error) {
+	if s.max > 0 && s.offset+int64(len(p)) > s.max {
+		if s.offset >= s.max {
+			return 0, ErrTooLarge
+		}
+
+		p = p[:s.max-s.offset]
+	}
+
+	n, err := s.r.Read(p)
+
+	s.offset += int64(n)
+
+	if s.remaining >= 0 {
+		s.remaining -= int64(n)
+	}
+
+	return n, err
 }
 
 func (d *decoder) read(data interface{}) error {
@@ -64,7 +123,98 @@ func (d *decoder) hdr() *geom.Hdr {
 	return &geom.Hdr{Dim: d.dim.dim(), Srid: d.srid}
 }
 
-func newDecoder(r io.Reader) (*decoder, error) {
+// count reads an element count, rejecting counts that cannot possibly fit in the remaining input given the minimum
+// encoded size of each element
+func (d *decoder) count(min int64) (uint32, error) {
+	n, err := d.u32()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if d.src.remaining >= 0 && int64(n)*min > d.src.remaining {
+		return 0, ErrCount
+	}
+
+	return n, nil
+}
+
+// capacity returns the number of elements to allocate up front for a count read from the input
+func (d *decoder) capacity(n uint32) int {
+	if d.src.remaining < 0 && n > maxPrealloc {
+		return maxPrealloc
+	}
+
+	return int(n)
+}
+
+// coordSize returns the encoded size of a coordinate in the current dimension
+func (d *decoder) coordSize() int64 {
+	switch d.dim {
+	case xyz, xyzs, xym, xyms:
+		return 24
+	case xyzm, xyzms:
+		return 32
+	default:
+		return 16
+	}
+}
+
+// enter records the start of the members of a collection, enforcing the depth limit
+func (d *decoder) enter() error {
+	d.depth++
+
+	if d.limits.depth > 0 && d.depth > d.limits.depth {
+		return ErrTooDeep
+	}
+
+	return nil
+}
+
+func (d *decoder) leave() {
+	d.depth--
+}
+
+// allowed reports whether the current geometry type is one of types
+func (d *decoder) allowed(types []geomtype) bool {
+	for _, t := range types {
+		if d.gtype == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// push adds a named element to the path, e.g. Ring
+func (d *decoder) push(name string) {
+	d.path = append(d.path, element{name, -1})
+}
+
+func (d *decoder) pop() {
+	d.path = d.path[:len(d.path)-1]
+}
+
+// at sets the index of the innermost path element
+func (d *decoder) at(idx uint32) {
+	d.path[len(d.path)-1].index = int(idx)
+}
+
+// fail wraps err in a DecodeError describing the current position, unless it is one already. Running out of input
+// part way through a geometry is reported as io.ErrUnexpectedEOF.
+func (d *decoder) fail(err error) error {
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return &DecodeError{Offset: d.src.offset, Path: d.path.String(), Code: d.code, Err: err}
+}
+
+func newDecoder(r *source) (*decoder, error) {
 	var otype byte
 	err := binary.Read(r, binary.BigEndian, &otype)
 
@@ -73,27 +223,95 @@ func newDecoder(r io.Reader) (*decoder, error) {
 	}
 
 	if otype == bigEndian {
-		return &decoder{order: binary.BigEndian, reader: r}, nil
+		return &decoder{order: binary.BigEndian, reader: r, src: r}, nil
 	}
-	return &decoder{order: binary.LittleEndian, reader: r}, nil
+	return &decoder{order: binary.LittleEndian, reader: r, src: r}, nil
+
+}
 
+// Decoder reads geometries from an input stream using the configured options
+type Decoder struct {
+	r      io.Reader
+	format Format
+	limits limits
+}
+
+// DecoderOption configures a Decoder
+type DecoderOption func(*Decoder)
+
+// WithStrictFormat rejects any geometry header that does not conform to the given format with ErrFormat. The default
+// is Any, which accepts WKB, ISO WKB and EWKB.
+func WithStrictFormat(format Format) DecoderOption {
+	return func(dec *Decoder) {
+		dec.format = format
+	}
+}
+
+// WithMaxCoordinates limits the total number of coordinates in a geometry, failing with ErrTooManyCoords
+func WithMaxCoordinates(n int) DecoderOption {
+	return func(dec *Decoder) {
+		dec.limits.coords = n
+	}
+}
+
+// WithMaxDepth limits how deeply geometries may be nested, failing with ErrTooDeep. A simple geometry has a depth of
+// 1 and the members of a collection a depth of 2. Zero removes the limit.
+func WithMaxDepth(n int) DecoderOption {
+	return func(dec *Decoder) {
+		dec.limits.depth = n
+	}
+}
+
+// WithMaxBytes limits the number of bytes read for a geometry, failing with ErrTooLarge
+func WithMaxBytes(n int64) DecoderOption {
+	return func(dec *Decoder) {
+		dec.limits.bytes = n
+	}
+}
+
+// NewDecoder creates a Decoder reading from r. When r reports its length, as bytes.Reader and strings.Reader do,
+// element counts that exceed the remaining input are rejected with ErrCount before anything is allocated.
+func NewDecoder(r io.Reader, opts ...DecoderOption) *Decoder {
+	dec := &Decoder{r: r, limits: limits{depth: DefaultMaxDepth}}
+
+	for _, opt := range opts {
+		opt(dec)
+	}
+
+	return dec
 }
 
 // Decode converts from the given reader to a geom type
 func Decode(r io.Reader) (geom.Geometry, error) {
+	return NewDecoder(r).Decode()
+}
 
-	decoder, err := newDecoder(r)
+// Decode reads the next geometry from the decoder's reader
+func (dec *Decoder) Decode() (geom.Geometry, error) {
 
-	if err != nil {
+	decoder, err := newDecoder(newSource(dec.r, dec.limits.bytes))
+
+	// a clean end of input is passed through so a stream of geometries can be read until io.EOF
+	if err == io.EOF {
 		return nil, err
 	}
 
+	if err != nil {
+		return nil, &DecodeError{Err: err}
+	}
+
+	decoder.format = dec.format
+	decoder.limits = dec.limits
+	decoder.depth = 1
+
 	err = unmarshalHdr(decoder)
 
 	if err != nil {
-		return nil, err
+		return nil, decoder.fail(err)
 	}
 
+	decoder.push(decoder.gtype.name())
+
 	return unmarshal(decoder)
 }
 
@@ -102,9 +320,11 @@ func unmarshalHdr(d *decoder) error {
 	gtype, err := d.u32()
 
 	if err != nil {
-		return err
+		return d.fail(err)
 	}
 
+	d.code = gtype
+
 	// fmt.Printf("gtype = %X\n", gtype)
 
 	var geomv, dim uint16
@@ -116,13 +336,19 @@ func unmarshalHdr(d *decoder) error {
 	// fmt.Printf("geomv = %X [%[1]d]\n", geomv)
 	// fmt.Printf("dim = %X\n", dim)
 
+	err = checkFormat(d.format, dim, geomv)
+
+	if err != nil {
+		return d.fail(err)
+	}
+
 	// switch on the mask first to check for EWKB
 	switch d.dim {
 	case xys, xyms, xyzs, xyzms:
 		err = d.read(&d.srid)
 
 		if err != nil {
-			return err
+			return d.fail(err)
 		}
 	}
 
@@ -139,15 +365,39 @@ func unmarshalHdr(d *decoder) error {
 		d.dim = xyz
 	}
 
+	if d.dim.dim() == geom.UNKNOWN {
+		return d.fail(geom.ErrUnknownDim)
+	}
+
 	d.gtype = geomtype(geomv)
 
 	return nil
 }
 
+// checkFormat validates the raw dimension flags and type code against a strict format
+func checkFormat(f Format, dim uint16, geomv uint16) error {
+	switch f {
+	case EWKB:
+		if geomv >= wkbz {
+			return ErrFormat
+		}
+	case ISO:
+		if dim != 0 {
+			return ErrFormat
+		}
+	case OGC:
+		if dim != 0 || geomv >= wkbz {
+			return ErrFormat
+		}
+	}
+
+	return nil
+}
+
 func unmarshalPoint(d *decoder) (geom.Geometry, error) {
 	coord, err := unmarshalCoord(d)
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
 	}
 
 	return &geom.Point{*d.hdr(), *coord}, nil
@@ -155,53 +405,48 @@ func unmarshalPoint(d *decoder) (geom.Geometry, error) {
 
 func unmarshalMultiPoint(d *decoder) (geom.Geometry, error) {
 	var idx uint32
-	numPoints, err := d.u32()
+	numPoints, err := d.count(21)
 
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
 	}
 
-	points := make([]geom.Point, numPoints, numPoints)
+	points := make([]geom.Point, 0, d.capacity(numPoints))
 	var point geom.Geometry
 	var hdr = d.hdr()
 	for idx = 0; idx < numPoints; idx++ {
+		d.at(idx)
 		d.u8() // byteorder
 		err = unmarshalHdr(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
 		point, err = unmarshalPoint(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
-		points[idx] = *point.(*geom.Point)
+		points = append(points, *point.(*geom.Point))
 	}
 
 	return &geom.MultiPoint{*hdr, points}, nil
 }
 
 func unmarshalLineString(d *decoder) (geom.Geometry, error) {
-	var idx uint32
-	numPoints, err := d.u32()
+	coords, err := unmarshalCoords(d)
 
 	if err != nil {
-		return nil, err
-	}
-
-	coords := make([]geom.Coordinate, numPoints, numPoints)
-	var coord *geom.Coordinate
-	for idx = 0; idx < numPoints; idx++ {
-		coord, err = unmarshalCoord(d)
-
-		if err != nil {
-			return nil, err
-		}
-
-		coords[idx] = *coord
+		return nil, d.fail(err)
 	}
 
 	return &geom.LineString{*d.hdr(), coords}, nil
@@ -209,30 +454,38 @@ func unmarshalLineString(d *decoder) (geom.Geometry, error) {
 
 func unmarshalMultiLineString(d *decoder) (geom.Geometry, error) {
 	var idx uint32
-	numStrings, err := d.u32()
+	numStrings, err := d.count(9)
 
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
 	}
 
-	lstrings := make([]geom.LineString, numStrings, numStrings)
+	lstrings := make([]geom.LineString, 0, d.capacity(numStrings))
 	var lstring geom.Geometry
 	var hdr = d.hdr()
 	for idx = 0; idx < numStrings; idx++ {
+		d.at(idx)
 		d.u8() // byteorder
 		err = unmarshalHdr(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
 		lstring, err = unmarshalLineString(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
-		lstrings[idx] = *lstring.(*geom.LineString)
+		lstrings = append(lstrings, *lstring.(*geom.LineString))
 	}
 
 	return &geom.MultiLineString{*hdr, lstrings}, nil
@@ -240,53 +493,64 @@ func unmarshalMultiLineString(d *decoder) (geom.Geometry, error) {
 
 func unmarshalPolygon(d *decoder) (geom.Geometry, error) {
 	var idx uint32
-	numRings, err := d.u32()
+	numRings, err := d.count(4)
 
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
 	}
 
-	rings := make([]geom.LinearRing, numRings, numRings)
+	rings := make([]geom.LinearRing, 0, d.capacity(numRings))
 	var ring *geom.LinearRing
+	d.push("Ring")
 	for idx = 0; idx < numRings; idx++ {
+		d.at(idx)
 		ring, err = unmarshalLinearRing(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
-		rings[idx] = *ring
+		rings = append(rings, *ring)
 	}
+	d.pop()
 
 	return &geom.Polygon{*d.hdr(), rings}, nil
 }
 
 func unmarshalMultiPolygon(d *decoder) (geom.Geometry, error) {
 	var idx uint32
-	numPolys, err := d.u32()
+	numPolys, err := d.count(9)
 
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
 	}
 
-	polys := make([]geom.Polygon, numPolys, numPolys)
+	polys := make([]geom.Polygon, 0, d.capacity(numPolys))
 	var poly geom.Geometry
 	var hdr = d.hdr()
 	for idx = 0; idx < numPolys; idx++ {
+		d.at(idx)
 		d.u8() // byteorder
 		err = unmarshalHdr(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
 		poly, err = unmarshalPolygon(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
-		polys[idx] = *poly.(*geom.Polygon)
+		polys = append(polys, *poly.(*geom.Polygon))
 	}
 
 	return &geom.MultiPolygon{*hdr, polys}, nil
@@ -294,74 +558,291 @@ func unmarshalMultiPolygon(d *decoder) (geom.Geometry, error) {
 
 func unmarshalGeometryCollection(d *decoder) (geom.Geometry, error) {
 	var idx uint32
-	numGeoms, err := d.u32()
+	numGeoms, err := d.count(9)
 
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
 	}
 
-	geoms := make([]geom.Geometry, numGeoms, numGeoms)
+	geoms := make([]geom.Geometry, 0, d.capacity(numGeoms))
 	var g geom.Geometry
 	var hdr = d.hdr()
 	for idx = 0; idx < numGeoms; idx++ {
+		d.at(idx)
 		d.u8()
 		err := unmarshalHdr(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
+		d.push(d.gtype.name())
 		g, err = unmarshal(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
-		geoms[idx] = g
+		d.pop()
+
+		geoms = append(geoms, g)
 	}
 
 	return &geom.GeometryCollection{*hdr, geoms}, nil
 }
 
+func unmarshalCircularString(d *decoder) (geom.Geometry, error) {
+	coords, err := unmarshalCoords(d)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	return &geom.CircularString{*d.hdr(), coords}, nil
+}
+
+func unmarshalCompoundCurve(d *decoder) (geom.Geometry, error) {
+	var hdr = d.hdr()
+	curves, err := unmarshalMembers(d, linestring, circularstring)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	return &geom.CompoundCurve{*hdr, curves}, nil
+}
+
+func unmarshalCurvePolygon(d *decoder) (geom.Geometry, error) {
+	var hdr = d.hdr()
+	rings, err := unmarshalMembers(d, linestring, circularstring, compoundcurve)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	return &geom.CurvePolygon{*hdr, rings}, nil
+}
+
+func unmarshalMultiCurve(d *decoder) (geom.Geometry, error) {
+	var hdr = d.hdr()
+	curves, err := unmarshalMembers(d, linestring, circularstring, compoundcurve)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	return &geom.MultiCurve{*hdr, curves}, nil
+}
+
+func unmarshalMultiSurface(d *decoder) (geom.Geometry, error) {
+	var hdr = d.hdr()
+	surfaces, err := unmarshalMembers(d, polygon, curvepolygon)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	return &geom.MultiSurface{*hdr, surfaces}, nil
+}
+
+func unmarshalPolyhedralSurface(d *decoder) (geom.Geometry, error) {
+	var idx uint32
+	numPolys, err := d.count(9)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	polys := make([]geom.Polygon, 0, d.capacity(numPolys))
+	var poly geom.Geometry
+	var hdr = d.hdr()
+	for idx = 0; idx < numPolys; idx++ {
+		d.at(idx)
+		d.u8() // byteorder
+		err = unmarshalHdr(d)
+
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		poly, err = unmarshalPolygon(d)
+
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		polys = append(polys, *poly.(*geom.Polygon))
+	}
+
+	return &geom.PolyhedralSurface{*hdr, polys}, nil
+}
+
+func unmarshalTIN(d *decoder) (geom.Geometry, error) {
+	var idx uint32
+	numTris, err := d.count(9)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	tris := make([]geom.Triangle, 0, d.capacity(numTris))
+	var tri geom.Geometry
+	var hdr = d.hdr()
+	for idx = 0; idx < numTris; idx++ {
+		d.at(idx)
+		d.u8() // byteorder
+		err = unmarshalHdr(d)
+
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		tri, err = unmarshalTriangle(d)
+
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		tris = append(tris, *tri.(*geom.Triangle))
+	}
+
+	return &geom.TIN{*hdr, tris}, nil
+}
+
+func unmarshalTriangle(d *decoder) (geom.Geometry, error) {
+	poly, err := unmarshalPolygon(d)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	p := poly.(*geom.Polygon)
+
+	return &geom.Triangle{p.Hdr, p.Rings}, nil
+}
+
+// unmarshalMembers reads the members of a curve or surface type, each of which carries its own header and must be
+// one of the allowed types
+func unmarshalMembers(d *decoder, allowed ...geomtype) ([]geom.Geometry, error) {
+	var idx uint32
+	numMembers, err := d.count(9)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	err = d.enter()
+	defer d.leave()
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	members := make([]geom.Geometry, 0, d.capacity(numMembers))
+	var g geom.Geometry
+	for idx = 0; idx < numMembers; idx++ {
+		d.at(idx)
+		d.u8() // byteorder
+		err = unmarshalHdr(d)
+
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		d.push(d.gtype.name())
+
+		if !d.allowed(allowed) {
+			return nil, d.fail(geom.ErrUnsupportedGeom)
+		}
+
+		g, err = unmarshal(d)
+
+		if err != nil {
+			return nil, d.fail(err)
+		}
+		d.pop()
+
+		members = append(members, g)
+	}
+
+	return members, nil
+}
+
 func unmarshalLinearRing(d *decoder) (*geom.LinearRing, error) {
+	coords, err := unmarshalCoords(d)
+
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	return &geom.LinearRing{coords}, nil
+}
+
+func unmarshalCoords(d *decoder) ([]geom.Coordinate, error) {
 	var idx uint32
-	numPoints, err := d.u32()
+	numPoints, err := d.count(d.coordSize())
 
 	if err != nil {
-		return nil, err
+		return nil, d.fail(err)
 	}
 
-	coords := make([]geom.Coordinate, numPoints, numPoints)
+	if d.limits.coords > 0 && d.coords+int(numPoints) > d.limits.coords {
+		return nil, d.fail(ErrTooManyCoords)
+	}
+
+	coords := make([]geom.Coordinate, 0, d.capacity(numPoints))
 	var coord *geom.Coordinate
+	d.push("Point")
 	for idx = 0; idx < numPoints; idx++ {
+		d.at(idx)
 		coord, err = unmarshalCoord(d)
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 
-		coords[idx] = *coord
+		coords = append(coords, *coord)
 	}
+	d.pop()
 
-	return &geom.LinearRing{coords}, nil
+	return coords, nil
 }
 
 func unmarshalCoord(d *decoder) (*geom.Coordinate, error) {
-	var size int
-	switch d.dim {
-	case xyz, xyzs, xym, xyms:
-		size = 3
-	case xyzm, xyzms:
-		size = 4
-	default:
-		size = 2
+	d.coords++
+
+	if d.limits.coords > 0 && d.coords > d.limits.coords {
+		return nil, d.fail(ErrTooManyCoords)
 	}
 
+	size := int(d.coordSize() / 8)
+
 	var coord geom.Coordinate = make([]float64, size, size)
 	for idx := 0; idx < size; idx++ {
 		err := d.read(&coord[idx])
 
 		if err != nil {
-			return nil, err
+			return nil, d.fail(err)
 		}
 	}
 
@@ -385,7 +866,23 @@ func unmarshal(d *decoder) (geom.Geometry, error) {
 		return unmarshalMultiPolygon(d)
 	case geometrycollection:
 		return unmarshalGeometryCollection(d)
+	case circularstring:
+		return unmarshalCircularString(d)
+	case compoundcurve:
+		return unmarshalCompoundCurve(d)
+	case curvepolygon:
+		return unmarshalCurvePolygon(d)
+	case multicurve:
+		return unmarshalMultiCurve(d)
+	case multisurface:
+		return unmarshalMultiSurface(d)
+	case polyhedralsurface:
+		return unmarshalPolyhedralSurface(d)
+	case tin:
+		return unmarshalTIN(d)
+	case triangle:
+		return unmarshalTriangle(d)
 	default:
-		return nil, geom.ErrUnsupportedGeom
+		return nil, d.fail(geom.ErrUnsupportedGeom)
 	}
 }
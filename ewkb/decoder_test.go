@@ -130,7 +130,10 @@ All test cases were generated from PostGIS:
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/devork/geom"
@@ -691,3 +694,245 @@ func TestDimensionsAndEndian(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeStrictFormat(t *testing.T) {
+	ewkb := "00a000000100006c343ff00000000000003ff00000000000003ff0000000000000"
+	iso := "00000003e93ff00000000000003ff00000000000003ff0000000000000"
+	ogc := "00000000013ff00000000000003ff0000000000000"
+
+	datasets := []struct {
+		data   string
+		format Format
+		err    error
+	}{
+		{ewkb, Any, nil},
+		{iso, Any, nil},
+		{ogc, Any, nil},
+		{ewkb, EWKB, nil},
+		{iso, EWKB, ErrFormat},
+		{ogc, EWKB, nil},
+		{ewkb, ISO, ErrFormat},
+		{iso, ISO, nil},
+		{ogc, ISO, nil},
+		{ewkb, OGC, ErrFormat},
+		{iso, OGC, ErrFormat},
+		{ogc, OGC, nil},
+	}
+
+	for _, dataset := range datasets {
+		data, err := hex.DecodeString(dataset.data)
+
+		if err != nil {
+			t.Fatal("Failed to decode HEX string: err = ", err)
+		}
+
+		_, err = NewDecoder(bytes.NewReader(data), WithStrictFormat(dataset.format)).Decode()
+
+		assert.ErrorIs(t, err, dataset.err, "Decoding %s with format %d", dataset.data, dataset.format)
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	// a linestring claiming 0xFFFFFFFF points, followed by a single point
+	hostile := "0000000002ffffffff3ff00000000000003ff0000000000000"
+	// a geometry collection nested three deep around a point
+	nested := "000000000700000001000000000700000001000000000700000001" + "00000000013ff00000000000003ff0000000000000"
+	// a linestring of three points
+	lstring := "000000000200000003403e00000000000040240000000000004024000000000000403e00000000000040440000000000004044000000000000"
+
+	datasets := []struct {
+		data     string
+		opts     []DecoderOption
+		sized    bool
+		expected error
+	}{
+		{hostile, nil, true, ErrCount},
+		{hostile, nil, false, io.ErrUnexpectedEOF},
+		{nested, nil, true, nil},
+		{nested, []DecoderOption{WithMaxDepth(3)}, true, ErrTooDeep},
+		{nested, []DecoderOption{WithMaxDepth(4)}, true, nil},
+		{lstring, []DecoderOption{WithMaxCoordinates(2)}, true, ErrTooManyCoords},
+		{lstring, []DecoderOption{WithMaxCoordinates(3)}, true, nil},
+		{lstring, []DecoderOption{WithMaxBytes(56)}, false, ErrTooLarge},
+		{lstring, []DecoderOption{WithMaxBytes(57)}, false, nil},
+	}
+
+	for _, dataset := range datasets {
+		data, err := hex.DecodeString(dataset.data)
+
+		if err != nil {
+			t.Fatal("Failed to decode HEX string: err = ", err)
+		}
+
+		var r io.Reader = bytes.NewReader(data)
+
+		// hide the length of the input from the decoder
+		if !dataset.sized {
+			r = io.MultiReader(r)
+		}
+
+		_, err = NewDecoder(r, dataset.opts...).Decode()
+
+		assert.ErrorIs(t, err, dataset.expected, "Decoding %s", dataset.data)
+	}
+
+	_, err := DecodeHex(hostile)
+	assert.ErrorIs(t, err, ErrCount)
+}
+
+func TestDecodeError(t *testing.T) {
+	// a multipolygon of two squares, the second truncated part way through the fourth point of its shell
+	data := "000000000600000002" +
+		"00000000030000000100000004" + "00000000000000000000000000000000" + "3ff00000000000000000000000000000" + "3ff00000000000003ff0000000000000" + "00000000000000000000000000000000" +
+		"00000000030000000100000005" + "00000000000000000000000000000000" + "3ff00000000000000000000000000000" + "3ff00000000000003ff0000000000000" + "00000000000000003ff0"
+
+	raw, err := hex.DecodeString(data)
+
+	if err != nil {
+		t.Fatal("Failed to decode HEX string: err = ", err)
+	}
+
+	// hide the length of the input so the truncation is found while reading the point
+	_, err = NewDecoder(io.MultiReader(bytes.NewReader(raw))).Decode()
+
+	var derr *DecodeError
+	if !errors.As(err, &derr) {
+		t.Fatalf("Expected a DecodeError: err = %v", err)
+	}
+
+	assert.Equal(t, int64(len(raw)), derr.Offset)
+	assert.Equal(t, "MultiPolygon[1].Ring[0].Point[3]", derr.Path)
+	assert.Equal(t, uint32(polygon), derr.Code)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "ewkb: unexpected EOF at offset 157 in MultiPolygon[1].Ring[0].Point[3] (type 0x00000003)", err.Error())
+
+	// failures in a geometry collection are reported against the member type
+	_, err = DecodeHex("000000000700000001000000006300000000")
+
+	assert.True(t, errors.As(err, &derr))
+	assert.Equal(t, "GeometryCollection[0].Geometry", derr.Path)
+	assert.Equal(t, uint32(0x63), derr.Code)
+	assert.ErrorIs(t, err, geom.ErrUnsupportedGeom)
+}
+
+func TestCurves(t *testing.T) {
+	arc := []geom.Coordinate{{0, 0}, {1, 1}, {2, 0}}
+	compound := func(srid uint32) *geom.CompoundCurve {
+		return &geom.CompoundCurve{geom.Hdr{geom.XY, srid}, []geom.Geometry{
+			&geom.CircularString{geom.Hdr{geom.XY, srid}, arc},
+			&geom.LineString{geom.Hdr{geom.XY, srid}, []geom.Coordinate{{2, 0}, {0, 0}}},
+		}}
+	}
+
+	datasets := []struct {
+		data     string
+		expected geom.Geometry
+	}{
+		{
+			"0108000020346c00000300000000000000000000000000000000000000000000000000f03f000000000000f03f00000000000000400000000000000000",
+			&geom.CircularString{geom.Hdr{geom.XY, 27700}, arc},
+		},
+		{
+			"002000000800006c3400000003000000000000000000000000000000003ff00000000000003ff000000000000040000000000000000000000000000000",
+			&geom.CircularString{geom.Hdr{geom.XY, 27700}, arc},
+		},
+		{
+			"01f00300000300000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000f03f0000000000000040000000000000004000000000000000000000000000000840",
+			&geom.CircularString{geom.Hdr{geom.XYZ, 0}, []geom.Coordinate{{0, 0, 1}, {1, 1, 2}, {2, 0, 3}}},
+		},
+		{
+			"0109000020346c00000200000001080000000300000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000004000000000000000000102000000020000000000000000000040000000000000000000000000000000000000000000000000",
+			compound(27700),
+		},
+		{
+			"010a000020346c00000200000001090000000200000001080000000300000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000004000000000000000000102000000020000000000000000000040000000000000000000000000000000000000000000000000010800000003000000000000000000e03f000000000000d03f000000000000f83f000000000000d03f000000000000e03f000000000000d03f",
+			&geom.CurvePolygon{geom.Hdr{geom.XY, 27700}, []geom.Geometry{
+				compound(27700),
+				&geom.CircularString{geom.Hdr{geom.XY, 27700}, []geom.Coordinate{{0.5, 0.25}, {1.5, 0.25}, {0.5, 0.25}}},
+			}},
+		},
+		{
+			"010b0000000200000001020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f01080000000300000000000000000000000000000000000000000000000000f03f000000000000f03f00000000000000400000000000000000",
+			&geom.MultiCurve{geom.Hdr{geom.XY, 0}, []geom.Geometry{
+				&geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{0, 0}, {1, 1}}},
+				&geom.CircularString{geom.Hdr{geom.XY, 0}, arc},
+			}},
+		},
+		{
+			"010c000020346c0000020000000103000000010000000400000000000000000000000000000000000000000000000000f03f0000000000000000000000000000f03f000000000000f03f00000000000000000000000000000000010a00000001000000010800000003000000000000000000104000000000000000000000000000001840000000000000000000000000000010400000000000000000",
+			&geom.MultiSurface{geom.Hdr{geom.XY, 27700}, []geom.Geometry{
+				&geom.Polygon{geom.Hdr{geom.XY, 27700}, []geom.LinearRing{{[]geom.Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+				&geom.CurvePolygon{geom.Hdr{geom.XY, 27700}, []geom.Geometry{
+					&geom.CircularString{geom.Hdr{geom.XY, 27700}, []geom.Coordinate{{4, 0}, {6, 0}, {4, 0}}},
+				}},
+			}},
+		},
+	}
+
+	for _, dataset := range datasets {
+		g, err := DecodeHex(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset.data, err)
+		}
+
+		assert.Equal(t, dataset.expected, g)
+	}
+
+	// a multicurve cannot contain a polygon
+	_, err := DecodeHex("010b00000001000000010300000000000000")
+
+	var derr *DecodeError
+	assert.True(t, errors.As(err, &derr))
+	assert.Equal(t, "MultiCurve[0].Polygon", derr.Path)
+	assert.ErrorIs(t, err, geom.ErrUnsupportedGeom)
+}
+
+func TestSurfaces(t *testing.T) {
+	hdr := geom.Hdr{geom.XYZ, 0}
+	t1 := []geom.Coordinate{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 0}}
+	t2 := []geom.Coordinate{{0, 0, 0}, {1, 1, 0}, {1, 0, 0}, {0, 0, 0}}
+	floor := []geom.Coordinate{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 0, 0}, {0, 0, 0}}
+	wall := []geom.Coordinate{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}, {0, 1, 0}, {0, 0, 0}}
+
+	datasets := []struct {
+		data     string
+		expected geom.Geometry
+	}{
+		{
+			"010f0000a0346c000002000000010300008001000000050000000000000000000000000000000000000000000000000000000000000000000000000000000000f03f0000000000000000000000000000f03f000000000000f03f0000000000000000000000000000f03f000000000000000000000000000000000000000000000000000000000000000000000000000000000103000080010000000500000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000f03f0000000000000000000000000000f03f000000000000f03f0000000000000000000000000000f03f0000000000000000000000000000000000000000000000000000000000000000",
+			&geom.PolyhedralSurface{geom.Hdr{geom.XYZ, 27700}, []geom.Polygon{
+				{geom.Hdr{geom.XYZ, 27700}, []geom.LinearRing{{floor}}},
+				{geom.Hdr{geom.XYZ, 27700}, []geom.LinearRing{{wall}}},
+			}},
+		},
+		{
+			"011000008002000000011100008001000000040000000000000000000000000000000000000000000000000000000000000000000000000000000000f03f0000000000000000000000000000f03f000000000000f03f000000000000000000000000000000000000000000000000000000000000000001110000800100000004000000000000000000000000000000000000000000000000000000000000000000f03f000000000000f03f0000000000000000000000000000f03f00000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			&geom.TIN{hdr, []geom.Triangle{{hdr, []geom.LinearRing{{t1}}}, {hdr, []geom.LinearRing{{t2}}}}},
+		},
+		{
+			"01110000000100000004000000000000000000000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000f03f00000000000000000000000000000000",
+			&geom.Triangle{geom.Hdr{geom.XY, 0}, []geom.LinearRing{{[]geom.Coordinate{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}}},
+		},
+	}
+
+	for _, dataset := range datasets {
+		g, err := DecodeHex(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset.data, err)
+		}
+
+		assert.Equal(t, dataset.expected, g)
+
+		// and back again
+		var w = new(bytes.Buffer)
+		err = NewEncoder(w, WithByteOrder(binary.LittleEndian), WithFormat(EWKB)).Encode(g)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", g.Type(), err)
+		}
+
+		assert.Equal(t, dataset.data, hex.EncodeToString(w.Bytes()))
+	}
+}
@@ -24,22 +24,76 @@ import (
 )
 
 type encoder struct {
-	w io.Writer
-	o binary.ByteOrder
+	w      io.Writer
+	o      binary.ByteOrder
+	flag   uint8
+	format Format
+	nested bool
 }
 
 func (d *encoder) write(data interface{}) error {
 	return binary.Write(d.w, d.o, data)
 }
 
-//Encode will take the given geometry and write to the specified writer
+// Encoder writes geometries to an output stream using the configured options
+type Encoder struct {
+	w      io.Writer
+	order  binary.ByteOrder
+	format Format
+}
+
+// EncoderOption configures an Encoder
+type EncoderOption func(*Encoder)
+
+// WithByteOrder sets the byte order used for every header and value written. The default is binary.BigEndian (XDR),
+// while Postgis and most database drivers emit binary.LittleEndian (NDR).
+func WithByteOrder(order binary.ByteOrder) EncoderOption {
+	return func(enc *Encoder) {
+		enc.order = order
+	}
+}
+
+// WithFormat sets the binary format written. The default is Any.
+func WithFormat(format Format) EncoderOption {
+	return func(enc *Encoder) {
+		enc.format = format
+	}
+}
+
+// NewEncoder creates an Encoder writing to w
+func NewEncoder(w io.Writer, opts ...EncoderOption) *Encoder {
+	enc := &Encoder{w: w, order: binary.BigEndian}
+
+	for _, opt := range opts {
+		opt(enc)
+	}
+
+	return enc
+}
+
+// Encode will take the given geometry and write to the specified writer
 func Encode(g geom.Geometry, w io.Writer) error {
+	return NewEncoder(w).Encode(g)
+}
+
+// Encode will take the given geometry and write it to the encoder's writer
+func (enc *Encoder) Encode(g geom.Geometry) error {
 
 	if g == nil {
 		return geom.ErrNoGeometry
 	}
 
-	e := &encoder{w, binary.BigEndian}
+	e := &encoder{w: enc.w, o: enc.order, flag: bigEndian, format: enc.format}
+
+	// the flag is derived from the order itself so binary.NativeEndian and friends are marked correctly
+	if enc.order.Uint16([]byte{0x01, 0x00}) == 0x01 {
+		e.flag = littleEndian
+	}
+
+	// Any writes the whole geometry as EWKB when it carries an SRID
+	if e.format == Any && g.SRID() != 0 {
+		e.format = EWKB
+	}
 
 	err := marshalHdr(g, e)
 
@@ -47,6 +101,9 @@ func Encode(g geom.Geometry, w io.Writer) error {
 		return err
 	}
 
+	// the SRID is only written on the outermost header, as with Postgis
+	e.nested = true
+
 	return marshal(g, e)
 }
 
@@ -187,6 +244,101 @@ func marshalGeometryCollection(gc *geom.GeometryCollection, e *encoder) error {
 	return nil
 }
 
+func marshalCircularString(c *geom.CircularString, e *encoder) error {
+	err := e.write(uint32(len(c.Coordinates)))
+
+	if err != nil {
+		return err
+	}
+
+	for _, coord := range c.Coordinates {
+		err = marshalCoord(&coord, e)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func marshalPolyhedralSurface(ps *geom.PolyhedralSurface, e *encoder) error {
+	err := e.write(uint32(len(ps.Polygons)))
+
+	if err != nil {
+		return err
+	}
+
+	for _, polygon := range ps.Polygons {
+		err = marshalHdr(&polygon, e)
+
+		if err != nil {
+			return err
+		}
+
+		err = marshalPolygon(&polygon, e)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func marshalTIN(t *geom.TIN, e *encoder) error {
+	err := e.write(uint32(len(t.Triangles)))
+
+	if err != nil {
+		return err
+	}
+
+	for _, tri := range t.Triangles {
+		err = marshalHdr(&tri, e)
+
+		if err != nil {
+			return err
+		}
+
+		err = marshalTriangle(&tri, e)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func marshalTriangle(t *geom.Triangle, e *encoder) error {
+	return marshalPolygon(&geom.Polygon{t.Hdr, t.Rings}, e)
+}
+
+// marshalMembers writes the count and members of a curve or surface type, each with its own header
+func marshalMembers(members []geom.Geometry, e *encoder) error {
+	err := e.write(uint32(len(members)))
+
+	if err != nil {
+		return err
+	}
+
+	for _, g := range members {
+		err = marshalHdr(g, e)
+
+		if err != nil {
+			return err
+		}
+
+		err = marshal(g, e)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func marshalLinearRing(l *geom.LinearRing, e *encoder) error {
 	err := e.write(uint32(len(l.Coordinates)))
 
@@ -207,7 +359,15 @@ func marshalLinearRing(l *geom.LinearRing, e *encoder) error {
 
 func marshalCoord(c *geom.Coordinate, e *encoder) error {
 	var err error
-	for idx := 0; idx < len(*c); idx++ {
+
+	size := len(*c)
+
+	// OGC WKB is 2D only, so write the shadow of the coordinate
+	if e.format == OGC && size > 2 {
+		size = 2
+	}
+
+	for idx := 0; idx < size; idx++ {
 		err = e.write((*c)[idx])
 
 		if err != nil {
@@ -219,7 +379,7 @@ func marshalCoord(c *geom.Coordinate, e *encoder) error {
 }
 
 func marshalHdr(g geom.Geometry, e *encoder) error {
-	err := e.write(bigEndian)
+	err := e.write(e.flag)
 
 	if err != nil {
 		return err
@@ -244,30 +404,60 @@ func marshalHdr(g geom.Geometry, e *encoder) error {
 		gtype = multipolygon
 	case *geom.GeometryCollection:
 		gtype = geometrycollection
+	case *geom.CircularString:
+		gtype = circularstring
+	case *geom.CompoundCurve:
+		gtype = compoundcurve
+	case *geom.CurvePolygon:
+		gtype = curvepolygon
+	case *geom.MultiCurve:
+		gtype = multicurve
+	case *geom.MultiSurface:
+		gtype = multisurface
+	case *geom.PolyhedralSurface:
+		gtype = polyhedralsurface
+	case *geom.TIN:
+		gtype = tin
+	case *geom.Triangle:
+		gtype = triangle
 	default:
 		return geom.ErrUnsupportedGeom
 	}
 
-	if g.SRID() != 0 {
-
-		writeSrid = true
-
+	switch e.format {
+	case EWKB:
 		switch g.Dimension() {
 		case geom.XY:
-			field = uint32(xys)
+			field = uint32(xy)
 		case geom.XYZ:
-			field = uint32(xyzs)
+			field = uint32(xyz)
 		case geom.XYM:
-			field = uint32(xyms)
+			field = uint32(xym)
 		case geom.XYZM:
-			field = uint32(xyzms)
+			field = uint32(xyzm)
 		default:
 			return geom.ErrUnknownDim
 		}
 
+		if g.SRID() != 0 && !e.nested {
+			writeSrid = true
+			field |= uint32(xys)
+		}
+
 		field <<= 16
 		field |= uint32(gtype)
-	} else {
+	case OGC:
+		if g.Dimension() == geom.UNKNOWN {
+			return geom.ErrUnknownDim
+		}
+
+		// OGC WKB 1.1 only has codes for the seven simple feature types
+		if gtype > geometrycollection {
+			return geom.ErrUnsupportedGeom
+		}
+
+		field = uint32(gtype)
+	default:
 		switch g.Dimension() {
 		case geom.XYZM:
 			field = uint32(wkbzm) + uint32(gtype)
@@ -311,6 +501,22 @@ func marshal(g geom.Geometry, e *encoder) error {
 		return marshalMultiPolygon(g, e)
 	case *geom.GeometryCollection:
 		return marshalGeometryCollection(g, e)
+	case *geom.CircularString:
+		return marshalCircularString(g, e)
+	case *geom.CompoundCurve:
+		return marshalMembers(g.Curves, e)
+	case *geom.CurvePolygon:
+		return marshalMembers(g.Rings, e)
+	case *geom.MultiCurve:
+		return marshalMembers(g.Curves, e)
+	case *geom.MultiSurface:
+		return marshalMembers(g.Surfaces, e)
+	case *geom.PolyhedralSurface:
+		return marshalPolyhedralSurface(g, e)
+	case *geom.TIN:
+		return marshalTIN(g, e)
+	case *geom.Triangle:
+		return marshalTriangle(g, e)
 	default:
 		return geom.ErrUnsupportedGeom
 	}
@@ -18,6 +18,7 @@ package ewkb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"strings"
 	"testing"
@@ -263,3 +264,220 @@ func TestEncodeGeometryCollection(t *testing.T) {
 
 	}
 }
+
+func TestEncodeNestedSrid(t *testing.T) {
+	datasets := []string{
+		"002000000700006c340000000200000000014010000000000000401800000000000000000000020000000240100000000000004018000000000000401c0000000000004024000000000000",
+		"002000000400006c340000000400000000014024000000000000404400000000000000000000014044000000000000403e0000000000000000000001403400000000000040340000000000000000000001403e0000000000004024000000000000",
+	}
+
+	for _, dataset := range datasets {
+		data, err := hex.DecodeString(dataset)
+
+		if err != nil {
+			t.Fatal("Failed to decode HEX string: err = ", err)
+		}
+
+		g, err := Decode(bytes.NewReader(data))
+
+		if err != nil {
+			t.Fatalf("Failed to decode geometry: err = %s", err)
+		}
+
+		var w = new(bytes.Buffer)
+		err = Encode(g, w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", g.Type(), err)
+		}
+
+		assert.Equal(t, dataset, hex.EncodeToString(w.Bytes()))
+	}
+}
+
+func TestEncodeByteOrder(t *testing.T) {
+	datasets := []struct {
+		data     geom.Geometry
+		order    binary.ByteOrder
+		expected string
+	}{
+		{&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{1, 1}}, binary.BigEndian, "00000000013ff00000000000003ff0000000000000"},
+		{&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{1, 1}}, binary.LittleEndian, "0101000000000000000000f03f000000000000f03f"},
+		{&geom.Point{geom.Hdr{geom.XYZ, 0}, geom.Coordinate{1, 1, 1}}, binary.LittleEndian, "01e9030000000000000000f03f000000000000f03f000000000000f03f"},
+		{
+			&geom.GeometryCollection{geom.Hdr{geom.XY, 27700}, []geom.Geometry{
+				&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{4, 6}},
+				&geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{4, 6}, {7, 10}}},
+			}},
+			binary.LittleEndian,
+			"0107000020346c000002000000010100000000000000000010400000000000001840010200000002000000000000000000104000000000000018400000000000001c400000000000002440",
+		},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+		err := NewEncoder(w, WithByteOrder(dataset.order)).Encode(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", dataset.data.Type(), err)
+		}
+
+		assert.Equal(t, dataset.expected, hex.EncodeToString(w.Bytes()))
+
+		g, err := Decode(w)
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s geometry: err = %s", dataset.data.Type(), err)
+		}
+
+		assert.Equal(t, dataset.data.Type(), g.Type())
+		assert.Equal(t, dataset.data.SRID(), g.SRID())
+	}
+}
+
+func TestEncodeFormat(t *testing.T) {
+	datasets := []struct {
+		data     geom.Geometry
+		format   Format
+		expected string
+	}{
+		{&geom.Point{geom.Hdr{geom.XYZ, 27700}, geom.Coordinate{1, 1, 1}}, Any, "00a000000100006c343ff00000000000003ff00000000000003ff0000000000000"},
+		{&geom.Point{geom.Hdr{geom.XYZ, 27700}, geom.Coordinate{1, 1, 1}}, EWKB, "00a000000100006c343ff00000000000003ff00000000000003ff0000000000000"},
+		{&geom.Point{geom.Hdr{geom.XYZ, 0}, geom.Coordinate{1, 1, 1}}, EWKB, "00800000013ff00000000000003ff00000000000003ff0000000000000"},
+		{&geom.Point{geom.Hdr{geom.XYZ, 27700}, geom.Coordinate{1, 1, 1}}, ISO, "00000003e93ff00000000000003ff00000000000003ff0000000000000"},
+		{&geom.Point{geom.Hdr{geom.XYM, 27700}, geom.Coordinate{1, 1, 1}}, ISO, "00000007d13ff00000000000003ff00000000000003ff0000000000000"},
+		{&geom.Point{geom.Hdr{geom.XYZM, 27700}, geom.Coordinate{1, 1, 1, 1}}, OGC, "00000000013ff00000000000003ff0000000000000"},
+		{
+			&geom.MultiPoint{geom.Hdr{geom.XYZ, 27700}, []geom.Point{
+				geom.Point{geom.Hdr{geom.XYZ, 27700}, geom.Coordinate{1, 1, 1}},
+			}},
+			EWKB,
+			"00a000000400006c34000000010080000001" + "3ff00000000000003ff00000000000003ff0000000000000",
+		},
+		{
+			&geom.MultiPoint{geom.Hdr{geom.XYZ, 27700}, []geom.Point{
+				geom.Point{geom.Hdr{geom.XYZ, 27700}, geom.Coordinate{1, 1, 1}},
+			}},
+			ISO,
+			"00000003ec0000000100000003e9" + "3ff00000000000003ff00000000000003ff0000000000000",
+		},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+		err := NewEncoder(w, WithFormat(dataset.format)).Encode(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", dataset.data.Type(), err)
+		}
+
+		assert.Equal(t, dataset.expected, hex.EncodeToString(w.Bytes()))
+	}
+
+	arc := &geom.CircularString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{0, 0}, {1, 1}, {2, 0}}}
+	triangle := &geom.Triangle{geom.Hdr{geom.XY, 0}, []geom.LinearRing{{[]geom.Coordinate{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}}}
+
+	for _, g := range []geom.Geometry{
+		arc,
+		&geom.CompoundCurve{geom.Hdr{geom.XY, 0}, []geom.Geometry{arc}},
+		&geom.CurvePolygon{geom.Hdr{geom.XY, 0}, []geom.Geometry{arc}},
+		&geom.MultiCurve{geom.Hdr{geom.XY, 0}, []geom.Geometry{arc}},
+		&geom.MultiSurface{geom.Hdr{geom.XY, 0}, nil},
+		triangle,
+		&geom.PolyhedralSurface{geom.Hdr{geom.XY, 0}, nil},
+		&geom.TIN{geom.Hdr{geom.XY, 0}, nil},
+		&geom.GeometryCollection{geom.Hdr{geom.XY, 0}, []geom.Geometry{arc}},
+	} {
+		err := NewEncoder(new(bytes.Buffer), WithFormat(OGC)).Encode(g)
+		assert.Equal(t, geom.ErrUnsupportedGeom, err, g.Type())
+	}
+}
+
+func TestEncodeCurves(t *testing.T) {
+	arc := []geom.Coordinate{{0, 0}, {1, 1}, {2, 0}}
+	compound := &geom.CompoundCurve{geom.Hdr{geom.XY, 0}, []geom.Geometry{
+		&geom.CircularString{geom.Hdr{geom.XY, 0}, arc},
+		&geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{2, 0}, {0, 0}}},
+	}}
+
+	datasets := []struct {
+		data     geom.Geometry
+		expected string
+	}{
+		{
+			&geom.CircularString{geom.Hdr{geom.XY, 27700}, arc},
+			"0108000020346c00000300000000000000000000000000000000000000000000000000f03f000000000000f03f00000000000000400000000000000000",
+		},
+		{
+			&geom.CircularString{geom.Hdr{geom.XYZ, 0}, []geom.Coordinate{{0, 0, 1}, {1, 1, 2}, {2, 0, 3}}},
+			"01f00300000300000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000f03f0000000000000040000000000000004000000000000000000000000000000840",
+		},
+		{
+			&geom.CompoundCurve{geom.Hdr{geom.XY, 27700}, compound.Curves},
+			"0109000020346c00000200000001080000000300000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000004000000000000000000102000000020000000000000000000040000000000000000000000000000000000000000000000000",
+		},
+		{
+			&geom.CurvePolygon{geom.Hdr{geom.XY, 27700}, []geom.Geometry{
+				compound,
+				&geom.CircularString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{0.5, 0.25}, {1.5, 0.25}, {0.5, 0.25}}},
+			}},
+			"010a000020346c00000200000001090000000200000001080000000300000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000004000000000000000000102000000020000000000000000000040000000000000000000000000000000000000000000000000010800000003000000000000000000e03f000000000000d03f000000000000f83f000000000000d03f000000000000e03f000000000000d03f",
+		},
+		{
+			&geom.MultiCurve{geom.Hdr{geom.XY, 0}, []geom.Geometry{
+				&geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{0, 0}, {1, 1}}},
+				&geom.CircularString{geom.Hdr{geom.XY, 0}, arc},
+			}},
+			"010b0000000200000001020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f01080000000300000000000000000000000000000000000000000000000000f03f000000000000f03f00000000000000400000000000000000",
+		},
+		{
+			&geom.MultiSurface{geom.Hdr{geom.XY, 27700}, []geom.Geometry{
+				&geom.Polygon{geom.Hdr{geom.XY, 0}, []geom.LinearRing{{[]geom.Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+				&geom.CurvePolygon{geom.Hdr{geom.XY, 0}, []geom.Geometry{
+					&geom.CircularString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{4, 0}, {6, 0}, {4, 0}}},
+				}},
+			}},
+			"010c000020346c0000020000000103000000010000000400000000000000000000000000000000000000000000000000f03f0000000000000000000000000000f03f000000000000f03f00000000000000000000000000000000010a00000001000000010800000003000000000000000000104000000000000000000000000000001840000000000000000000000000000010400000000000000000",
+		},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+		err := NewEncoder(w, WithByteOrder(binary.LittleEndian)).Encode(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", dataset.data.Type(), err)
+		}
+
+		assert.Equal(t, dataset.expected, hex.EncodeToString(w.Bytes()))
+	}
+}
+
+func TestEncodeMemberSrid(t *testing.T) {
+	// members carry the SRID of the collection, but only the outermost header writes it
+	mp := &geom.MultiPoint{geom.Hdr{geom.XY, 4326}, []geom.Point{{geom.Hdr{geom.XY, 4326}, geom.Coordinate{1, 2}}}}
+	gc := &geom.GeometryCollection{geom.Hdr{geom.XY, 4326}, []geom.Geometry{
+		&geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{1, 2}},
+	}}
+
+	datasets := []struct {
+		g        geom.Geometry
+		expected string
+	}{
+		// multipoint with SRID 4326, then a point header without the SRID flag or value
+		{mp, "0020000004000010e600000001" + "00000000013ff00000000000004000000000000000"},
+		{gc, "0020000007000010e600000001" + "00000000013ff00000000000004000000000000000"},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+
+		err := Encode(dataset.g, w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", dataset.g.Type(), err)
+		}
+
+		assert.Equal(t, dataset.expected, hex.EncodeToString(w.Bytes()))
+	}
+}
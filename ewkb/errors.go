@@ -0,0 +1,78 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ewkb
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DecodeError describes where decoding failed: the byte offset reached in the input, the path to the geometry
+// element being read, e.g. MultiPolygon[3].Ring[1].Point[17], and the raw type code of the most recent geometry
+// header. The underlying cause is available through errors.Is and errors.As.
+type DecodeError struct {
+	Offset int64
+	Path   string
+	Code   uint32
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	// causes from this package already carry the prefix
+	cause := strings.TrimPrefix(e.Err.Error(), "ewkb: ")
+
+	if e.Path == "" {
+		return fmt.Sprintf("ewkb: %s at offset %d", cause, e.Offset)
+	}
+
+	return fmt.Sprintf("ewkb: %s at offset %d in %s (type 0x%08x)", cause, e.Offset, e.Path, e.Code)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// element is a single step of the path to the geometry element being decoded
+type element struct {
+	name  string
+	index int
+}
+
+// path is the stack of elements leading to the geometry element being decoded
+type path []element
+
+func (p path) String() string {
+	var sb bytes.Buffer
+
+	for idx, e := range p {
+		if idx > 0 {
+			sb.WriteByte('.')
+		}
+
+		sb.WriteString(e.name)
+
+		if e.index >= 0 {
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(e.index))
+			sb.WriteByte(']')
+		}
+	}
+
+	return sb.String()
+}
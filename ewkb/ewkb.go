@@ -16,7 +16,37 @@ limitations under the License.
 
 package ewkb
 
-import "github.com/devork/geom"
+import (
+	"errors"
+
+	"github.com/devork/geom"
+)
+
+// Common error types
+var (
+	ErrFormat        = errors.New("geometry does not match the required format")
+	ErrCount         = errors.New("element count exceeds the remaining input")
+	ErrTooManyCoords = errors.New("geometry exceeds the maximum number of coordinates")
+	ErrTooDeep       = errors.New("geometry exceeds the maximum nesting depth")
+	ErrTooLarge      = errors.New("geometry exceeds the maximum size")
+)
+
+// Format is the binary dialect written by an Encoder or required by a Decoder
+type Format uint8
+
+const (
+	// Any writes Postgis EWKB for geometries with an SRID and ISO WKB otherwise, and accepts every format when
+	// decoding. This is the default.
+	Any Format = iota
+	// EWKB is the Postgis extended format, marking Z, M and the SRID with the high bits of the type.
+	EWKB
+	// ISO is the ISO/OGC SQL/MM format, using the +1000, +2000 and +3000 type codes for Z, M and ZM. The SRID is
+	// never written.
+	ISO
+	// OGC is the original 2D only OGC format. Z and M values are dropped when writing, and curves, triangles, TINs
+	// and polyhedral surfaces, which it has no type codes for, fail with geom.ErrUnsupportedGeom.
+	OGC
+)
 
 // https://trac.osgeo.org/postgis/browser/trunk/doc/ZMSgeoms.txt
 //
@@ -121,31 +151,69 @@ func (g geomtype) String() string {
 		return "MULTIPOLYGON"
 	case geometrycollection:
 		return "GEOMETRYCOLLECTION"
-	// case CIRCULARSTRING:
-	// 	return "CIRCULARSTRING"
-	// case COMPOUNDCURVE:
-	// 	return "COMPOUNDCURVE"
-	// case CURVEPOLYGON:
-	// 	return "CURVEPOLYGON"
-	// case MULTICURVE:
-	// 	return "MULTICURVE"
-	// case MULTISURFACE:
-	// 	return "MULTISURFACE"
+	case circularstring:
+		return "CIRCULARSTRING"
+	case compoundcurve:
+		return "COMPOUNDCURVE"
+	case curvepolygon:
+		return "CURVEPOLYGON"
+	case multicurve:
+		return "MULTICURVE"
+	case multisurface:
+		return "MULTISURFACE"
 	// case CURVE:
 	// 	return "CURVE"
 	// case SURFACE:
 	// 	return "SURFACE"
-	// case POLYHEDRALSURFACE:
-	// 	return "POLYHEDRALSURFACE "
-	// case TIN:
-	// 	return "TIN"
-	// case TRIANGLE:
-	// 	return "TRIANGLE"
+	case polyhedralsurface:
+		return "POLYHEDRALSURFACE"
+	case tin:
+		return "TIN"
+	case triangle:
+		return "TRIANGLE"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// name returns the type name used in DecodeError paths
+func (g geomtype) name() string {
+	switch g {
+	case point:
+		return "Point"
+	case linestring:
+		return "LineString"
+	case polygon:
+		return "Polygon"
+	case multipoint:
+		return "MultiPoint"
+	case multilinestring:
+		return "MultiLineString"
+	case multipolygon:
+		return "MultiPolygon"
+	case geometrycollection:
+		return "GeometryCollection"
+	case circularstring:
+		return "CircularString"
+	case compoundcurve:
+		return "CompoundCurve"
+	case curvepolygon:
+		return "CurvePolygon"
+	case multicurve:
+		return "MultiCurve"
+	case multisurface:
+		return "MultiSurface"
+	case polyhedralsurface:
+		return "PolyhedralSurface"
+	case tin:
+		return "TIN"
+	case triangle:
+		return "Triangle"
+	default:
+		return "Geometry"
+	}
+}
+
 // WKB extensions for Z, M, and ZM. these extensions are applied to the base geometry types,
 // such that a ZM version of a Point = 17 + 3000 = 3017 (0xBC9)
 const (
@@ -156,5 +224,6 @@ const (
 
 // Big or Little endian identifiers
 const (
-	bigEndian uint8 = 0x00
+	bigEndian    uint8 = 0x00
+	littleEndian uint8 = 0x01
 )
@@ -0,0 +1,60 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ewkb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func FuzzDecode(f *testing.F) {
+	seeds := []string{
+		"002000000700006c340000000200000000014010000000000000401800000000000000000000020000000240100000000000004018000000000000401c0000000000004024000000000000",
+		"0107000020346c000002000000010100000000000000000010400000000000001840010200000002000000000000000000104000000000000018400000000000001c400000000000002440",
+		"002000000300006c340000000100000005403e0000000000004024000000000000404400000000000040440000000000004034000000000000404400000000000040240000000000004034000000000000403e0000000000004024000000000000",
+		"002000000400006c340000000400000000014024000000000000404400000000000000000000014044000000000000403e0000000000000000000001403400000000000040340000000000000000000001403e0000000000004024000000000000",
+		"00e000000100006c343ff00000000000003ff00000000000003ff00000000000003ff0000000000000",
+		"0000000002ffffffff",
+	}
+
+	for _, seed := range seeds {
+		data, err := hex.DecodeString(seed)
+
+		if err != nil {
+			f.Fatal("Failed to decode HEX string: err = ", err)
+		}
+
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, err := Decode(bytes.NewReader(data))
+
+		if err != nil {
+			return
+		}
+
+		// anything that decodes must encode again
+		var w bytes.Buffer
+		err = Encode(g, &w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode decoded %s geometry: err = %s", g.Type(), err)
+		}
+	})
+}
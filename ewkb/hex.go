@@ -0,0 +1,175 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ewkb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/devork/geom"
+)
+
+// upper case hex digits, as written by Postgres for geometry columns
+const hexDigits = "0123456789ABCDEF"
+
+// HexError reports an invalid character in hex encoded input, with its byte offset from the start of the input
+type HexError struct {
+	Offset int64
+	Char   byte
+}
+
+func (e *HexError) Error() string {
+	return fmt.Sprintf("ewkb: invalid hex character %q at offset %d", e.Char, e.Offset)
+}
+
+// DecodeHex converts the hex encoded WKB or EWKB in s to a geom type. Upper and lower case digits are accepted, as is
+// the \x prefix of a Postgres bytea.
+func DecodeHex(s string, opts ...DecoderOption) (geom.Geometry, error) {
+	return NewDecoder(NewHexReader(strings.NewReader(s)), opts...).Decode()
+}
+
+// EncodeHex converts the geometry to upper case hex encoded EWKB, as returned by Postgres for geometry columns
+func EncodeHex(g geom.Geometry, opts ...EncoderOption) (string, error) {
+	var sb bytes.Buffer
+
+	err := NewEncoder(NewHexWriter(&sb), opts...).Encode(g)
+
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+type hexReader struct {
+	src     io.Reader
+	r       *bufio.Reader
+	offset  int64
+	started bool
+}
+
+// NewHexReader returns a reader that decodes the hex encoded data read from r. An optional \x prefix is skipped, an
+// invalid character is reported as a *HexError and an odd number of digits as io.ErrUnexpectedEOF.
+func NewHexReader(r io.Reader) io.Reader {
+	return &hexReader{src: r, r: bufio.NewReader(r)}
+}
+
+// Len returns the number of decoded bytes remaining when the length of the underlying reader is known, allowing the
+// decoder to reject element counts that exceed the input. It returns -1 otherwise.
+func (h *hexReader) Len() int {
+	l, ok := h.src.(interface{ Len() int })
+
+	if !ok {
+		return -1
+	}
+
+	n := l.Len() + h.r.Buffered()
+
+	if !h.started && n >= 2 {
+		if prefix, _ := h.r.Peek(2); string(prefix) == `\x` || string(prefix) == `\X` {
+			n -= 2
+		}
+	}
+
+	return n / 2
+}
+
+func (h *hexReader) Read(p []byte) (int, error) {
+	if !h.started {
+		h.started = true
+
+		prefix, _ := h.r.Peek(2)
+
+		if len(prefix) == 2 && prefix[0] == '\\' && (prefix[1] == 'x' || prefix[1] == 'X') {
+			h.r.Discard(2)
+			h.offset += 2
+		}
+	}
+
+	for n := range p {
+		hi, err := h.nibble()
+
+		if err == io.EOF && n > 0 {
+			return n, nil
+		}
+
+		if err != nil {
+			return n, err
+		}
+
+		lo, err := h.nibble()
+
+		if err == io.EOF {
+			return n, io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return n, err
+		}
+
+		p[n] = hi<<4 | lo
+	}
+
+	return len(p), nil
+}
+
+func (h *hexReader) nibble() (byte, error) {
+	c, err := h.r.ReadByte()
+
+	if err != nil {
+		return 0, err
+	}
+
+	h.offset++
+
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', nil
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, nil
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, nil
+	default:
+		return 0, &HexError{Offset: h.offset - 1, Char: c}
+	}
+}
+
+type hexWriter struct {
+	w io.Writer
+}
+
+// NewHexWriter returns a writer that writes the upper case hex encoding of the data to w
+func NewHexWriter(w io.Writer) io.Writer {
+	return &hexWriter{w: w}
+}
+
+func (h *hexWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, hex.EncodedLen(len(p)))
+
+	for idx, b := range p {
+		buf[idx*2] = hexDigits[b>>4]
+		buf[idx*2+1] = hexDigits[b&0x0f]
+	}
+
+	n, err := h.w.Write(buf)
+
+	return n / 2, err
+}
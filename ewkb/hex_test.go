@@ -0,0 +1,121 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ewkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeHex(t *testing.T) {
+	datasets := []string{
+		"0102000020346c0000030000000000000000003e40000000000000244000000000000024400000000000003e4000000000000044400000000000004440",
+		"0102000020346C0000030000000000000000003E40000000000000244000000000000024400000000000003E4000000000000044400000000000004440",
+		`\x0102000020346c0000030000000000000000003e40000000000000244000000000000024400000000000003e4000000000000044400000000000004440`,
+		"002000000200006c3400000003403e00000000000040240000000000004024000000000000403e00000000000040440000000000004044000000000000",
+	}
+
+	for _, dataset := range datasets {
+		g, err := DecodeHex(dataset)
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset, err)
+		}
+
+		assert.Equal(t, &geom.LineString{geom.Hdr{geom.XY, 27700}, []geom.Coordinate{{30, 10}, {10, 30}, {40, 40}}}, g)
+	}
+}
+
+func TestDecodeHexErrors(t *testing.T) {
+	datasets := []struct {
+		data     string
+		expected error
+	}{
+		{"0101000000000000000000f03f000000000000f03g", &HexError{Offset: 41, Char: 'g'}},
+		{`\x01010000 000000000000f03f000000000000f03f`, &HexError{Offset: 10, Char: ' '}},
+		{"0101000000000000000000f03f000000000000f03", io.ErrUnexpectedEOF},
+		{"0101000000000000000000f03f", io.ErrUnexpectedEOF},
+		{"", io.EOF},
+	}
+
+	for _, dataset := range datasets {
+		_, err := DecodeHex(dataset.data)
+
+		var herr *HexError
+		if errors.As(dataset.expected, &herr) {
+			var actual *HexError
+			assert.True(t, errors.As(err, &actual), "Decoding %q", dataset.data)
+			assert.Equal(t, herr, actual, "Decoding %q", dataset.data)
+			continue
+		}
+
+		assert.ErrorIs(t, err, dataset.expected, "Decoding %q", dataset.data)
+	}
+
+	var herr *HexError
+	_, err := DecodeHex("zz")
+
+	assert.True(t, errors.As(err, &herr))
+	assert.Equal(t, `ewkb: invalid hex character 'z' at offset 0`, herr.Error())
+}
+
+func TestEncodeHex(t *testing.T) {
+	g := &geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{1, 1}}
+
+	data, err := EncodeHex(g)
+
+	if err != nil {
+		t.Fatalf("Failed to encode Point geometry: err = %s", err)
+	}
+
+	assert.Equal(t, "00000000013FF00000000000003FF0000000000000", data)
+
+	data, err = EncodeHex(g, WithByteOrder(binary.LittleEndian))
+
+	if err != nil {
+		t.Fatalf("Failed to encode Point geometry: err = %s", err)
+	}
+
+	assert.Equal(t, "0101000000000000000000F03F000000000000F03F", data)
+
+	_, err = EncodeHex(nil)
+	assert.Equal(t, geom.ErrNoGeometry, err)
+}
+
+func TestHexReaderWriter(t *testing.T) {
+	var sb bytes.Buffer
+
+	w := NewHexWriter(&sb)
+	n, err := w.Write([]byte{0x00, 0xab, 0xff})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "00ABFF", sb.String())
+
+	r := NewHexReader(strings.NewReader(`\x00abFF`))
+	data, err := io.ReadAll(r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0xab, 0xff}, data)
+}
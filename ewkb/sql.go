@@ -0,0 +1,228 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ewkb
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/devork/geom"
+)
+
+// Geom wraps a geometry so it can be scanned from, and written to, a geometry column with database/sql. Both raw
+// EWKB and the hex encoded text returned by Postgres are accepted. A NULL column scans to a nil Geometry and a nil
+// Geometry is written as NULL.
+type Geom struct {
+	geom.Geometry
+}
+
+// Scan implements the sql.Scanner interface
+func (g *Geom) Scan(src interface{}) error {
+	v, err := scan(src)
+
+	if err != nil {
+		return err
+	}
+
+	g.Geometry = v
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface
+func (g Geom) Value() (driver.Value, error) {
+	return value(g.Geometry)
+}
+
+// PointValue wraps a *geom.Point for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type PointValue struct {
+	*geom.Point
+}
+
+// Scan implements the sql.Scanner interface
+func (v *PointValue) Scan(src interface{}) error {
+	return scanAs(src, &v.Point, "PointValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v PointValue) Value() (driver.Value, error) {
+	return value(v.Point)
+}
+
+// LineStringValue wraps a *geom.LineString for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type LineStringValue struct {
+	*geom.LineString
+}
+
+// Scan implements the sql.Scanner interface
+func (v *LineStringValue) Scan(src interface{}) error {
+	return scanAs(src, &v.LineString, "LineStringValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v LineStringValue) Value() (driver.Value, error) {
+	return value(v.LineString)
+}
+
+// PolygonValue wraps a *geom.Polygon for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type PolygonValue struct {
+	*geom.Polygon
+}
+
+// Scan implements the sql.Scanner interface
+func (v *PolygonValue) Scan(src interface{}) error {
+	return scanAs(src, &v.Polygon, "PolygonValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v PolygonValue) Value() (driver.Value, error) {
+	return value(v.Polygon)
+}
+
+// MultiPointValue wraps a *geom.MultiPoint for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type MultiPointValue struct {
+	*geom.MultiPoint
+}
+
+// Scan implements the sql.Scanner interface
+func (v *MultiPointValue) Scan(src interface{}) error {
+	return scanAs(src, &v.MultiPoint, "MultiPointValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v MultiPointValue) Value() (driver.Value, error) {
+	return value(v.MultiPoint)
+}
+
+// MultiLineStringValue wraps a *geom.MultiLineString for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type MultiLineStringValue struct {
+	*geom.MultiLineString
+}
+
+// Scan implements the sql.Scanner interface
+func (v *MultiLineStringValue) Scan(src interface{}) error {
+	return scanAs(src, &v.MultiLineString, "MultiLineStringValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v MultiLineStringValue) Value() (driver.Value, error) {
+	return value(v.MultiLineString)
+}
+
+// MultiPolygonValue wraps a *geom.MultiPolygon for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type MultiPolygonValue struct {
+	*geom.MultiPolygon
+}
+
+// Scan implements the sql.Scanner interface
+func (v *MultiPolygonValue) Scan(src interface{}) error {
+	return scanAs(src, &v.MultiPolygon, "MultiPolygonValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v MultiPolygonValue) Value() (driver.Value, error) {
+	return value(v.MultiPolygon)
+}
+
+// GeometryCollectionValue wraps a *geom.GeometryCollection for use with database/sql, see Geom. Scanning any other geometry type is an error.
+type GeometryCollectionValue struct {
+	*geom.GeometryCollection
+}
+
+// Scan implements the sql.Scanner interface
+func (v *GeometryCollectionValue) Scan(src interface{}) error {
+	return scanAs(src, &v.GeometryCollection, "GeometryCollectionValue")
+}
+
+// Value implements the driver.Valuer interface
+func (v GeometryCollectionValue) Value() (driver.Value, error) {
+	return value(v.GeometryCollection)
+}
+
+// scan decodes a column value, distinguishing raw EWKB from hex text by the leading byte order flag
+func scan(src interface{}) (geom.Geometry, error) {
+	switch src := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		if len(src) > 0 && (src[0] == bigEndian || src[0] == littleEndian) {
+			return Decode(bytes.NewReader(src))
+		}
+
+		return Decode(NewHexReader(bytes.NewReader(src)))
+	case string:
+		return DecodeHex(src)
+	default:
+		return nil, fmt.Errorf("ewkb: cannot scan %T into a geometry", src)
+	}
+}
+
+// scanAs scans src into field, a pointer to the geometry field of the named typed wrapper, failing when the column
+// holds a geometry of another type
+func scanAs(src interface{}, field interface{}, name string) error {
+	g, err := scan(src)
+
+	if err != nil {
+		return err
+	}
+
+	f := reflect.ValueOf(field).Elem()
+
+	if g == nil {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(g)
+
+	if v.Type() != f.Type() {
+		return fmt.Errorf("ewkb: cannot scan %s into %s", g.Type(), name)
+	}
+
+	f.Set(v)
+
+	return nil
+}
+
+// value encodes g as EWKB, writing a nil geometry, including a typed nil such as (*geom.Point)(nil), as NULL
+func value(g geom.Geometry) (driver.Value, error) {
+	if isNil(g) {
+		return nil, nil
+	}
+
+	var sb bytes.Buffer
+
+	err := Encode(g, &sb)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sb.Bytes(), nil
+}
+
+func isNil(g geom.Geometry) bool {
+	if g == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(g)
+
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
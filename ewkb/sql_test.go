@@ -0,0 +1,205 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ewkb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver stands in for a Postgres driver: queries return the rows registered against the query text and
+// executed statements record their arguments
+type fakeDriver struct {
+	rows map[string][]driver.Value
+	args []driver.Value
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+type fakeRows struct {
+	values []driver.Value
+	idx    int
+}
+
+var fake = &fakeDriver{rows: map[string][]driver.Value{}}
+
+func init() {
+	sql.Register("ewkbfake", fake)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.args = args
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{values: s.d.rows[s.query]}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"geom"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.values) {
+		return io.EOF
+	}
+
+	dest[0] = r.values[r.idx]
+	r.idx++
+
+	return nil
+}
+
+const (
+	ndrPoint = "0101000020E6100000000000000000F03F0000000000000040"
+	xdrPoint = "0020000001000010e63ff00000000000004000000000000000"
+)
+
+func TestScanGeom(t *testing.T) {
+	raw, err := DecodeHex(xdrPoint)
+
+	if err != nil {
+		t.Fatalf("Failed to decode point: err = %s", err)
+	}
+
+	fake.rows["select geom"] = []driver.Value{
+		[]byte(ndrPoint),
+		ndrPoint,
+		mustBytes(t, raw),
+		nil,
+	}
+
+	db, err := sql.Open("ewkbfake", "")
+
+	if err != nil {
+		t.Fatalf("Failed to open database: err = %s", err)
+	}
+
+	defer db.Close()
+
+	rows, err := db.Query("select geom")
+
+	if err != nil {
+		t.Fatalf("Failed to query: err = %s", err)
+	}
+
+	defer rows.Close()
+
+	expected := &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{1, 2}}
+
+	var got []geom.Geometry
+	for rows.Next() {
+		var g Geom
+
+		err = rows.Scan(&g)
+
+		if err != nil {
+			t.Fatalf("Failed to scan: err = %s", err)
+		}
+
+		got = append(got, g.Geometry)
+	}
+
+	assert.Equal(t, []geom.Geometry{expected, expected, expected, nil}, got)
+}
+
+func TestScanTyped(t *testing.T) {
+	var p PointValue
+
+	assert.Nil(t, p.Scan(ndrPoint))
+	assert.Equal(t, geom.Coordinate{1, 2}, p.Coordinate)
+	assert.Equal(t, uint32(4326), p.SRID())
+
+	assert.Nil(t, p.Scan(nil))
+	assert.Nil(t, p.Point)
+
+	var l LineStringValue
+	assert.EqualError(t, l.Scan(ndrPoint), "ewkb: cannot scan point into LineStringValue")
+	assert.Nil(t, l.LineString)
+	assert.EqualError(t, l.Scan(12), "ewkb: cannot scan int into a geometry")
+
+	var g Geom
+	assert.Error(t, g.Scan("01zz"))
+}
+
+func TestValue(t *testing.T) {
+	db, err := sql.Open("ewkbfake", "")
+
+	if err != nil {
+		t.Fatalf("Failed to open database: err = %s", err)
+	}
+
+	defer db.Close()
+
+	point := &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{1, 2}}
+
+	datasets := []struct {
+		arg      interface{}
+		expected driver.Value
+	}{
+		{Geom{point}, mustBytes(t, point)},
+		{PointValue{point}, mustBytes(t, point)},
+		{Geom{}, nil},
+		{Geom{(*geom.Point)(nil)}, nil},
+		{Geom{(*geom.GeometryCollection)(nil)}, nil},
+		{PolygonValue{}, nil},
+		{MultiPointValue{}, nil},
+		{GeometryCollectionValue{}, nil},
+	}
+
+	for _, dataset := range datasets {
+		_, err = db.Exec("insert", dataset.arg)
+
+		if err != nil {
+			t.Fatalf("Failed to exec: err = %s", err)
+		}
+
+		assert.Equal(t, []driver.Value{dataset.expected}, fake.args)
+	}
+}
+
+func mustBytes(t *testing.T, g geom.Geometry) []byte {
+	v, err := value(g)
+
+	if err != nil {
+		t.Fatalf("Failed to encode %s: err = %s", g.Type(), err)
+	}
+
+	return v.([]byte)
+}
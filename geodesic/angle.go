@@ -0,0 +1,170 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import "math"
+
+const degree = math.Pi / 180
+
+// Tolerances of the iterative solution, derived from the machine epsilon
+var (
+	tol0    = math.Nextafter(1, 2) - 1
+	tol1    = 200 * tol0
+	tol2    = math.Sqrt(tol0)
+	tolb    = tol0 * tol2
+	xthresh = 1000 * tol2
+	tiny    = math.Sqrt(math.SmallestNonzeroFloat64)
+)
+
+const (
+	maxit1 = 20
+	maxit2 = maxit1 + 53 + 10
+)
+
+func sq(x float64) float64 {
+	return x * x
+}
+
+// hypot is deliberately not math.Hypot: the results must match the reference implementation to round off
+func hypot(x, y float64) float64 {
+	return math.Sqrt(x*x + y*y)
+}
+
+// norm scales the sine and cosine of an angle so that s^2 + c^2 = 1
+func norm(s, c float64) (float64, float64) {
+	r := hypot(s, c)
+	return s / r, c / r
+}
+
+// sum returns the rounded sum of u and v and the error t, so that u + v = s + t exactly
+func sum(u, v float64) (s, t float64) {
+	s = u + v
+	up := s - v
+	vpp := s - up
+	up -= u
+	vpp -= v
+	t = -(up + vpp)
+
+	return s, t
+}
+
+// angNormalize reduces an angle in degrees to (-180, 180]
+func angNormalize(x float64) float64 {
+	x = math.Remainder(x, 360)
+	if x == -180 {
+		return 180
+	}
+
+	return x
+}
+
+// angDiff returns y - x in degrees, reduced to (-180, 180], and the error e of the exact difference d + e
+func angDiff(x, y float64) (d, e float64) {
+	d, t := sum(angNormalize(-x), angNormalize(y))
+	d = angNormalize(d)
+
+	if d == 180 && t > 0 {
+		d = -180
+	}
+
+	return sum(d, t)
+}
+
+// angRound coarsens tiny angles so that values close to zero become exactly representable, which stops underflow in
+// the spherical trigonometry
+func angRound(x float64) float64 {
+	const z = 1.0 / 16
+
+	if x == 0 {
+		return 0
+	}
+
+	y := math.Abs(x)
+	if y < z {
+		y = z - (z - y)
+	}
+
+	return math.Copysign(y, x)
+}
+
+// latFix returns NaN for latitudes outside [-90, 90]
+func latFix(x float64) float64 {
+	if math.Abs(x) > 90 {
+		return math.NaN()
+	}
+
+	return x
+}
+
+// sincosd returns the sine and cosine of x in degrees, reducing the argument exactly to [-45, 45] first
+func sincosd(x float64) (sinx, cosx float64) {
+	r := math.Remainder(x, 90)
+	q := int(math.Round((x - r) / 90))
+
+	s, c := math.Sincos(r * degree)
+
+	switch q & 3 {
+	case 0:
+		sinx, cosx = s, c
+	case 1:
+		sinx, cosx = c, -s
+	case 2:
+		sinx, cosx = -s, -c
+	default:
+		sinx, cosx = -c, s
+	}
+
+	if x != 0 {
+		// convert -0 to +0
+		sinx += 0
+		cosx += 0
+	}
+
+	return sinx, cosx
+}
+
+// atan2d returns atan2(y, x) in degrees, reducing the arguments so the result of math.Atan2 is in [-45, 45]
+func atan2d(y, x float64) float64 {
+	q := 0
+
+	if math.Abs(y) > math.Abs(x) {
+		x, y = y, x
+		q = 2
+	}
+
+	if x < 0 {
+		x = -x
+		q++
+	}
+
+	ang := math.Atan2(y, x) / degree
+
+	switch q {
+	case 1:
+		if y >= 0 {
+			ang = 180 - ang
+		} else {
+			ang = -180 - ang
+		}
+	case 2:
+		ang = 90 - ang
+	case 3:
+		ang = -90 + ang
+	}
+
+	return ang
+}
@@ -0,0 +1,113 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import "math"
+
+// Direct solves the direct geodesic problem. Starting from the point lat1, lon1 in degrees and travelling s12 metres
+// along the geodesic with the azimuth azi1, in degrees clockwise from north, it returns the end point and the forward
+// azimuth azi2 there. The longitude is reduced to [-180, 180].
+func (e *Ellipsoid) Direct(lat1, lon1, azi1, s12 float64) (lat2, lon2, azi2 float64) {
+	// guard against underflow in salp0
+	salp1, calp1 := sincosd(angRound(angNormalize(azi1)))
+
+	sbet1, cbet1 := sincosd(angRound(latFix(lat1)))
+	sbet1, cbet1 = norm(sbet1*e.f1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+
+	// sin(alp1) * cos(bet1) = sin(alp0), with alp0 in [0, pi/2 - |bet1|]
+	salp0 := salp1 * cbet1
+	calp0 := hypot(calp1, salp1*sbet1)
+
+	// tan(bet1) = tan(sig1) * cos(alp1) and tan(omg1) = sin(alp0) * tan(sig1), where sig = 0 is the nearest
+	// northward crossing of the equator
+	somg1 := salp0 * sbet1
+	comg1 := 1.0
+
+	if sbet1 != 0 || calp1 != 0 {
+		comg1 = cbet1 * calp1
+	}
+
+	ssig1, csig1 := norm(sbet1, comg1)
+
+	k2 := sq(calp0) * e.ep2
+	eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+
+	var c1a, c1pa [nC]float64
+	var c3a [order]float64
+
+	a1m1 := a1m1f(eps)
+	c1f(eps, c1a[:])
+	c1pf(eps, c1pa[:])
+	e.c3f(eps, c3a[:])
+
+	b11 := sinCosSeries(true, ssig1, csig1, c1a[:], order)
+	b31 := sinCosSeries(true, ssig1, csig1, c3a[:], order-1)
+	a3c := -e.f * salp0 * e.a3f(eps)
+
+	// tau1 = sig1 + B11
+	s, c := math.Sincos(b11)
+	stau1 := ssig1*c + csig1*s
+	ctau1 := csig1*c - ssig1*s
+
+	// convert the distance to an arc length with the reverted series, tau2 = tau1 + tau12
+	tau12 := s12 / (e.b * (1 + a1m1))
+	s, c = math.Sincos(tau12)
+
+	b12 := -sinCosSeries(true, stau1*c+ctau1*s, ctau1*c-stau1*s, c1pa[:], order)
+	sig12 := tau12 - (b12 - b11)
+	ssig12, csig12 := math.Sincos(sig12)
+
+	if math.Abs(e.f) > 0.01 {
+		// the reverted series is inaccurate for |f| > 1/100, so correct sig12 with one Newton iteration
+		ssig2 := ssig1*csig12 + csig1*ssig12
+		csig2 := csig1*csig12 - ssig1*ssig12
+		b12 = sinCosSeries(true, ssig2, csig2, c1a[:], order)
+		serr := (1+a1m1)*(sig12+(b12-b11)) - s12/e.b
+		sig12 -= serr / math.Sqrt(1+k2*sq(ssig2))
+		ssig12, csig12 = math.Sincos(sig12)
+	}
+
+	// sig2 = sig1 + sig12
+	ssig2 := ssig1*csig12 + csig1*ssig12
+	csig2 := csig1*csig12 - ssig1*ssig12
+
+	// sin(bet2) = cos(alp0) * sin(sig2)
+	sbet2 := calp0 * ssig2
+	cbet2 := hypot(salp0, calp0*csig2)
+
+	if cbet2 == 0 {
+		// salp0 = 0 and csig2 = 0, break the degeneracy
+		csig2 = tiny
+		cbet2 = tiny
+	}
+
+	// tan(alp0) = cos(sig2) * tan(alp2) and tan(omg2) = sin(alp0) * tan(sig2)
+	salp2 := salp0
+	calp2 := calp0 * csig2
+	somg2 := salp0 * ssig2
+	comg2 := csig2
+
+	omg12 := math.Atan2(somg2*comg1-comg2*somg1, comg2*comg1+somg2*somg1)
+	lam12 := omg12 + a3c*(sig12+(sinCosSeries(true, ssig2, csig2, c3a[:], order-1)-b31))
+
+	lat2 = atan2d(sbet2, e.f1*cbet2)
+	lon2 = angNormalize(angNormalize(lon1) + angNormalize(lam12/degree))
+	azi2 = atan2d(salp2, calp2)
+
+	return lat2, lon2, azi2
+}
@@ -0,0 +1,27 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package geodesic measures geometries on the surface of an ellipsoid. Coordinates are read as longitude (X) and
+latitude (Y) in degrees, as used by SRID 4326, and results are in metres, square metres and degrees.
+
+The direct and inverse geodesic problems are solved with the series expansions from C. F. F. Karney, "Algorithms for
+geodesics", J. Geodesy 87, 43-55 (2013), https://doi.org/10.1007/s00190-012-0578-z, which are accurate to round off
+for the terrestrial ellipsoids. Polygon areas follow the same paper and are exact for polygons with geodesic edges.
+
+The package level functions use WGS84. Other ellipsoids are selected by calling the methods of an Ellipsoid instead.
+*/
+package geodesic
@@ -0,0 +1,89 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import "math"
+
+// Well known ellipsoids
+var (
+	// WGS84 is the World Geodetic System 1984 ellipsoid used by GPS and SRID 4326
+	WGS84 = NewEllipsoid(6378137, 1/298.257223563)
+	// GRS80 is the Geodetic Reference System 1980 ellipsoid used by NAD83 and ETRS89
+	GRS80 = NewEllipsoid(6378137, 1/298.257222101)
+)
+
+// Ellipsoid is an ellipsoid of revolution on which geodesic problems are solved. It is safe for concurrent use.
+type Ellipsoid struct {
+	a, f float64
+
+	// derived constants, see Karney (2013) section 2
+	f1, e2, ep2, n, b, c2, etol2 float64
+
+	a3x [nA3x]float64
+	c3x [nC3x]float64
+	c4x [nC4x]float64
+}
+
+// NewEllipsoid returns the ellipsoid with the equatorial radius a in metres and the flattening f. A flattening of 0
+// gives a sphere and a negative flattening a prolate ellipsoid.
+func NewEllipsoid(a, f float64) *Ellipsoid {
+	e := &Ellipsoid{a: a, f: f}
+
+	e.f1 = 1 - f
+	e.e2 = f * (2 - f)
+	e.ep2 = e.e2 / sq(e.f1)
+	e.n = f / (2 - f)
+	e.b = a * e.f1
+
+	// authalic radius squared, the radius of the sphere with the same surface area
+	var v float64
+
+	switch {
+	case e.e2 == 0:
+		v = 1
+	case e.e2 > 0:
+		v = math.Atanh(math.Sqrt(e.e2)) / math.Sqrt(e.e2)
+	default:
+		v = math.Atan(math.Sqrt(-e.e2)) / math.Sqrt(-e.e2)
+	}
+
+	e.c2 = (sq(a) + sq(e.b)*v) / 2
+
+	// the arc length below which the spherical approximation is accurate to round off
+	e.etol2 = 0.1 * tol2 / math.Sqrt(math.Max(0.001, math.Abs(f))*math.Min(1, 1-f/2)/2)
+
+	e.a3coeff()
+	e.c3coeff()
+	e.c4coeff()
+
+	return e
+}
+
+// EquatorialRadius returns the semi-major axis of the ellipsoid in metres
+func (e *Ellipsoid) EquatorialRadius() float64 {
+	return e.a
+}
+
+// Flattening returns the flattening of the ellipsoid
+func (e *Ellipsoid) Flattening() float64 {
+	return e.f
+}
+
+// SurfaceArea returns the total area of the ellipsoid in square metres
+func (e *Ellipsoid) SurfaceArea() float64 {
+	return 4 * math.Pi * e.c2
+}
@@ -0,0 +1,128 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Expected values are from the GeographicLib test suite, C. F. F. Karney, https://geographiclib.sourceforge.io
+
+func TestInverse(t *testing.T) {
+	datasets := []struct {
+		name                   string
+		ellipsoid              *Ellipsoid
+		lat1, lon1, lat2, lon2 float64
+		s12, azi1, azi2        float64
+		tolerance              float64
+	}{
+		{"wellington to salamanca", WGS84, -41.32, 174.81, 40.96, -5.50, 19959679.267354, 161.06766998616, 18.82519512325, 1e-6},
+		{"jfk to cdg", WGS84, 40.6, -73.8, 49.01666667, 2.55, 5853226.255613, 53.47021824, 111.59366951, 1e-6},
+		{"short line", WGS84, 36.493349428792, 0, 36.49334942879201, .0000008, 0.072, 90, 90, 1e-3},
+		{"nearly antipodal 1", WGS84, 88.202499451857, 0, -88.202499451857, 179.981022032992859592, 20003898.214, math.NaN(), math.NaN(), 1e-3},
+		{"nearly antipodal 2", WGS84, 89.333123580033, 0, -89.333123580032997687, 179.99295812360148422, 20003926.881, math.NaN(), math.NaN(), 1e-3},
+		{"nearly antipodal 3", WGS84, 56.320923501171, 0, -56.320923501171, 179.664747671772880215, 19993558.287, math.NaN(), math.NaN(), 1e-3},
+		{"nearly antipodal 4", WGS84, 48.522876735459, 0, -48.52287673545898293, 179.599720456223079643, 19989144.774, math.NaN(), math.NaN(), 1e-3},
+		{"equator", WGS84, 0, 0, 0, 179, 19926188.852, 90, 90, 1e-3},
+		{"equator near antipode", WGS84, 0, 0, 0, 179.5, 19980862.0, 55.96650, 124.03350, 1},
+		{"equatorial antipodes", WGS84, 0, 0, 0, 180, 20003931.0, 0, 180, 1},
+		{"meridian", WGS84, 0, 0, 1, 180, 19893357.0, 0, 180, 1},
+		{"wrapped longitudes", WGS84, 0, 539, 0, 181, 222638.981587, 90, 90, 1e-6},
+		{"sphere", NewEllipsoid(6.4e6, 0), 0, 0, 0, 179, 19994492.0, 90, 90, 1},
+		{"sphere antipodes", NewEllipsoid(6.4e6, 0), 0, 0, 0, 180, 20106193.0, 0, 180, 1},
+		{"prolate antipodes", NewEllipsoid(6.4e6, -1/300.0), 0, 0, 0, 180, 20106193.0, 90, 90, 1},
+		{"prolate near antipode", NewEllipsoid(6.4e6, -1/300.0), 0, 0, 0.5, 180, 20082617.0, 33.02493, 146.97364, 1},
+		{"prolate meridian", NewEllipsoid(6.4e6, -1/150.0), 0.07476, 0, -0.07476, 180, 20106193.0, 90.00078, 90.00078, 1},
+	}
+
+	for _, dataset := range datasets {
+		s12, azi1, azi2 := dataset.ellipsoid.Inverse(dataset.lat1, dataset.lon1, dataset.lat2, dataset.lon2)
+
+		assert.InDelta(t, dataset.s12, s12, dataset.tolerance, dataset.name)
+
+		if !math.IsNaN(dataset.azi1) {
+			assert.InDelta(t, dataset.azi1, azi1, 1e-5, dataset.name)
+			assert.InDelta(t, dataset.azi2, azi2, 1e-5, dataset.name)
+		}
+	}
+}
+
+func TestInverseNaN(t *testing.T) {
+	s12, azi1, azi2 := WGS84.Inverse(0, 0, 1, math.NaN())
+	assert.True(t, math.IsNaN(s12))
+	assert.True(t, math.IsNaN(azi1))
+	assert.True(t, math.IsNaN(azi2))
+
+	s12, _, _ = WGS84.Inverse(91, 0, 0, 0)
+	assert.True(t, math.IsNaN(s12))
+}
+
+func TestDirect(t *testing.T) {
+	datasets := []struct {
+		name             string
+		ellipsoid        *Ellipsoid
+		lat1, lon1, azi1 float64
+		s12              float64
+		lat2, lon2, azi2 float64
+	}{
+		{"jfk", WGS84, 40.63972222, -73.77888889, 53.5, 5850e3, 49.01466893, 2.56106226, 111.62946705},
+		{"wellington", WGS84, -41.32, 174.81, 161.06766998616, 19959679.267354, 40.96, -5.50, 18.82519512325},
+		{"pole", WGS84, 90, 0, 180, 10001965.729, 0, 0, 180},
+		{"sphere quadrant", NewEllipsoid(6.4e6, 0), 0, 0, 90, 6.4e6 * math.Pi / 2, 0, 90, 90},
+		{"eccentric", NewEllipsoid(6.4e6, 1/30.0), 10, 20, 30, 5e6, 47.85600597, 50.94463607, 46.15431358},
+	}
+
+	for _, dataset := range datasets {
+		lat2, lon2, azi2 := dataset.ellipsoid.Direct(dataset.lat1, dataset.lon1, dataset.azi1, dataset.s12)
+
+		assert.InDelta(t, dataset.lat2, lat2, 1e-5, dataset.name)
+		assert.InDelta(t, dataset.lon2, lon2, 1e-5, dataset.name)
+		assert.InDelta(t, dataset.azi2, azi2, 1e-5, dataset.name)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, e := range []*Ellipsoid{WGS84, NewEllipsoid(6.4e6, 1/30.0), NewEllipsoid(6.4e6, -1/30.0)} {
+		for lat1 := -80.0; lat1 <= 80; lat1 += 40 {
+			for azi1 := -180.0; azi1 < 180; azi1 += 45 {
+				for _, s12 := range []float64{1, 1e3, 1e6, 1e7} {
+					lat2, lon2, azi2 := e.Direct(lat1, 10, azi1, s12)
+					s, a1, a2 := e.Inverse(lat1, 10, lat2, lon2)
+
+					assert.InDelta(t, s12, s, 1e-6*math.Max(1, s12/1e6))
+					assert.InDelta(t, 0, math.Remainder(azi1-a1, 360), 1e-6)
+					assert.InDelta(t, 0, math.Remainder(azi2-a2, 360), 1e-6)
+				}
+			}
+		}
+	}
+}
+
+func TestEllipsoid(t *testing.T) {
+	assert.Equal(t, 6378137.0, WGS84.EquatorialRadius())
+	assert.Equal(t, 1/298.257223563, WGS84.Flattening())
+	assert.InDelta(t, 510065621724088.5, WGS84.SurfaceArea(), 0.1)
+	assert.InDelta(t, 4*math.Pi*sq(6.4e6), NewEllipsoid(6.4e6, 0).SurfaceArea(), 1)
+
+	// GRS80 differs from WGS84 by a tenth of a millimetre on the semi-minor axis
+	s1, _, _ := WGS84.Inverse(0, 0, 90, 0)
+	s2, _, _ := GRS80.Inverse(0, 0, 90, 0)
+	assert.InDelta(t, 0.0001, s1-s2, 0.00005)
+}
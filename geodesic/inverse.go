@@ -0,0 +1,609 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import "math"
+
+// Inverse solves the inverse geodesic problem between two points given in degrees. It returns the length s12 in metres
+// of the shortest geodesic between them, and the azimuths azi1 and azi2 of the geodesic at each point in degrees
+// clockwise from north. azi2 is the forward azimuth, the direction of travel on arrival at the second point.
+func (e *Ellipsoid) Inverse(lat1, lon1, lat2, lon2 float64) (s12, azi1, azi2 float64) {
+	s := e.inverse(lat1, lon1, lat2, lon2, false)
+	return s.s12, atan2d(s.salp1, s.calp1), atan2d(s.salp2, s.calp2)
+}
+
+// solution is the result of the inverse problem, with the azimuths kept as sines and cosines. area is the area between
+// the geodesic and the equator, only computed on request.
+type solution struct {
+	s12                        float64
+	salp1, calp1, salp2, calp2 float64
+	area                       float64
+}
+
+// trial holds the auxiliary sphere quantities found by lambda12 for a trial azimuth at the first point
+type trial struct {
+	salp2, calp2                      float64
+	sig12, ssig1, csig1, ssig2, csig2 float64
+	eps, domg12                       float64
+}
+
+func (e *Ellipsoid) inverse(lat1, lon1, lat2, lon2 float64, area bool) solution {
+	// the longitude difference in [-180, 180], where -180 is only used by west going geodesics
+	lon12, lon12s := angDiff(lon1, lon2)
+
+	lonsign := 1.0
+	if lon12 < 0 {
+		lonsign = -1
+	}
+
+	// when very close to the same half meridian, make it so
+	lon12 = lonsign * angRound(lon12)
+	lon12s = angRound((180 - lon12) - lonsign*lon12s)
+	lam12 := lon12 * degree
+
+	var slam12, clam12 float64
+
+	if lon12 > 90 {
+		slam12, clam12 = sincosd(lon12s)
+		clam12 = -clam12
+	} else {
+		slam12, clam12 = sincosd(lon12)
+	}
+
+	// when really close to the equator, treat as on the equator
+	lat1 = angRound(latFix(lat1))
+	lat2 = angRound(latFix(lat2))
+
+	// swap the points so the one with the greater absolute latitude is first, then make lat1 <= 0. This leaves
+	//
+	//	0 <= lon12 <= 180, -90 <= lat1 <= 0, lat1 <= lat2 <= -lat1
+	//
+	// and lonsign, swapp and latsign record the transformation to undo at the end
+	swapp := 1.0
+
+	if math.Abs(lat1) < math.Abs(lat2) {
+		swapp = -1
+		lonsign = -lonsign
+		lat1, lat2 = lat2, lat1
+	}
+
+	latsign := -1.0
+	if lat1 < 0 {
+		latsign = 1
+	}
+
+	lat1 *= latsign
+	lat2 *= latsign
+
+	sbet1, cbet1 := sincosd(lat1)
+	sbet1, cbet1 = norm(sbet1*e.f1, cbet1)
+	cbet1 = math.Max(tiny, cbet1)
+
+	sbet2, cbet2 := sincosd(lat2)
+	sbet2, cbet2 = norm(sbet2*e.f1, cbet2)
+	cbet2 = math.Max(tiny, cbet2)
+
+	// force bet2 = +/-bet1 exactly when the sensitive measure of their difference vanishes
+	if cbet1 < -sbet1 {
+		if cbet2 == cbet1 {
+			if sbet2 < 0 {
+				sbet2 = sbet1
+			} else {
+				sbet2 = -sbet1
+			}
+		}
+	} else if math.Abs(sbet2) == -sbet1 {
+		cbet2 = cbet1
+	}
+
+	dn1 := math.Sqrt(1 + e.ep2*sq(sbet1))
+	dn2 := math.Sqrt(1 + e.ep2*sq(sbet2))
+
+	var s12x, sig12, omg12 float64
+	var salp1, calp1, salp2, calp2 float64
+
+	// somg12 > 1 marks that it still needs to be computed
+	somg12, comg12 := 2.0, 0.0
+
+	meridian := lat1 == -90 || slam12 == 0
+
+	if meridian {
+		// the end points are on a single full meridian, so the geodesic might lie on it
+		salp1, calp1 = slam12, clam12
+		salp2, calp2 = 0, 1
+
+		ssig1, csig1 := sbet1, calp1*cbet1
+		ssig2, csig2 := sbet2, calp2*cbet2
+
+		sig12 = math.Atan2(math.Max(0, csig1*ssig2-ssig1*csig2), csig1*csig2+ssig1*ssig2)
+
+		var m12x float64
+		s12x, m12x, _ = e.lengths(e.n, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2, true)
+
+		// a meridian with sig12 > pi/2 is not the shortest path unless m12 >= 0, and zero length lines might give
+		// a tiny negative m12
+		if sig12 < 1 || m12x >= 0 {
+			if sig12 < 3*tiny || (sig12 < tol0 && (s12x < 0 || m12x < 0)) {
+				sig12, s12x = 0, 0
+			}
+
+			s12x *= e.b
+		} else {
+			// prolate and too close to antipodal
+			meridian = false
+		}
+	}
+
+	switch {
+	case meridian:
+	case sbet1 == 0 && (e.f <= 0 || lon12s >= e.f*180):
+		// the geodesic runs along the equator
+		salp1, calp1 = 1, 0
+		salp2, calp2 = 1, 0
+		s12x = e.a * lam12
+		omg12 = lam12 / e.f1
+		sig12 = omg12
+	default:
+		// the points are within a hemisphere bounded by a meridian and the geodesic is neither meridional nor
+		// equatorial
+		var dnm float64
+
+		sig12, salp1, calp1, salp2, calp2, dnm = e.inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12,
+			clam12)
+
+		if sig12 >= 0 {
+			// a short line, solved by inverseStart
+			s12x = sig12 * e.b * dnm
+			omg12 = lam12 / (e.f1 * dnm)
+			break
+		}
+
+		// Newton's method on f(alp1) = lambda12(alp1) - lam12, which has exactly one root in (0, pi) with a
+		// positive derivative. A bracket (alp1a, alp1b) around the root is shrunk with every evaluation and its
+		// midpoint is used whenever a Newton step goes the wrong way or leaves the bracket.
+		var t trial
+
+		salp1a, calp1a, salp1b, calp1b := tiny, 1.0, tiny, -1.0
+		tripn, tripb := false, false
+
+		for numit := 0; numit < maxit2; numit++ {
+			var v, dv float64
+
+			v, dv, t = e.lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam12, clam12, numit < maxit1)
+
+			tol := tol0
+			if tripn {
+				tol *= 8
+			}
+
+			// reversed test to allow escape with NaNs
+			if tripb || !(math.Abs(v) >= tol) {
+				break
+			}
+
+			if v > 0 && (numit > maxit1 || calp1/salp1 > calp1b/salp1b) {
+				salp1b, calp1b = salp1, calp1
+			} else if v < 0 && (numit > maxit1 || calp1/salp1 < calp1a/salp1a) {
+				salp1a, calp1a = salp1, calp1
+			}
+
+			if numit < maxit1 && dv > 0 {
+				dalp1 := -v / dv
+				sdalp1, cdalp1 := math.Sincos(dalp1)
+
+				if nsalp1 := salp1*cdalp1 + calp1*sdalp1; nsalp1 > 0 && math.Abs(dalp1) < math.Pi {
+					salp1, calp1 = norm(nsalp1, calp1*cdalp1-salp1*sdalp1)
+
+					// convergence can be linear where the slope tends to 0, so test against epsilon rather than
+					// its square root
+					tripn = math.Abs(v) <= 16*tol0
+					continue
+				}
+			}
+
+			salp1, calp1 = norm((salp1a+salp1b)/2, (calp1a+calp1b)/2)
+			tripn = false
+			tripb = math.Abs(salp1a-salp1)+(calp1a-calp1) < tolb || math.Abs(salp1-salp1b)+(calp1-calp1b) < tolb
+		}
+
+		salp2, calp2, sig12 = t.salp2, t.calp2, t.sig12
+		s12x, _, _ = e.lengths(t.eps, sig12, t.ssig1, t.csig1, dn1, t.ssig2, t.csig2, dn2, true)
+		s12x *= e.b
+
+		if area {
+			// omg12 = lam12 - domg12
+			sdomg12, cdomg12 := math.Sincos(t.domg12)
+			somg12 = slam12*cdomg12 - clam12*sdomg12
+			comg12 = clam12*cdomg12 + slam12*sdomg12
+		}
+	}
+
+	var area12 float64
+
+	if area {
+		area12 = e.area(sbet1, cbet1, sbet2, cbet2, salp1, calp1, salp2, calp2)
+
+		if !meridian && somg12 > 1 {
+			somg12, comg12 = math.Sincos(omg12)
+		}
+
+		var alp12 float64
+
+		if !meridian && comg12 > -0.7071 && sbet2-sbet1 < 1.75 {
+			// omg12 < 3/4 pi and the latitude difference is not too big, so use
+			//
+			//	tan(alp12/2) = tan(omg12/2) * (tan(bet1/2)+tan(bet2/2))/(1+tan(bet1/2)*tan(bet2/2))
+			domg12 := 1 + comg12
+			dbet1 := 1 + cbet1
+			dbet2 := 1 + cbet2
+			alp12 = 2 * math.Atan2(somg12*(sbet1*dbet2+sbet2*dbet1), domg12*(sbet1*sbet2+dbet1*dbet2))
+		} else {
+			// alp12 = alp2 - alp1
+			salp12 := salp2*calp1 - calp2*salp1
+			calp12 := calp2*calp1 + salp2*salp1
+
+			// make sure alp12 = -180 when alp1 = +/-180 and alp2 = 0
+			if salp12 == 0 && calp12 < 0 {
+				salp12 = tiny * calp1
+				calp12 = -1
+			}
+
+			alp12 = math.Atan2(salp12, calp12)
+		}
+
+		area12 += e.c2 * alp12
+		area12 *= swapp * lonsign * latsign
+		area12 += 0
+	}
+
+	// undo the transformation to the canonical form
+	if swapp < 0 {
+		salp1, salp2 = salp2, salp1
+		calp1, calp2 = calp2, calp1
+	}
+
+	salp1 *= swapp * lonsign
+	calp1 *= swapp * latsign
+	salp2 *= swapp * lonsign
+	calp2 *= swapp * latsign
+
+	return solution{0 + s12x, salp1, calp1, salp2, calp2, area12}
+}
+
+// area returns the integral I4 part of the area between a geodesic and the equator, still in the canonical form of
+// inverse
+func (e *Ellipsoid) area(sbet1, cbet1, sbet2, cbet2, salp1, calp1, salp2, calp2 float64) float64 {
+	salp0 := salp1 * cbet1
+	calp0 := hypot(calp1, salp1*sbet1)
+
+	if calp0 == 0 || salp0 == 0 {
+		// sig1 and sig2 are indeterminate on the equator
+		return 0
+	}
+
+	ssig1, csig1 := norm(sbet1, calp1*cbet1)
+	ssig2, csig2 := norm(sbet2, calp2*cbet2)
+
+	k2 := sq(calp0) * e.ep2
+	eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+	a4 := sq(e.a) * calp0 * salp0 * e.e2
+
+	var c4a [order]float64
+	e.c4f(eps, c4a[:])
+
+	b41 := sinCosSeries(false, ssig1, csig1, c4a[:], order)
+	b42 := sinCosSeries(false, ssig2, csig2, c4a[:], order)
+
+	return a4 * (b42 - b41)
+}
+
+// lengths returns the distance s12b and the reduced length m12b, both divided by b, along the geodesic with the
+// parameter eps between sig1 and sig2, and m0, the coefficient of the secular term of the reduced length. The distance
+// is only computed when distp is set.
+func (e *Ellipsoid) lengths(eps, sig12, ssig1, csig1, dn1, ssig2, csig2, dn2 float64, distp bool) (s12b, m12b,
+	m0 float64) {
+	var c1a, c2a [nC]float64
+
+	a1 := a1m1f(eps)
+	c1f(eps, c1a[:])
+	a2 := a2m1f(eps)
+	c2f(eps, c2a[:])
+	m0 = a1 - a2
+	a1++
+	a2++
+
+	var j12 float64
+
+	if distp {
+		b1 := sinCosSeries(true, ssig2, csig2, c1a[:], order) - sinCosSeries(true, ssig1, csig1, c1a[:], order)
+		b2 := sinCosSeries(true, ssig2, csig2, c2a[:], order) - sinCosSeries(true, ssig1, csig1, c2a[:], order)
+		s12b = a1 * (sig12 + b1)
+		j12 = m0*sig12 + (a1*b1 - a2*b2)
+	} else {
+		for l := 1; l <= order; l++ {
+			c2a[l] = a1*c1a[l] - a2*c2a[l]
+		}
+
+		j12 = m0*sig12 + (sinCosSeries(true, ssig2, csig2, c2a[:], order) -
+			sinCosSeries(true, ssig1, csig1, c2a[:], order))
+	}
+
+	// the parentheses ensure accurate cancellation for coincident points
+	m12b = dn2*(csig1*ssig2) - dn1*(ssig1*csig2) - csig1*csig2*j12
+
+	return s12b, m12b, m0
+}
+
+// inverseStart returns a starting azimuth at the first point for Newton's method. For short lines, where the
+// spherical approximation is already accurate, it also returns the arc length sig12 >= 0 with the azimuth at the
+// second point and the mean dn, otherwise sig12 is negative.
+func (e *Ellipsoid) inverseStart(sbet1, cbet1, dn1, sbet2, cbet2, dn2, lam12, slam12, clam12 float64) (sig12, salp1,
+	calp1, salp2, calp2, dnm float64) {
+	sig12 = -1
+
+	// bet12 = bet2 - bet1 in [0, pi), bet12a = bet2 + bet1 in (-pi, 0]
+	sbet12 := sbet2*cbet1 - cbet2*sbet1
+	cbet12 := cbet2*cbet1 + sbet2*sbet1
+	sbet12a := sbet2*cbet1 + cbet2*sbet1
+
+	shortline := cbet12 >= 0 && sbet12 < 0.5 && cbet2*lam12 < 0.5
+
+	var somg12, comg12 float64
+
+	if shortline {
+		// sin((bet1+bet2)/2)^2
+		sbetm2 := sq(sbet1 + sbet2)
+		sbetm2 /= sbetm2 + sq(cbet1+cbet2)
+		dnm = math.Sqrt(1 + e.ep2*sbetm2)
+		somg12, comg12 = math.Sincos(lam12 / (e.f1 * dnm))
+	} else {
+		somg12, comg12 = slam12, clam12
+	}
+
+	salp1 = cbet2 * somg12
+
+	if comg12 >= 0 {
+		calp1 = sbet12 + cbet2*sbet1*sq(somg12)/(1+comg12)
+	} else {
+		calp1 = sbet12a - cbet2*sbet1*sq(somg12)/(1-comg12)
+	}
+
+	ssig12 := hypot(salp1, calp1)
+	csig12 := sbet1*sbet2 + cbet1*cbet2*comg12
+
+	switch {
+	case shortline && ssig12 < e.etol2:
+		// really short lines
+		salp2 = cbet1 * somg12
+
+		if comg12 >= 0 {
+			calp2 = sbet12 - cbet1*sbet2*(sq(somg12)/(1+comg12))
+		} else {
+			calp2 = sbet12 - cbet1*sbet2*(1-comg12)
+		}
+
+		salp2, calp2 = norm(salp2, calp2)
+		sig12 = math.Atan2(ssig12, csig12)
+	case math.Abs(e.n) > 0.1 || csig12 >= 0 || ssig12 >= 6*math.Abs(e.n)*math.Pi*sq(cbet1):
+		// too eccentric for the astroid, or the zeroth order spherical approximation is good enough
+	default:
+		// nearly antipodal: scale lam12 and bet2 to x, y coordinates where the antipodal point is at the origin and
+		// the singular point is at y = 0, x = -1
+		var x, y, lamscale, betscale float64
+
+		lam12x := math.Atan2(-slam12, -clam12) // lam12 - pi
+
+		if e.f >= 0 {
+			k2 := sq(sbet1) * e.ep2
+			eps := k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+			lamscale = e.f * cbet1 * e.a3f(eps) * math.Pi
+			betscale = lamscale * cbet1
+
+			x = lam12x / lamscale
+			y = sbet12a / betscale
+		} else {
+			cbet12a := cbet2*cbet1 - sbet2*sbet1
+			bet12a := math.Atan2(sbet12a, cbet12a)
+
+			_, m12b, m0 := e.lengths(e.n, math.Pi+bet12a, sbet1, -cbet1, dn1, sbet2, cbet2, dn2, false)
+
+			x = -1 + m12b/(cbet1*cbet2*m0*math.Pi)
+
+			if x < -0.01 {
+				betscale = sbet12a / x
+			} else {
+				betscale = -e.f * sq(cbet1) * math.Pi
+			}
+
+			lamscale = betscale / cbet1
+			y = lam12x / lamscale
+		}
+
+		if y > -tol1 && x > -1-xthresh {
+			// the strip near the cut
+			if e.f >= 0 {
+				salp1 = math.Min(1, -x)
+				calp1 = -math.Sqrt(1 - sq(salp1))
+			} else {
+				lim := -1.0
+				if x > -tol1 {
+					lim = 0
+				}
+
+				calp1 = math.Max(lim, x)
+				salp1 = math.Sqrt(1 - sq(calp1))
+			}
+
+			break
+		}
+
+		// estimate omg12 from the astroid, working with omg12a = pi - omg12, and update the spherical estimate of
+		// alp1 with it
+		k := astroid(x, y)
+
+		var omg12a float64
+
+		if e.f >= 0 {
+			omg12a = lamscale * (-x * k / (1 + k))
+		} else {
+			omg12a = lamscale * (-y * (1 + k) / k)
+		}
+
+		somg12, comg12 = math.Sincos(omg12a)
+		comg12 = -comg12
+
+		salp1 = cbet2 * somg12
+		calp1 = sbet12a - cbet2*sbet1*sq(somg12)/(1-comg12)
+	}
+
+	// sanity check the starting guess, written so that NaNs pass through
+	if !(salp1 <= 0) {
+		salp1, calp1 = norm(salp1, calp1)
+	} else {
+		salp1, calp1 = 1, 0
+	}
+
+	return sig12, salp1, calp1, salp2, calp2, dnm
+}
+
+// astroid returns the positive root k of k^4 + 2k^3 - (x^2 + y^2 - 1)k^2 - 2y^2k - y^2 = 0
+func astroid(x, y float64) float64 {
+	p := sq(x)
+	q := sq(y)
+	r := (p + q - 1) / 6
+
+	if q == 0 && r <= 0 {
+		// y = 0 with |x| <= 1
+		return 0
+	}
+
+	// avoid a division by zero when r = 0 by multiplying the equations for s and t by r^3 and r
+	s := p * q / 4 // r^3 * s
+	r2 := sq(r)
+	r3 := r * r2
+
+	// the discriminant of the quadratic for T3, zero on the evolute p^(1/3) + q^(1/3) = 1
+	disc := s * (s + 2*r3)
+	u := r
+
+	if disc >= 0 {
+		// pick the sign of the root that maximises |T3| to minimise cancellation
+		t3 := s + r3
+		if t3 < 0 {
+			t3 -= math.Sqrt(disc)
+		} else {
+			t3 += math.Sqrt(disc)
+		}
+
+		t := math.Cbrt(t3) // r * t
+		if t != 0 {
+			u += t + r2/t
+		}
+	} else {
+		// T is complex but u is real. Choose the cube root that avoids cancellation, disc < 0 implies r < 0.
+		ang := math.Atan2(math.Sqrt(-disc), -(s + r3))
+		u += 2 * r * math.Cos(ang/3)
+	}
+
+	v := math.Sqrt(sq(u) + q)
+
+	// u + v, rearranged when u < 0 to avoid cancellation
+	var uv float64
+	if u < 0 {
+		uv = q / (v - u)
+	} else {
+		uv = u + v
+	}
+
+	w := (uv - q) / (2 * v)
+
+	return uv / (math.Sqrt(uv+sq(w)) + w)
+}
+
+// lambda12 returns the longitude difference lam12 - lam120 on the auxiliary sphere for the geodesic leaving the first
+// point with the azimuth alp1, and its derivative with respect to alp1 when diffp is set
+func (e *Ellipsoid) lambda12(sbet1, cbet1, dn1, sbet2, cbet2, dn2, salp1, calp1, slam120, clam120 float64,
+	diffp bool) (v, dv float64, t trial) {
+	if sbet1 == 0 && calp1 == 0 {
+		// break the degeneracy of the equatorial line, which has already been handled
+		calp1 = -tiny
+	}
+
+	// sin(alp1) * cos(bet1) = sin(alp0)
+	salp0 := salp1 * cbet1
+	calp0 := hypot(calp1, salp1*sbet1)
+
+	// tan(bet1) = tan(sig1) * cos(alp1), tan(omg1) = sin(alp0) * tan(sig1). omg1 needs no normalising.
+	somg1 := salp0 * sbet1
+	comg1 := calp1 * cbet1
+	t.ssig1, t.csig1 = norm(sbet1, comg1)
+
+	// enforce the symmetry when |bet2| = -bet1, which would otherwise give singularities in the iteration
+	if cbet2 != cbet1 {
+		t.salp2 = salp0 / cbet2
+	} else {
+		t.salp2 = salp1
+	}
+
+	if cbet2 != cbet1 || math.Abs(sbet2) != -sbet1 {
+		var d float64
+		if cbet1 < -sbet1 {
+			d = (cbet2 - cbet1) * (cbet1 + cbet2)
+		} else {
+			d = (sbet1 - sbet2) * (sbet1 + sbet2)
+		}
+
+		t.calp2 = math.Sqrt(sq(calp1*cbet1)+d) / cbet2
+	} else {
+		t.calp2 = math.Abs(calp1)
+	}
+
+	somg2 := salp0 * sbet2
+	comg2 := t.calp2 * cbet2
+	t.ssig2, t.csig2 = norm(sbet2, comg2)
+
+	// sig12 = sig2 - sig1 and omg12 = omg2 - omg1, limited to [0, pi]
+	t.sig12 = math.Atan2(math.Max(0, t.csig1*t.ssig2-t.ssig1*t.csig2), t.csig1*t.csig2+t.ssig1*t.ssig2)
+	somg12 := math.Max(0, comg1*somg2-somg1*comg2)
+	comg12 := comg1*comg2 + somg1*somg2
+
+	// eta = omg12 - lam120
+	eta := math.Atan2(somg12*clam120-comg12*slam120, comg12*clam120+somg12*slam120)
+
+	k2 := sq(calp0) * e.ep2
+	t.eps = k2 / (2*(1+math.Sqrt(1+k2)) + k2)
+
+	var c3a [order]float64
+	e.c3f(t.eps, c3a[:])
+
+	b312 := sinCosSeries(true, t.ssig2, t.csig2, c3a[:], order-1) -
+		sinCosSeries(true, t.ssig1, t.csig1, c3a[:], order-1)
+
+	t.domg12 = -e.f * e.a3f(t.eps) * salp0 * (t.sig12 + b312)
+	v = eta + t.domg12
+
+	if diffp {
+		if t.calp2 == 0 {
+			dv = -2 * e.f1 * dn1 / sbet1
+		} else {
+			_, m12b, _ := e.lengths(t.eps, t.sig12, t.ssig1, t.csig1, dn1, t.ssig2, t.csig2, dn2, false)
+			dv = m12b * e.f1 / (t.calp2 * cbet2)
+		}
+	}
+
+	return v, dv, t
+}
@@ -0,0 +1,197 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import (
+	"math"
+
+	"github.com/devork/geom"
+)
+
+// Measures follow the planar ones in the geom package: Length covers the lineal parts of a geometry, Area and Perimeter
+// the polygonal parts. Edges are geodesics, and the arcs of curved geometries are linearized with the default options
+// first. Z and M values are ignored.
+
+// Distance returns the geodesic distance in metres between two points on WGS84
+func Distance(p1, p2 *geom.Point) (float64, error) {
+	return WGS84.Distance(p1, p2)
+}
+
+// Azimuth returns the initial azimuth in degrees clockwise from north of the geodesic from p1 to p2 on WGS84
+func Azimuth(p1, p2 *geom.Point) (float64, error) {
+	return WGS84.Azimuth(p1, p2)
+}
+
+// Length returns the geodesic length in metres of the lineal parts of g on WGS84
+func Length(g geom.Geometry) (float64, error) {
+	return WGS84.Length(g)
+}
+
+// Perimeter returns the geodesic length in metres of the boundaries, including holes, of the polygonal parts of g on
+// WGS84
+func Perimeter(g geom.Geometry) (float64, error) {
+	return WGS84.Perimeter(g)
+}
+
+// Area returns the area in square metres of the polygonal parts of g, excluding holes, on WGS84
+func Area(g geom.Geometry) (float64, error) {
+	return WGS84.Area(g)
+}
+
+// Distance returns the geodesic distance in metres between two points. Returns geom.ErrNoGeometry when either point
+// is nil and geom.ErrEmptyGeometry when either is empty.
+func (e *Ellipsoid) Distance(p1, p2 *geom.Point) (float64, error) {
+	s, err := e.inversePoints(p1, p2)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.s12, nil
+}
+
+// Azimuth returns the initial azimuth in degrees clockwise from north of the geodesic from p1 to p2. Returns
+// geom.ErrNoGeometry when either point is nil and geom.ErrEmptyGeometry when either is empty.
+func (e *Ellipsoid) Azimuth(p1, p2 *geom.Point) (float64, error) {
+	s, err := e.inversePoints(p1, p2)
+	if err != nil {
+		return 0, err
+	}
+
+	return atan2d(s.salp1, s.calp1), nil
+}
+
+// Length returns the geodesic length in metres of the lineal parts of g. The boundaries of polygons are not included,
+// see Perimeter.
+func (e *Ellipsoid) Length(g geom.Geometry) (float64, error) {
+	g, err := geom.Linearize(g)
+	if err != nil {
+		return 0, err
+	}
+
+	return e.length(g), nil
+}
+
+// Perimeter returns the geodesic length in metres of the boundaries, including holes, of the polygonal parts of g
+func (e *Ellipsoid) Perimeter(g geom.Geometry) (float64, error) {
+	g, err := geom.Linearize(g)
+	if err != nil {
+		return 0, err
+	}
+
+	var perimeter float64
+
+	polygons(g, func(rings []geom.LinearRing) {
+		for _, r := range rings {
+			perimeter += e.lineLength(r.Coordinates)
+		}
+	})
+
+	return perimeter, nil
+}
+
+// Area returns the area in square metres of the polygonal parts of g, excluding holes. The orientation of the rings
+// does not matter, but each must enclose less than half of the ellipsoid.
+func (e *Ellipsoid) Area(g geom.Geometry) (float64, error) {
+	g, err := geom.Linearize(g)
+	if err != nil {
+		return 0, err
+	}
+
+	var area float64
+
+	polygons(g, func(rings []geom.LinearRing) {
+		for idx, r := range rings {
+			a, _ := e.ringArea(r.Coordinates)
+
+			if idx == 0 {
+				area += math.Abs(a)
+			} else {
+				area -= math.Abs(a)
+			}
+		}
+	})
+
+	return area, nil
+}
+
+func (e *Ellipsoid) length(g geom.Geometry) float64 {
+	switch g := g.(type) {
+	case *geom.LineString:
+		return e.lineLength(g.Coordinates)
+	case *geom.MultiLineString:
+		var length float64
+
+		for _, ls := range g.LineStrings {
+			length += e.lineLength(ls.Coordinates)
+		}
+
+		return length
+	case *geom.GeometryCollection:
+		var length float64
+
+		for _, m := range g.Geometries {
+			length += e.length(m)
+		}
+
+		return length
+	default:
+		return 0
+	}
+}
+
+// inversePoints solves the inverse problem between two points given as longitude and latitude
+func (e *Ellipsoid) inversePoints(p1, p2 *geom.Point) (solution, error) {
+	if p1 == nil || p2 == nil {
+		return solution{}, geom.ErrNoGeometry
+	}
+
+	if empty(p1) || empty(p2) {
+		return solution{}, geom.ErrEmptyGeometry
+	}
+
+	return e.inverse(p1.Coordinate[1], p1.Coordinate[0], p2.Coordinate[1], p2.Coordinate[0], false), nil
+}
+
+func empty(p *geom.Point) bool {
+	return len(p.Coordinate) < 2 || math.IsNaN(p.Coordinate[0]) || math.IsNaN(p.Coordinate[1])
+}
+
+// polygons calls fn with the rings of each polygonal part of the linearized geometry g
+func polygons(g geom.Geometry, fn func(rings []geom.LinearRing)) {
+	switch g := g.(type) {
+	case *geom.Polygon:
+		fn(g.Rings)
+	case *geom.MultiPolygon:
+		for _, p := range g.Polygons {
+			fn(p.Rings)
+		}
+	case *geom.Triangle:
+		fn(g.Rings)
+	case *geom.PolyhedralSurface:
+		for _, p := range g.Polygons {
+			fn(p.Rings)
+		}
+	case *geom.TIN:
+		for _, t := range g.Triangles {
+			fn(t.Rings)
+		}
+	case *geom.GeometryCollection:
+		for _, m := range g.Geometries {
+			polygons(m, fn)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// one eighth of the ellipsoid, bounded by the equator and two meridians
+	octant = &geom.Polygon{geom.Hdr{geom.XY, 4326}, []geom.LinearRing{
+		{[]geom.Coordinate{{0, 0}, {90, 0}, {0, 90}, {0, 0}}},
+	}}
+	// a clockwise box around London with a hole
+	london = &geom.Polygon{geom.Hdr{geom.XY, 4326}, []geom.LinearRing{
+		{[]geom.Coordinate{{-1, 51}, {-1, 52}, {1, 52}, {1, 51}, {-1, 51}}},
+		{[]geom.Coordinate{{-0.4, 51.4}, {-0.4, 51.6}, {0.2, 51.6}, {0.2, 51.4}, {-0.4, 51.4}}},
+	}}
+	cities = &geom.LineString{geom.Hdr{geom.XY, 4326}, []geom.Coordinate{{-0.12, 51.5}, {2.35, 48.85}, {13.4, 52.52}}}
+)
+
+func TestDistanceAndAzimuth(t *testing.T) {
+	wellington := &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{174.81, -41.32}}
+	salamanca := &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{-5.50, 40.96}}
+
+	d, err := Distance(wellington, salamanca)
+	assert.NoError(t, err)
+	assert.InDelta(t, 19959679.267354, d, 1e-6)
+
+	az, err := Azimuth(wellington, salamanca)
+	assert.NoError(t, err)
+	assert.InDelta(t, 161.06766998616, az, 1e-11)
+
+	az, err = Azimuth(salamanca, wellington)
+	assert.NoError(t, err)
+	assert.InDelta(t, -161.17480487675, az, 1e-11)
+
+	d, err = Distance(wellington, wellington)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, d)
+
+	// on a sphere the geodesic is the great circle
+	phi1, phi2, lambda := -41.32*degree, 40.96*degree, (-5.50-174.81)*degree
+	angle := math.Acos(math.Sin(phi1)*math.Sin(phi2) + math.Cos(phi1)*math.Cos(phi2)*math.Cos(lambda))
+	d, err = NewEllipsoid(6371008.8, 0).Distance(wellington, salamanca)
+	assert.NoError(t, err)
+	assert.InDelta(t, 6371008.8*angle, d, 1e-6)
+}
+
+func TestDistanceAndAzimuthErrors(t *testing.T) {
+	wellington := &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{174.81, -41.32}}
+	nan := math.NaN()
+
+	datasets := []struct {
+		name     string
+		p1, p2   *geom.Point
+		expected error
+	}{
+		{"nil first", nil, wellington, geom.ErrNoGeometry},
+		{"nil second", wellington, nil, geom.ErrNoGeometry},
+		{"empty", &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{nan, nan}}, wellington, geom.ErrEmptyGeometry},
+		{"no coordinate", wellington, &geom.Point{geom.Hdr{geom.XY, 4326}, nil}, geom.ErrEmptyGeometry},
+		{"short coordinate", wellington, &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{1}}, geom.ErrEmptyGeometry},
+	}
+
+	for _, dataset := range datasets {
+		_, err := Distance(dataset.p1, dataset.p2)
+		assert.Equal(t, dataset.expected, err, dataset.name)
+
+		_, err = Azimuth(dataset.p1, dataset.p2)
+		assert.Equal(t, dataset.expected, err, dataset.name)
+	}
+}
+
+func TestArea(t *testing.T) {
+	polar := &geom.Polygon{geom.Hdr{geom.XY, 4326}, []geom.LinearRing{
+		{[]geom.Coordinate{{0, 89}, {90, 89}, {180, 89}, {270, 89}, {0, 89}}},
+	}}
+	diamond := &geom.Polygon{geom.Hdr{geom.XY, 4326}, []geom.LinearRing{
+		{[]geom.Coordinate{{-1, 0}, {0, -1}, {1, 0}, {0, 1}, {-1, 0}}},
+	}}
+	reversed := &geom.Polygon{geom.Hdr{geom.XY, 4326}, []geom.LinearRing{
+		{[]geom.Coordinate{{0, 0}, {0, 90}, {90, 0}, {0, 0}}},
+	}}
+
+	datasets := []struct {
+		name     string
+		data     geom.Geometry
+		expected float64
+	}{
+		{"octant", octant, 63758202715511.0547},
+		{"clockwise octant", reversed, 63758202715511.0547},
+		{"polar cap", polar, 24952305678.0312},
+		{"diamond on the equator", diamond, 24619419146.8857},
+		{"polygon with hole", london, 15450086938.4484 - 927088598.5342},
+		{"multipolygon", &geom.MultiPolygon{geom.Hdr{geom.XY, 4326}, []geom.Polygon{*octant, *diamond}}, 63758202715511.0547 + 24619419146.8857},
+		{"triangle", &geom.Triangle{geom.Hdr{geom.XY, 4326}, octant.Rings}, 63758202715511.0547},
+		{"collection", &geom.GeometryCollection{geom.Hdr{geom.XY, 4326}, []geom.Geometry{diamond, cities}}, 24619419146.8857},
+		{"linestring", cities, 0},
+	}
+
+	for _, dataset := range datasets {
+		area, err := Area(dataset.data)
+		assert.NoError(t, err, dataset.name)
+		assert.InDelta(t, dataset.expected, area, 1e-2, dataset.name)
+	}
+}
+
+func TestLengthAndPerimeter(t *testing.T) {
+	length, err := Length(cities)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1223404.038447, length, 1e-6)
+
+	length, err = Length(&geom.MultiLineString{geom.Hdr{geom.XY, 4326}, []geom.LineString{*cities, *cities}})
+	assert.NoError(t, err)
+	assert.InDelta(t, 2*1223404.038447, length, 1e-6)
+
+	length, err = Length(octant)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, length)
+
+	perimeter, err := Perimeter(octant)
+	assert.NoError(t, err)
+	assert.InDelta(t, 30022685.630020, perimeter, 1e-6)
+
+	perimeter, err = Perimeter(london)
+	assert.NoError(t, err)
+	assert.InDelta(t, 500258.406944+127831.391978, perimeter, 1e-6)
+
+	perimeter, err = Perimeter(cities)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, perimeter)
+}
+
+func TestCurves(t *testing.T) {
+	arc := &geom.CircularString{geom.Hdr{geom.XY, 4326}, []geom.Coordinate{{-1, 50}, {0, 51}, {1, 50}}}
+
+	line, err := geom.Linearize(arc)
+	assert.NoError(t, err)
+
+	expected, err := Length(line)
+	assert.NoError(t, err)
+
+	length, err := Length(arc)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, length)
+
+	_, err = Length(&geom.CircularString{geom.Hdr{geom.XY, 4326}, []geom.Coordinate{{-1, 50}, {0, 51}}})
+	assert.ErrorIs(t, err, geom.ErrInvalidArc)
+
+	_, err = Area(nil)
+	assert.ErrorIs(t, err, geom.ErrNoGeometry)
+}
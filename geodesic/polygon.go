@@ -0,0 +1,129 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+import (
+	"math"
+
+	"github.com/devork/geom"
+)
+
+// accumulator sums values with twice the precision of a float64, which keeps the small area of a polygon accurate when
+// it is the difference of the large areas between each edge and the equator
+type accumulator struct {
+	s, t float64
+}
+
+func (a *accumulator) add(y float64) {
+	z, u := sum(y, a.t)
+	a.s, a.t = sum(z, a.s)
+
+	if a.s == 0 {
+		a.s = u
+	} else {
+		a.t += u
+	}
+}
+
+// reduce reduces the sum to [-y/2, y/2]
+func (a *accumulator) reduce(y float64) {
+	a.s = math.Remainder(a.s, y)
+	a.add(0)
+}
+
+func (a *accumulator) negate() {
+	a.s, a.t = -a.s, -a.t
+}
+
+// transit returns 1 or -1 when the edge from lon1 to lon2 crosses the prime meridian heading east or west, and 0
+// otherwise
+func transit(lon1, lon2 float64) int {
+	lon1 = angNormalize(lon1)
+	lon2 = angNormalize(lon2)
+	lon12, _ := angDiff(lon1, lon2)
+
+	switch {
+	case lon1 <= 0 && lon2 > 0 && lon12 > 0:
+		return 1
+	case lon2 <= 0 && lon1 > 0 && lon12 < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// lineLength returns the length of the geodesics joining the coordinates in turn
+func (e *Ellipsoid) lineLength(coords []geom.Coordinate) float64 {
+	var length accumulator
+
+	for i := 1; i < len(coords); i++ {
+		length.add(e.inverse(coords[i-1][1], coords[i-1][0], coords[i][1], coords[i][0], false).s12)
+	}
+
+	return length.s
+}
+
+// ringArea returns the area enclosed by a ring of geodesic edges, positive when the ring is counter-clockwise, and
+// its perimeter. The ring is closed between its last and first coordinates if it is not already.
+func (e *Ellipsoid) ringArea(coords []geom.Coordinate) (area, perimeter float64) {
+	n := len(coords)
+
+	if n > 1 && coords[0][0] == coords[n-1][0] && coords[0][1] == coords[n-1][1] {
+		n--
+	}
+
+	if n < 3 {
+		return 0, e.lineLength(coords)
+	}
+
+	var a, p accumulator
+	var crossings int
+
+	for i := 0; i < n; i++ {
+		c1, c2 := coords[i], coords[(i+1)%n]
+		lon1, lon2 := angNormalize(c1[0]), angNormalize(c2[0])
+
+		s := e.inverse(c1[1], lon1, c2[1], lon2, true)
+		p.add(s.s12)
+		a.add(s.area)
+		crossings += transit(lon1, lon2)
+	}
+
+	// the edge areas are measured clockwise from the equator; a ring crossing the prime meridian an odd number of
+	// times encircles a pole and needs half the ellipsoid adding or removing
+	area0 := e.SurfaceArea()
+	a.reduce(area0)
+
+	if crossings&1 != 0 {
+		if a.s < 0 {
+			a.add(area0 / 2)
+		} else {
+			a.add(-area0 / 2)
+		}
+	}
+
+	a.negate()
+
+	// put the area in (-area0/2, area0/2]
+	if a.s > area0/2 {
+		a.add(-area0)
+	} else if a.s <= -area0/2 {
+		a.add(area0)
+	}
+
+	return 0 + a.s, p.s
+}
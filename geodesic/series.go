@@ -0,0 +1,277 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geodesic
+
+// The series expansions in the flattening are carried to sixth order, which is accurate to round off for |f| < 0.01.
+// Each coefficient table holds polynomials stored highest power first, each followed by its divisor.
+const (
+	order = 6
+	nC    = order + 1
+	nA3x  = order
+	nC3x  = order * (order - 1) / 2
+	nC4x  = order * (order + 1) / 2
+)
+
+var (
+	// (1-eps)*A1-1, polynomial in eps^2 of order 3
+	coeffA1 = []float64{1, 4, 64, 0, 256}
+
+	// C1[l]/eps^l, polynomials in eps^2
+	coeffC1 = []float64{
+		-1, 6, -16, 32,
+		-9, 64, -128, 2048,
+		9, -16, 768,
+		3, -5, 512,
+		-7, 1280,
+		-7, 2048,
+	}
+
+	// C1p[l]/eps^l, polynomials in eps^2
+	coeffC1p = []float64{
+		205, -432, 768, 1536,
+		4005, -4736, 3840, 12288,
+		-225, 116, 384,
+		-7173, 2695, 7680,
+		3467, 7680,
+		38081, 61440,
+	}
+
+	// (1+eps)*A2-1, polynomial in eps^2 of order 3
+	coeffA2 = []float64{-11, -28, -192, 0, 256}
+
+	// C2[l]/eps^l, polynomials in eps^2
+	coeffC2 = []float64{
+		1, 2, 16, 32,
+		35, 64, 384, 2048,
+		15, 80, 768,
+		7, 35, 512,
+		63, 1280,
+		77, 2048,
+	}
+
+	// A3, coefficients of eps^5 down to eps^0 as polynomials in n
+	coeffA3 = []float64{
+		-3, 128,
+		-2, -3, 64,
+		-1, -3, -1, 16,
+		3, -1, -2, 8,
+		1, -1, 2,
+		1, 1,
+	}
+
+	// C3[l], coefficients of eps^5 down to eps^l as polynomials in n
+	coeffC3 = []float64{
+		3, 128,
+		2, 5, 128,
+		-1, 3, 3, 64,
+		-1, 0, 1, 8,
+		-1, 1, 4,
+		5, 256,
+		1, 3, 128,
+		-3, -2, 3, 64,
+		1, -3, 2, 32,
+		7, 512,
+		-10, 9, 384,
+		5, -9, 5, 192,
+		7, 512,
+		-14, 7, 512,
+		21, 2560,
+	}
+
+	// C4[l], coefficients of eps^5 down to eps^l as polynomials in n
+	coeffC4 = []float64{
+		97, 15015,
+		1088, 156, 45045,
+		-224, -4784, 1573, 45045,
+		-10656, 14144, -4576, -858, 45045,
+		64, 624, -4576, 6864, -3003, 15015,
+		100, 208, 572, 3432, -12012, 30030, 45045,
+		1, 9009,
+		-2944, 468, 135135,
+		5792, 1040, -1287, 135135,
+		5952, -11648, 9152, -2574, 135135,
+		-64, -624, 4576, -6864, 3003, 135135,
+		8, 10725,
+		1856, -936, 225225,
+		-8448, 4992, -1144, 225225,
+		-1440, 4160, -4576, 1716, 225225,
+		-136, 63063,
+		1024, -208, 105105,
+		3584, -3328, 1144, 315315,
+		-128, 135135,
+		-2560, 832, 405405,
+		128, 99099,
+	}
+)
+
+// polyval evaluates the polynomial of degree n with coefficients p, highest power first, at x
+func polyval(n int, p []float64, x float64) float64 {
+	var y float64
+
+	for i := 0; i <= n; i++ {
+		y = y*x + p[i]
+	}
+
+	return y
+}
+
+// a1m1f returns the scale factor A1-1, the mean value of (d/dsigma)I1 - 1
+func a1m1f(eps float64) float64 {
+	m := order / 2
+	t := polyval(m, coeffA1, sq(eps)) / coeffA1[m+1]
+
+	return (t + eps) / (1 - eps)
+}
+
+// a2m1f returns the scale factor A2-1, the mean value of (d/dsigma)I2 - 1
+func a2m1f(eps float64) float64 {
+	m := order / 2
+	t := polyval(m, coeffA2, sq(eps)) / coeffA2[m+1]
+
+	return (t - eps) / (1 + eps)
+}
+
+// fourier sets c[1] to c[order] from a table of polynomials in eps^2, each scaled by the matching power of eps
+func fourier(coeff []float64, eps float64, c []float64) {
+	eps2 := sq(eps)
+	d := eps
+	o := 0
+
+	for l := 1; l <= order; l++ {
+		m := (order - l) / 2
+		c[l] = d * polyval(m, coeff[o:], eps2) / coeff[o+m+1]
+		o += m + 2
+		d *= eps
+	}
+}
+
+// c1f sets the coefficients C1[l] of the Fourier expansion of B1
+func c1f(eps float64, c []float64) {
+	fourier(coeffC1, eps, c)
+}
+
+// c1pf sets the coefficients C1p[l] of the reverted expansion of B1
+func c1pf(eps float64, c []float64) {
+	fourier(coeffC1p, eps, c)
+}
+
+// c2f sets the coefficients C2[l] of the Fourier expansion of B2
+func c2f(eps float64, c []float64) {
+	fourier(coeffC2, eps, c)
+}
+
+// a3coeff reduces the A3 table to polynomials in eps for the third flattening of e
+func (e *Ellipsoid) a3coeff() {
+	o, k := 0, 0
+
+	for j := order - 1; j >= 0; j-- {
+		m := min(order-j-1, j)
+		e.a3x[k] = polyval(m, coeffA3[o:], e.n) / coeffA3[o+m+1]
+		k++
+		o += m + 2
+	}
+}
+
+// c3coeff reduces the C3 table to polynomials in eps for the third flattening of e
+func (e *Ellipsoid) c3coeff() {
+	o, k := 0, 0
+
+	for l := 1; l < order; l++ {
+		for j := order - 1; j >= l; j-- {
+			m := min(order-j-1, j)
+			e.c3x[k] = polyval(m, coeffC3[o:], e.n) / coeffC3[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+// c4coeff reduces the C4 table to polynomials in eps for the third flattening of e
+func (e *Ellipsoid) c4coeff() {
+	o, k := 0, 0
+
+	for l := 0; l < order; l++ {
+		for j := order - 1; j >= l; j-- {
+			m := order - j - 1
+			e.c4x[k] = polyval(m, coeffC4[o:], e.n) / coeffC4[o+m+1]
+			k++
+			o += m + 2
+		}
+	}
+}
+
+// a3f returns A3, the scale factor of the longitude integral I3
+func (e *Ellipsoid) a3f(eps float64) float64 {
+	return polyval(order-1, e.a3x[:], eps)
+}
+
+// c3f sets c[1] to c[order-1], the coefficients of the Fourier expansion of I3
+func (e *Ellipsoid) c3f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+
+	for l := 1; l < order; l++ {
+		m := order - l - 1
+		mult *= eps
+		c[l] = mult * polyval(m, e.c3x[o:], eps)
+		o += m + 1
+	}
+}
+
+// c4f sets c[0] to c[order-1], the coefficients of the Fourier expansion of the area integral I4
+func (e *Ellipsoid) c4f(eps float64, c []float64) {
+	mult := 1.0
+	o := 0
+
+	for l := 0; l < order; l++ {
+		m := order - l - 1
+		c[l] = mult * polyval(m, e.c4x[o:], eps)
+		o += m + 1
+		mult *= eps
+	}
+}
+
+// sinCosSeries evaluates sum(c[l] * sin(2*l*x), l, 1, n) when sinp is set, and sum(c[l] * cos((2*l+1)*x), l, 0,
+// n-1) otherwise, using Clenshaw summation
+func sinCosSeries(sinp bool, sinx, cosx float64, c []float64, n int) float64 {
+	k := n
+	if sinp {
+		k++
+	}
+
+	ar := 2 * (cosx - sinx) * (cosx + sinx) // 2 * cos(2 * x)
+
+	var y0, y1 float64
+
+	if n&1 != 0 {
+		k--
+		y0 = c[k]
+	}
+
+	for n /= 2; n > 0; n-- {
+		k--
+		y1 = ar*y0 - y1 + c[k]
+		k--
+		y0 = ar*y1 - y0 + c[k]
+	}
+
+	if sinp {
+		return 2 * sinx * cosx * y0 // sin(2 * x) * y0
+	}
+
+	return cosx * (y0 - y1) // cos(x) * (y0 - y1)
+}
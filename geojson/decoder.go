@@ -0,0 +1,347 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/devork/geom"
+)
+
+// wgs84 is the SRID of the only coordinate reference system permitted by RFC 7946
+const wgs84 uint32 = 4326
+
+// object is the raw form of a GeoJSON geometry object
+type object struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []json.RawMessage `json:"geometries"`
+}
+
+// dims tracks the dimension of a geometry as it is decoded. GeoJSON carries no dimension information, so it is
+// inferred from the number of ordinates in the first coordinate and enforced for the remainder.
+type dims struct {
+	dim   geom.Dimension
+	fixed bool
+}
+
+func (s *dims) check(c []float64) error {
+	var dim geom.Dimension
+
+	switch len(c) {
+	case 2:
+		dim = geom.XY
+	case 3:
+		dim = geom.XYZ
+	case 4:
+		dim = geom.XYZM
+	default:
+		return fmt.Errorf("geojson: position must have 2 to 4 elements, but got %d", len(c))
+	}
+
+	if !s.fixed {
+		s.dim = dim
+		s.fixed = true
+	}
+
+	if dim != s.dim {
+		return fmt.Errorf("geojson: mixed position sizes, expected %d elements but got %d", coordSize(s.dim), len(c))
+	}
+
+	return nil
+}
+
+func (s *dims) hdr() geom.Hdr {
+	return geom.Hdr{Dim: s.dim, Srid: wgs84}
+}
+
+func coordSize(d geom.Dimension) int {
+	switch d {
+	case geom.XYZ, geom.XYM:
+		return 3
+	case geom.XYZM:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// Decode converts the GeoJSON geometry object read from the given reader to a geom type. The dimension of the
+// geometry is inferred from the number of elements in each position, which must be consistent throughout, and the
+// SRID is set to 4326 as required by RFC 7946. Anything but white space after the geometry object is an error.
+func Decode(r io.Reader) (geom.Geometry, error) {
+	var raw json.RawMessage
+
+	dec := json.NewDecoder(r)
+	err := dec.Decode(&raw)
+
+	if err != nil {
+		return nil, fmt.Errorf("geojson: %w", err)
+	}
+
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("geojson: unexpected trailing data after the geometry object")
+	}
+
+	return unmarshal(raw, &dims{})
+}
+
+func unmarshalPoint(o *object, s *dims) (geom.Geometry, error) {
+	var coord []float64
+
+	err := unmarshalCoords(o, &coord)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty position array is treated as an empty point
+	if len(coord) == 0 {
+		return &geom.Point{s.hdr(), nil}, nil
+	}
+
+	err = s.check(coord)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &geom.Point{s.hdr(), coord}, nil
+}
+
+func unmarshalMultiPoint(o *object, s *dims) (geom.Geometry, error) {
+	var coords [][]float64
+
+	err := unmarshalCoords(o, &coords)
+
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]geom.Point, len(coords))
+	for idx, coord := range coords {
+		err = s.check(coord)
+
+		if err != nil {
+			return nil, err
+		}
+
+		points[idx].Coordinate = coord
+	}
+
+	for idx := range points {
+		points[idx].Hdr = s.hdr()
+	}
+
+	return &geom.MultiPoint{s.hdr(), points}, nil
+}
+
+func unmarshalLineString(o *object, s *dims) (geom.Geometry, error) {
+	var coords [][]float64
+
+	err := unmarshalCoords(o, &coords)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lcoords, err := toCoords(coords, s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lcoords) == 1 {
+		return nil, fmt.Errorf("geojson: LineString must have at least 2 positions")
+	}
+
+	return &geom.LineString{s.hdr(), lcoords}, nil
+}
+
+func unmarshalMultiLineString(o *object, s *dims) (geom.Geometry, error) {
+	var coords [][][]float64
+
+	err := unmarshalCoords(o, &coords)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lstrings := make([]geom.LineString, len(coords))
+	for idx, lcoords := range coords {
+		lstrings[idx].Coordinates, err = toCoords(lcoords, s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(lcoords) < 2 {
+			return nil, fmt.Errorf("geojson: MultiLineString member %d must have at least 2 positions", idx)
+		}
+	}
+
+	for idx := range lstrings {
+		lstrings[idx].Hdr = s.hdr()
+	}
+
+	return &geom.MultiLineString{s.hdr(), lstrings}, nil
+}
+
+func unmarshalPolygon(o *object, s *dims) (geom.Geometry, error) {
+	var coords [][][]float64
+
+	err := unmarshalCoords(o, &coords)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rings, err := toRings(coords, s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &geom.Polygon{s.hdr(), rings}, nil
+}
+
+func unmarshalMultiPolygon(o *object, s *dims) (geom.Geometry, error) {
+	var coords [][][][]float64
+
+	err := unmarshalCoords(o, &coords)
+
+	if err != nil {
+		return nil, err
+	}
+
+	polys := make([]geom.Polygon, len(coords))
+	for idx, pcoords := range coords {
+		polys[idx].Rings, err = toRings(pcoords, s)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for idx := range polys {
+		polys[idx].Hdr = s.hdr()
+	}
+
+	return &geom.MultiPolygon{s.hdr(), polys}, nil
+}
+
+func unmarshalGeometryCollection(o *object, s *dims) (geom.Geometry, error) {
+	if o.Geometries == nil {
+		return nil, fmt.Errorf("geojson: GeometryCollection is missing the geometries member")
+	}
+
+	geoms := make([]geom.Geometry, len(o.Geometries))
+	for idx, raw := range o.Geometries {
+		g, err := unmarshal(raw, s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		geoms[idx] = g
+	}
+
+	return &geom.GeometryCollection{s.hdr(), geoms}, nil
+}
+
+// unmarshalCoords decodes the coordinates member into the nested slices for the geometry type
+func unmarshalCoords(o *object, v interface{}) error {
+	if o.Coordinates == nil || string(o.Coordinates) == "null" {
+		return fmt.Errorf("geojson: %s is missing the coordinates member", o.Type)
+	}
+
+	err := json.Unmarshal(o.Coordinates, v)
+
+	if err != nil {
+		return fmt.Errorf("geojson: invalid %s coordinates: %w", o.Type, err)
+	}
+
+	return nil
+}
+
+func toRings(coords [][][]float64, s *dims) ([]geom.LinearRing, error) {
+	var err error
+
+	rings := make([]geom.LinearRing, len(coords))
+	for idx, rcoords := range coords {
+		rings[idx].Coordinates, err = toCoords(rcoords, s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rcoords) < 4 {
+			return nil, fmt.Errorf("geojson: linear ring must have at least 4 positions, but got %d", len(rcoords))
+		}
+	}
+
+	return rings, nil
+}
+
+func toCoords(coords [][]float64, s *dims) ([]geom.Coordinate, error) {
+	gcoords := make([]geom.Coordinate, len(coords))
+	for idx, coord := range coords {
+		err := s.check(coord)
+
+		if err != nil {
+			return nil, err
+		}
+
+		gcoords[idx] = coord
+	}
+
+	return gcoords, nil
+}
+
+// Resolves a geometry object to its unmarshaller instance
+func unmarshal(data json.RawMessage, s *dims) (geom.Geometry, error) {
+	var o object
+
+	err := json.Unmarshal(data, &o)
+
+	if err != nil {
+		return nil, fmt.Errorf("geojson: invalid geometry object: %w", err)
+	}
+
+	switch o.Type {
+	case "Point":
+		return unmarshalPoint(&o, s)
+	case "MultiPoint":
+		return unmarshalMultiPoint(&o, s)
+	case "LineString":
+		return unmarshalLineString(&o, s)
+	case "MultiLineString":
+		return unmarshalMultiLineString(&o, s)
+	case "Polygon":
+		return unmarshalPolygon(&o, s)
+	case "MultiPolygon":
+		return unmarshalMultiPolygon(&o, s)
+	case "GeometryCollection":
+		return unmarshalGeometryCollection(&o, s)
+	case "":
+		return nil, fmt.Errorf("geojson: geometry object is missing the type member")
+	default:
+		return nil, fmt.Errorf("geojson: unknown geometry type %q: %w", o.Type, geom.ErrUnsupportedGeom)
+	}
+}
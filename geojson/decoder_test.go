@@ -0,0 +1,212 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodePoint(t *testing.T) {
+	datasets := []struct {
+		data     string
+		dim      geom.Dimension
+		expected geom.Coordinate
+	}{
+		{`{"type":"Point","coordinates":[-0.11834,51.503475]}`, geom.XY, geom.Coordinate{-0.11834, 51.503475}},
+		{`{"coordinates":[1,2,3], "type":"Point"}`, geom.XYZ, geom.Coordinate{1, 2, 3}},
+		{`{"type":"Point","coordinates":[1,2,3,4]}`, geom.XYZM, geom.Coordinate{1, 2, 3, 4}},
+		{`{"type":"Point","coordinates":[]}`, geom.XY, nil},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset.data))
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset.data, err)
+		}
+
+		assert.Equal(t, "point", g.Type())
+
+		p := g.(*geom.Point)
+		assert.Equal(t, dataset.dim, p.Dimension())
+		assert.Equal(t, uint32(4326), p.SRID())
+		assert.Equal(t, dataset.expected, p.Coordinate)
+	}
+}
+
+func TestDecodeMultiPoint(t *testing.T) {
+	g, err := Decode(strings.NewReader(`{"type":"MultiPoint","coordinates":[[-105.01621,39.57422],[-80.6665134,35.0539943]]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to decode MultiPoint: err = %s", err)
+	}
+
+	mpoint := g.(*geom.MultiPoint)
+
+	assert.Equal(t, 2, len(mpoint.Points))
+	assert.Equal(t, geom.XY, mpoint.Points[1].Dimension())
+	assert.Equal(t, geom.Coordinate{-80.6665134, 35.0539943}, mpoint.Points[1].Coordinate)
+}
+
+func TestDecodeLineString(t *testing.T) {
+	g, err := Decode(strings.NewReader(`{"type":"LineString","coordinates":[[30,10,1],[10,30,2],[40,40,3]]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to decode LineString: err = %s", err)
+	}
+
+	assert.Equal(t, &geom.LineString{geom.Hdr{geom.XYZ, 4326}, []geom.Coordinate{{30, 10, 1}, {10, 30, 2}, {40, 40, 3}}}, g)
+}
+
+func TestDecodeMultiLineString(t *testing.T) {
+	g, err := Decode(strings.NewReader(`{"type":"MultiLineString","coordinates":[[[10,10],[20,20],[10,40]],[[40,40],[30,30],[40,20],[30,10]]]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to decode MultiLineString: err = %s", err)
+	}
+
+	mlstring := g.(*geom.MultiLineString)
+
+	assert.Equal(t, 2, len(mlstring.LineStrings))
+	assert.Equal(t, 4, len(mlstring.LineStrings[1].Coordinates))
+	assert.Equal(t, geom.Coordinate{30, 10}, mlstring.LineStrings[1].Coordinates[3])
+}
+
+func TestDecodePolygon(t *testing.T) {
+	g, err := Decode(strings.NewReader(`{"type":"Polygon","coordinates":[[[35,10],[45,45],[15,40],[10,20],[35,10]],[[20,30],[35,35],[30,20],[20,30]]]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to decode Polygon: err = %s", err)
+	}
+
+	polygon := g.(*geom.Polygon)
+
+	assert.Equal(t, 2, len(polygon.Rings))
+	assert.Equal(t, 5, len(polygon.Rings[0].Coordinates))
+	assert.Equal(t, geom.Coordinate{20, 30}, polygon.Rings[1].Coordinates[3])
+}
+
+func TestDecodeMultiPolygon(t *testing.T) {
+	g, err := Decode(strings.NewReader(`{"type":"MultiPolygon","coordinates":[[[[40,40],[20,45],[45,30],[40,40]]],[[[20,35],[10,30],[10,10],[30,5],[45,20],[20,35]],[[30,20],[20,15],[20,25],[30,20]]]]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to decode MultiPolygon: err = %s", err)
+	}
+
+	mpolygon := g.(*geom.MultiPolygon)
+
+	assert.Equal(t, 2, len(mpolygon.Polygons))
+	assert.Equal(t, 1, len(mpolygon.Polygons[0].Rings))
+	assert.Equal(t, 2, len(mpolygon.Polygons[1].Rings))
+	assert.Equal(t, geom.XY, mpolygon.Polygons[1].Dimension())
+}
+
+func TestDecodeGeometryCollection(t *testing.T) {
+	g, err := Decode(strings.NewReader(`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[4,6]},{"type":"LineString","coordinates":[[4,6],[7,10]]}]}`))
+
+	if err != nil {
+		t.Fatalf("Failed to decode GeometryCollection: err = %s", err)
+	}
+
+	gcol := g.(*geom.GeometryCollection)
+
+	assert.Equal(t, 2, len(gcol.Geometries))
+	assert.Equal(t, &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{4, 6}}, gcol.Geometries[0])
+	assert.Equal(t, &geom.LineString{geom.Hdr{geom.XY, 4326}, []geom.Coordinate{{4, 6}, {7, 10}}}, gcol.Geometries[1])
+}
+
+func TestDecodeErrors(t *testing.T) {
+	datasets := []struct {
+		data     string
+		expected string
+	}{
+		{``, "geojson: EOF"},
+		{`{"type":"Point","coordinates":[1,2]`, "geojson: unexpected EOF"},
+		{`{"type":"Point","coordinates":[1,2]} garbage`, "geojson: unexpected trailing data after the geometry object"},
+		{`{"type":"Point","coordinates":[1,2]}{}`, "geojson: unexpected trailing data after the geometry object"},
+		{`{"type":"Point","coordinates":[1,2]}]`, "geojson: unexpected trailing data after the geometry object"},
+		{`{"coordinates":[1,2]}`, "geojson: geometry object is missing the type member"},
+		{`{"type":"Point"}`, "geojson: Point is missing the coordinates member"},
+		{`{"type":"Point","coordinates":[1]}`, "geojson: position must have 2 to 4 elements, but got 1"},
+		{`{"type":"Point","coordinates":"1,2"}`, "geojson: invalid Point coordinates: json: cannot unmarshal string into Go value of type []float64"},
+		{`{"type":"LineString","coordinates":[[1,2],[3,4,5]]}`, "geojson: mixed position sizes, expected 2 elements but got 3"},
+		{`{"type":"LineString","coordinates":[[1,2]]}`, "geojson: LineString must have at least 2 positions"},
+		{`{"type":"Polygon","coordinates":[[[1,2],[3,4],[1,2]]]}`, "geojson: linear ring must have at least 4 positions, but got 3"},
+		{`{"type":"GeometryCollection"}`, "geojson: GeometryCollection is missing the geometries member"},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"Point","coordinates":[1,2,3]}]}`, "geojson: mixed position sizes, expected 2 elements but got 3"},
+	}
+
+	for _, dataset := range datasets {
+		_, err := Decode(strings.NewReader(dataset.data))
+
+		if assert.Error(t, err, "Expected error decoding %q", dataset.data) {
+			assert.Equal(t, dataset.expected, err.Error())
+		}
+	}
+
+	_, err := Decode(strings.NewReader(`{"type":"Feature","geometry":null}`))
+	assert.True(t, errors.Is(err, geom.ErrUnsupportedGeom))
+
+	var serr *json.SyntaxError
+	_, err = Decode(strings.NewReader(`{"type":"Point","coordinates":[1,]}`))
+	assert.True(t, errors.As(err, &serr))
+
+	var terr *json.UnmarshalTypeError
+	_, err = Decode(strings.NewReader(`{"type":"Point","coordinates":"1,2"}`))
+	assert.True(t, errors.As(err, &terr))
+
+	_, err = Decode(strings.NewReader(`{"type":"GeometryCollection","geometries":[{"type":7}]}`))
+	assert.True(t, errors.As(err, &terr))
+
+	_, err = Decode(strings.NewReader("{\"type\":\"Point\",\"coordinates\":[1,2]}\n \t"))
+	assert.NoError(t, err)
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	expected := &geom.GeometryCollection{
+		geom.Hdr{Dim: geom.XYZ, Srid: 4326},
+		[]geom.Geometry{
+			&geom.Point{geom.Hdr{Dim: geom.XYZ, Srid: 4326}, geom.Coordinate{-0.11834, 51.503475, -12.3456789}},
+			&geom.Polygon{
+				geom.Hdr{Dim: geom.XYZ, Srid: 4326},
+				[]geom.LinearRing{{[]geom.Coordinate{{30, 10, 1}, {40, 40, 1}, {20, 40, 1}, {10, 20, 1}, {30, 10, 1}}}},
+			},
+		},
+	}
+
+	var sb bytes.Buffer
+	err := Encode(expected, &sb)
+
+	if err != nil {
+		t.Fatalf("failed to marshal geometry collection: %s", err)
+	}
+
+	g, err := Decode(&sb)
+
+	if err != nil {
+		t.Fatalf("failed to decode geometry collection: %s", err)
+	}
+
+	assert.Equal(t, expected, g)
+}
@@ -57,6 +57,12 @@ func Encode(g geom.Geometry, w io.Writer) error {
 		return marshalMultiPolygon(g, w)
 	case *geom.GeometryCollection:
 		return marshalGeometryCollection(g, w)
+	case *geom.Triangle:
+		return marshalPolygon(triangleAsPolygon(g), w)
+	case *geom.PolyhedralSurface:
+		return marshalMultiPolygon(surfaceAsMultiPolygon(g), w)
+	case *geom.TIN:
+		return marshalMultiPolygon(tinAsMultiPolygon(g), w)
 	default:
 		return geom.ErrUnsupportedGeom
 	}
@@ -225,6 +231,12 @@ func marshalGeometryCollection(gc *geom.GeometryCollection, w io.Writer) error {
 			marshalMultiPolygon(g, &sb)
 		case *geom.GeometryCollection:
 			marshalGeometryCollection(g, &sb)
+		case *geom.Triangle:
+			marshalPolygon(triangleAsPolygon(g), &sb)
+		case *geom.PolyhedralSurface:
+			marshalMultiPolygon(surfaceAsMultiPolygon(g), &sb)
+		case *geom.TIN:
+			marshalMultiPolygon(tinAsMultiPolygon(g), &sb)
 		default:
 			return geom.ErrUnsupportedGeom
 		}
@@ -241,6 +253,26 @@ func marshalGeometryCollection(gc *geom.GeometryCollection, w io.Writer) error {
 	return err
 }
 
+// GeoJSON has no triangle or surface types, so these are written as the equivalent Polygon and MultiPolygon
+
+func triangleAsPolygon(t *geom.Triangle) *geom.Polygon {
+	return &geom.Polygon{t.Hdr, t.Rings}
+}
+
+func surfaceAsMultiPolygon(ps *geom.PolyhedralSurface) *geom.MultiPolygon {
+	return &geom.MultiPolygon{ps.Hdr, ps.Polygons}
+}
+
+func tinAsMultiPolygon(t *geom.TIN) *geom.MultiPolygon {
+	polygons := make([]geom.Polygon, 0, len(t.Triangles))
+
+	for _, tri := range t.Triangles {
+		polygons = append(polygons, *triangleAsPolygon(&tri))
+	}
+
+	return &geom.MultiPolygon{t.Hdr, polygons}
+}
+
 func marshalLinearRing(l *geom.LinearRing, sb *bytes.Buffer) {
 
 	sb.Write(lparen)
@@ -1186,3 +1186,49 @@ func TestEncodeGeometryCollection(t *testing.T) {
 		assert.Equal(t, expected.Geometries[2].(*geom.LineString).Coordinates[idx][1], c[1].(float64))
 	}
 }
+
+func TestEncodeSurfaces(t *testing.T) {
+	hdr := geom.Hdr{Dim: geom.XYZ, Srid: 4326}
+	t1 := []geom.Coordinate{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {0, 0, 0}}
+	t2 := []geom.Coordinate{{0, 0, 0}, {1, 1, 0}, {1, 0, 0}, {0, 0, 0}}
+	wall := []geom.Coordinate{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}, {0, 1, 0}, {0, 0, 0}}
+
+	datasets := []struct {
+		data     geom.Geometry
+		expected geom.Geometry
+	}{
+		{
+			&geom.Triangle{hdr, []geom.LinearRing{{t1}}},
+			&geom.Polygon{hdr, []geom.LinearRing{{t1}}},
+		},
+		{
+			&geom.TIN{hdr, []geom.Triangle{{hdr, []geom.LinearRing{{t1}}}, {hdr, []geom.LinearRing{{t2}}}}},
+			&geom.MultiPolygon{hdr, []geom.Polygon{{hdr, []geom.LinearRing{{t1}}}, {hdr, []geom.LinearRing{{t2}}}}},
+		},
+		{
+			&geom.PolyhedralSurface{hdr, []geom.Polygon{{hdr, []geom.LinearRing{{wall}}}}},
+			&geom.MultiPolygon{hdr, []geom.Polygon{{hdr, []geom.LinearRing{{wall}}}}},
+		},
+		{
+			&geom.GeometryCollection{hdr, []geom.Geometry{&geom.Triangle{hdr, []geom.LinearRing{{t1}}}}},
+			&geom.GeometryCollection{hdr, []geom.Geometry{&geom.Polygon{hdr, []geom.LinearRing{{t1}}}}},
+		},
+	}
+
+	for _, dataset := range datasets {
+		var sb bytes.Buffer
+		err := Encode(dataset.data, &sb)
+
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %s", dataset.data.Type(), err)
+		}
+
+		g, err := Decode(&sb)
+
+		if err != nil {
+			t.Fatalf("Failed to parse generated GeoJSON: error %s", err)
+		}
+
+		assert.Equal(t, dataset.expected, g)
+	}
+}
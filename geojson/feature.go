@@ -0,0 +1,466 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/devork/geom"
+)
+
+// snippets of geojson
+var (
+	featureHdr           = []byte(`{"type":"Feature"`)
+	featureCollectionHdr = []byte(`{"type":"FeatureCollection"`)
+	idKey                = []byte(`,"id":`)
+	bboxKey              = []byte(`,"bbox":`)
+	geometryKey          = []byte(`,"geometry":`)
+	propertiesKey        = []byte(`,"properties":`)
+	featuresKey          = []byte(`,"features":`)
+	null                 = []byte(`null`)
+)
+
+// Feature is a geometry together with its identifier and properties. A nil Geometry is written as an unlocated
+// feature and a nil ID is omitted. Numeric ids and property values are decoded as float64.
+type Feature struct {
+	ID         interface{}
+	Geometry   geom.Geometry
+	Properties map[string]interface{}
+	BBox       []float64
+}
+
+// FeatureCollection is an ordered list of features
+type FeatureCollection struct {
+	Features []Feature
+	BBox     []float64
+}
+
+// featureObject is the raw form of a GeoJSON feature object
+type featureObject struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id"`
+	Geometry   json.RawMessage        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+	BBox       []float64              `json:"bbox"`
+}
+
+// EncodeFeature writes the GeoJSON feature object to the specified writer
+func EncodeFeature(f *Feature, w io.Writer) error {
+	var sb bytes.Buffer
+
+	err := marshalFeature(f, &sb)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(sb.Bytes())
+
+	return err
+}
+
+// EncodeFeatureCollection writes the GeoJSON feature collection object to the specified writer. Each feature is
+// written as it is encoded, so the output is not buffered in full.
+func EncodeFeatureCollection(fc *FeatureCollection, w io.Writer) error {
+	fw := &FeatureWriter{w: w, bbox: fc.BBox}
+
+	for idx := range fc.Features {
+		err := fw.Write(&fc.Features[idx])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return fw.Close()
+}
+
+func marshalFeature(f *Feature, sb *bytes.Buffer) error {
+	sb.Write(featureHdr)
+
+	if f.ID != nil {
+		switch f.ID.(type) {
+		case string, float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		default:
+			return fmt.Errorf("geojson: feature id must be a string or number, but got %T", f.ID)
+		}
+
+		id, err := json.Marshal(f.ID)
+
+		if err != nil {
+			return fmt.Errorf("geojson: invalid feature id: %w", err)
+		}
+
+		sb.Write(idKey)
+		sb.Write(id)
+	}
+
+	if f.BBox != nil {
+		err := marshalBBox(f.BBox, sb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sb.Write(geometryKey)
+
+	if f.Geometry == nil {
+		sb.Write(null)
+	} else {
+		err := Encode(f.Geometry, sb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sb.Write(propertiesKey)
+
+	if f.Properties == nil {
+		sb.Write(null)
+	} else {
+		props, err := json.Marshal(f.Properties)
+
+		if err != nil {
+			return fmt.Errorf("geojson: invalid feature properties: %w", err)
+		}
+
+		sb.Write(props)
+	}
+
+	sb.Write(rbrace)
+
+	return nil
+}
+
+func marshalBBox(bbox []float64, sb *bytes.Buffer) error {
+	if len(bbox) < 4 || len(bbox)%2 != 0 {
+		return fmt.Errorf("geojson: bbox must have 2*n elements, but got %d", len(bbox))
+	}
+
+	sb.Write(bboxKey)
+	marshalCoord((*geom.Coordinate)(&bbox), sb)
+
+	return nil
+}
+
+// FeatureWriter streams features to a GeoJSON feature collection. The collection is completed by calling Close,
+// which does not close the underlying writer.
+type FeatureWriter struct {
+	w      io.Writer
+	bbox   []float64
+	count  int
+	closed bool
+}
+
+// NewFeatureWriter creates a FeatureWriter that writes a feature collection to w
+func NewFeatureWriter(w io.Writer) *FeatureWriter {
+	return &FeatureWriter{w: w}
+}
+
+// Write appends the feature to the collection
+func (fw *FeatureWriter) Write(f *Feature) error {
+	if fw.closed {
+		return fmt.Errorf("geojson: write to closed FeatureWriter")
+	}
+
+	var sb bytes.Buffer
+
+	if fw.count == 0 {
+		err := fw.marshalHdr(&sb)
+
+		if err != nil {
+			return err
+		}
+	} else {
+		sb.Write(comma)
+	}
+
+	err := marshalFeature(f, &sb)
+
+	if err != nil {
+		return err
+	}
+
+	fw.count++
+
+	_, err = fw.w.Write(sb.Bytes())
+
+	return err
+}
+
+// Close completes the feature collection
+func (fw *FeatureWriter) Close() error {
+	if fw.closed {
+		return nil
+	}
+
+	var sb bytes.Buffer
+
+	if fw.count == 0 {
+		err := fw.marshalHdr(&sb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sb.Write(rparen)
+	sb.Write(rbrace)
+
+	fw.closed = true
+
+	_, err := fw.w.Write(sb.Bytes())
+
+	return err
+}
+
+func (fw *FeatureWriter) marshalHdr(sb *bytes.Buffer) error {
+	sb.Write(featureCollectionHdr)
+
+	if fw.bbox != nil {
+		err := marshalBBox(fw.bbox, sb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	sb.Write(featuresKey)
+	sb.Write(lparen)
+
+	return nil
+}
+
+// DecodeFeature converts the GeoJSON feature object read from the given reader to a Feature
+func DecodeFeature(r io.Reader) (*Feature, error) {
+	var raw json.RawMessage
+
+	err := json.NewDecoder(r).Decode(&raw)
+
+	if err != nil {
+		return nil, fmt.Errorf("geojson: %w", err)
+	}
+
+	return unmarshalFeature(raw)
+}
+
+// DecodeFeatureCollection converts the GeoJSON feature collection object read from the given reader to a
+// FeatureCollection
+func DecodeFeatureCollection(r io.Reader) (*FeatureCollection, error) {
+	fr := NewFeatureReader(r)
+	fc := &FeatureCollection{Features: []Feature{}}
+
+	for {
+		f, err := fr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		fc.Features = append(fc.Features, *f)
+	}
+
+	fc.BBox = fr.BBox
+
+	return fc, nil
+}
+
+func unmarshalFeature(data json.RawMessage) (*Feature, error) {
+	var o featureObject
+
+	err := json.Unmarshal(data, &o)
+
+	if err != nil {
+		return nil, fmt.Errorf("geojson: invalid feature object: %w", err)
+	}
+
+	if o.Type != "Feature" {
+		return nil, fmt.Errorf("geojson: expected type Feature, but got %q", o.Type)
+	}
+
+	switch o.ID.(type) {
+	case nil, string, float64:
+	default:
+		return nil, fmt.Errorf("geojson: feature id must be a string or number")
+	}
+
+	f := &Feature{ID: o.ID, Properties: o.Properties, BBox: o.BBox}
+
+	if o.Geometry != nil && string(o.Geometry) != "null" {
+		f.Geometry, err = unmarshal(o.Geometry, &dims{})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// FeatureReader streams the features of a GeoJSON feature collection, decoding one feature per call to Next. Members
+// of the collection other than the features are read as they are encountered, so BBox is only guaranteed to be set
+// once Next has returned io.EOF.
+type FeatureReader struct {
+	dec     *json.Decoder
+	BBox    []float64
+	started bool
+	infeats bool
+	done    bool
+	isFC    bool
+}
+
+// NewFeatureReader creates a FeatureReader that reads a feature collection from r
+func NewFeatureReader(r io.Reader) *FeatureReader {
+	return &FeatureReader{dec: json.NewDecoder(r)}
+}
+
+// Next returns the next feature in the collection, or io.EOF once all features have been read
+func (fr *FeatureReader) Next() (*Feature, error) {
+	if fr.done {
+		return nil, io.EOF
+	}
+
+	if !fr.started {
+		err := fr.delim('{')
+
+		if err != nil {
+			return nil, err
+		}
+
+		fr.started = true
+	}
+
+	for {
+		if fr.infeats {
+			if fr.dec.More() {
+				var raw json.RawMessage
+
+				err := fr.dec.Decode(&raw)
+
+				if err != nil {
+					return nil, fmt.Errorf("geojson: %w", err)
+				}
+
+				return unmarshalFeature(raw)
+			}
+
+			err := fr.delim(']')
+
+			if err != nil {
+				return nil, err
+			}
+
+			fr.infeats = false
+		}
+
+		if !fr.dec.More() {
+			err := fr.delim('}')
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !fr.isFC {
+				return nil, fmt.Errorf("geojson: FeatureCollection is missing the type member")
+			}
+
+			fr.done = true
+
+			return nil, io.EOF
+		}
+
+		err := fr.member()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// member reads the next member of the feature collection object, stopping at the start of the features array
+func (fr *FeatureReader) member() error {
+	tok, err := fr.dec.Token()
+
+	if err != nil {
+		return fmt.Errorf("geojson: %w", err)
+	}
+
+	switch tok {
+	case "type":
+		var t string
+
+		err = fr.dec.Decode(&t)
+
+		if err != nil {
+			return fmt.Errorf("geojson: invalid type member: %w", err)
+		}
+
+		if t != "FeatureCollection" {
+			return fmt.Errorf("geojson: expected type FeatureCollection, but got %q", t)
+		}
+
+		fr.isFC = true
+	case "bbox":
+		err = fr.dec.Decode(&fr.BBox)
+
+		if err != nil {
+			return fmt.Errorf("geojson: invalid bbox member: %w", err)
+		}
+	case "features":
+		err = fr.delim('[')
+
+		if err != nil {
+			return err
+		}
+
+		fr.infeats = true
+	default:
+		// foreign members are ignored
+		var raw json.RawMessage
+
+		err = fr.dec.Decode(&raw)
+
+		if err != nil {
+			return fmt.Errorf("geojson: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (fr *FeatureReader) delim(d json.Delim) error {
+	tok, err := fr.dec.Token()
+
+	if err != nil {
+		return fmt.Errorf("geojson: %w", err)
+	}
+
+	if tok != d {
+		return fmt.Errorf("geojson: expected '%s' but got %v", d, tok)
+	}
+
+	return nil
+}
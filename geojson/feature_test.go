@@ -0,0 +1,239 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeFeature(t *testing.T) {
+	expected := &Feature{
+		ID: "parcel.1",
+		Geometry: &geom.Point{
+			geom.Hdr{Dim: geom.XY, Srid: 4326},
+			geom.Coordinate{-0.11834, 51.503475},
+		},
+		Properties: map[string]interface{}{"name": "County Hall", "floors": 6},
+		BBox:       []float64{-0.11834, 51.503475, -0.11834, 51.503475},
+	}
+
+	var sb bytes.Buffer
+	err := EncodeFeature(expected, &sb)
+
+	if err != nil {
+		t.Fatalf("failed to marshal feature: %s", err)
+	}
+
+	got := make(map[string]interface{})
+	err = json.Unmarshal(sb.Bytes(), &got)
+
+	if err != nil {
+		t.Fatalf("Failed to parse generated GeoJSON: error %s", err)
+	}
+
+	t.Logf("%s\n", string(sb.Bytes()))
+
+	assert.Equal(t, "Feature", got["type"])
+	assert.Equal(t, "parcel.1", got["id"])
+	assert.Equal(t, 4, len(got["bbox"].([]interface{})))
+	assert.Equal(t, "Point", got["geometry"].(map[string]interface{})["type"])
+	assert.Equal(t, map[string]interface{}{"name": "County Hall", "floors": float64(6)}, got["properties"])
+}
+
+func TestEncodeFeatureNulls(t *testing.T) {
+	var sb bytes.Buffer
+	err := EncodeFeature(&Feature{}, &sb)
+
+	if err != nil {
+		t.Fatalf("failed to marshal feature: %s", err)
+	}
+
+	assert.Equal(t, `{"type":"Feature","geometry":null,"properties":null}`, sb.String())
+
+	assert.Error(t, EncodeFeature(&Feature{ID: true}, &sb))
+	assert.Error(t, EncodeFeature(&Feature{BBox: []float64{1, 2, 3}}, &sb))
+}
+
+func TestEncodeFeatureCollection(t *testing.T) {
+	expected := &FeatureCollection{
+		Features: []Feature{
+			{ID: 1, Geometry: &geom.Point{geom.Hdr{Dim: geom.XY, Srid: 4326}, geom.Coordinate{4, 6}}},
+			{ID: 2, Geometry: &geom.LineString{geom.Hdr{Dim: geom.XY, Srid: 4326}, []geom.Coordinate{{4, 6}, {7, 10}}}},
+		},
+		BBox: []float64{4, 6, 7, 10},
+	}
+
+	var sb bytes.Buffer
+	err := EncodeFeatureCollection(expected, &sb)
+
+	if err != nil {
+		t.Fatalf("failed to marshal feature collection: %s", err)
+	}
+
+	got := make(map[string]interface{})
+	err = json.Unmarshal(sb.Bytes(), &got)
+
+	if err != nil {
+		t.Fatalf("Failed to parse generated GeoJSON: error %s", err)
+	}
+
+	t.Logf("%s\n", string(sb.Bytes()))
+
+	assert.Equal(t, "FeatureCollection", got["type"])
+	assert.Equal(t, []interface{}{4.0, 6.0, 7.0, 10.0}, got["bbox"])
+
+	features := got["features"].([]interface{})
+	assert.Equal(t, 2, len(features))
+	assert.Equal(t, 2.0, features[1].(map[string]interface{})["id"])
+
+	sb.Reset()
+	err = EncodeFeatureCollection(&FeatureCollection{}, &sb)
+
+	if err != nil {
+		t.Fatalf("failed to marshal feature collection: %s", err)
+	}
+
+	assert.Equal(t, `{"type":"FeatureCollection","features":[]}`, sb.String())
+}
+
+func TestDecodeFeature(t *testing.T) {
+	f, err := DecodeFeature(strings.NewReader(`{"type":"Feature","id":12,"geometry":{"type":"Point","coordinates":[4,6]},"properties":{"name":"a"}}`))
+
+	if err != nil {
+		t.Fatalf("failed to decode feature: %s", err)
+	}
+
+	assert.Equal(t, 12.0, f.ID)
+	assert.Equal(t, &geom.Point{geom.Hdr{geom.XY, 4326}, geom.Coordinate{4, 6}}, f.Geometry)
+	assert.Equal(t, map[string]interface{}{"name": "a"}, f.Properties)
+	assert.Nil(t, f.BBox)
+
+	f, err = DecodeFeature(strings.NewReader(`{"type":"Feature","geometry":null,"properties":null}`))
+
+	if err != nil {
+		t.Fatalf("failed to decode feature: %s", err)
+	}
+
+	assert.Nil(t, f.ID)
+	assert.Nil(t, f.Geometry)
+	assert.Nil(t, f.Properties)
+
+	datasets := []string{
+		`{"type":"Point","coordinates":[4,6]}`,
+		`{"type":"Feature","id":true,"geometry":null,"properties":null}`,
+		`{"type":"Feature","geometry":{"type":"Point"},"properties":null}`,
+		`[]`,
+	}
+
+	for _, dataset := range datasets {
+		_, err = DecodeFeature(strings.NewReader(dataset))
+
+		assert.Error(t, err, "Expected error decoding %q", dataset)
+	}
+
+	var serr *json.SyntaxError
+	_, err = DecodeFeature(strings.NewReader(`{"type":"Feature",}`))
+	assert.True(t, errors.As(err, &serr))
+}
+
+func TestDecodeFeatureCollection(t *testing.T) {
+	data := `{
+		"features": [
+			{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[4,6]},"properties":{}},
+			{"type":"Feature","id":"b","geometry":{"type":"LineString","coordinates":[[4,6,1],[7,10,2]]},"properties":null}
+		],
+		"name": "foreign member",
+		"type": "FeatureCollection",
+		"bbox": [4, 6, 7, 10]
+	}`
+
+	fc, err := DecodeFeatureCollection(strings.NewReader(data))
+
+	if err != nil {
+		t.Fatalf("failed to decode feature collection: %s", err)
+	}
+
+	assert.Equal(t, []float64{4, 6, 7, 10}, fc.BBox)
+	assert.Equal(t, 2, len(fc.Features))
+	assert.Equal(t, "b", fc.Features[1].ID)
+	assert.Equal(t, geom.XYZ, fc.Features[1].Geometry.Dimension())
+
+	datasets := []string{
+		`{"type":"Feature","features":[]}`,
+		`{"features":[]}`,
+		`{"type":"FeatureCollection","features":{}}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature"},`,
+	}
+
+	for _, dataset := range datasets {
+		_, err = DecodeFeatureCollection(strings.NewReader(dataset))
+
+		assert.Error(t, err, "Expected error decoding %q", dataset)
+	}
+}
+
+func TestFeatureStream(t *testing.T) {
+	var sb bytes.Buffer
+	fw := NewFeatureWriter(&sb)
+
+	for idx := 0; idx < 100; idx++ {
+		err := fw.Write(&Feature{
+			ID:         idx,
+			Geometry:   &geom.Point{geom.Hdr{Dim: geom.XY, Srid: 4326}, geom.Coordinate{float64(idx), float64(-idx)}},
+			Properties: map[string]interface{}{"idx": idx},
+		})
+
+		if err != nil {
+			t.Fatalf("failed to write feature: %s", err)
+		}
+	}
+
+	assert.Nil(t, fw.Close())
+	assert.Error(t, fw.Write(&Feature{}))
+
+	fr := NewFeatureReader(&sb)
+
+	var count int
+	for {
+		f, err := fr.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("failed to read feature: %s", err)
+		}
+
+		assert.Equal(t, float64(count), f.ID)
+		assert.Equal(t, geom.Coordinate{float64(count), float64(-count)}, f.Geometry.(*geom.Point).Coordinate)
+		count++
+	}
+
+	assert.Equal(t, 100, count)
+
+	_, err := fr.Next()
+	assert.Equal(t, io.EOF, err)
+}
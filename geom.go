@@ -23,9 +23,17 @@ import (
 
 // Common error types
 var (
-	ErrNoGeometry      = errors.New("no geometry specified")
-	ErrUnsupportedGeom = errors.New("cannot encode unknown geometry")
-	ErrUnknownDim      = errors.New("unknown dimension")
+	ErrNoGeometry           = errors.New("no geometry specified")
+	ErrUnsupportedGeom      = errors.New("cannot encode unknown geometry")
+	ErrUnknownDim           = errors.New("unknown dimension")
+	ErrInvalidArc           = errors.New("circular string must have an odd number of points")
+	ErrPattern              = errors.New("intersection matrix pattern must be 9 characters from T, F, *, 0, 1 and 2")
+	ErrFraction             = errors.New("densify fraction must be greater than 0 and at most 1")
+	ErrOutOfRange           = errors.New("location lies beyond the ends of the line")
+	ErrNoMeasure            = errors.New("geometry has no M values")
+	ErrEmptyGeometry        = errors.New("geometry is empty")
+	ErrTolerance            = errors.New("linearize tolerance must be a positive deviation or segment count")
+	ErrUnsupportedOperation = errors.New("operation not supported for geometry type")
 )
 
 type Encoder interface {
@@ -0,0 +1,280 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// Hulls are built from every vertex of a geometry, with arcs linearized with the default options first. The result
+// takes the SRID of g and drops any Z and M values. A Point is returned when all the vertices are the same and a
+// LineString when they lie on a line, and the hull of an empty geometry is an empty GeometryCollection. Each returns
+// ErrNoGeometry when g is nil.
+
+// ConvexHull returns the smallest convex polygon holding every point of g, with its shell counter-clockwise
+func ConvexHull(g Geometry) (Geometry, error) {
+	pts, err := hullPoints(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hullGeometry(g.SRID(), convexHull(pts)), nil
+}
+
+// ConcaveHull returns a polygon holding every point of g that follows the points more closely than the convex hull.
+// The edges of the Delaunay triangulation of the points longer than the given fraction of the way from the shortest
+// edge to the longest are cut away from the outside in, as long as the polygon keeps all of its points and stays in
+// one piece without holes. A ratio of 1 gives the convex hull and 0 the tightest hull.
+func ConcaveHull(g Geometry, ratio float64) (Geometry, error) {
+	return concaveHull(g, func(lengths []float64) float64 {
+		if ratio <= 0 {
+			return 0
+		}
+
+		lo, hi := math.Inf(1), 0.0
+
+		for _, l := range lengths {
+			lo, hi = math.Min(lo, l), math.Max(hi, l)
+		}
+
+		if ratio >= 1 {
+			return 2 * hi
+		}
+
+		return lo + ratio*(hi-lo)
+	})
+}
+
+// ConcaveHullByLength returns a concave hull as ConcaveHull does, cutting away the edges longer than length
+func ConcaveHullByLength(g Geometry, length float64) (Geometry, error) {
+	return concaveHull(g, func([]float64) float64 {
+		return length
+	})
+}
+
+// hullPoints returns the distinct vertices of g, sorted by X and then Y
+func hullPoints(g Geometry) ([]xy, error) {
+	o, err := newOperand(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pts := append([]xy{}, o.points...)
+
+	for _, l := range o.lines {
+		pts = append(pts, l...)
+	}
+
+	for _, polygon := range o.polygons {
+		for _, r := range polygon {
+			pts = append(pts, r...)
+		}
+	}
+
+	sort.Slice(pts, func(i, j int) bool {
+		return pts[i][0] < pts[j][0] || (pts[i][0] == pts[j][0] && pts[i][1] < pts[j][1])
+	})
+
+	out := pts[:0]
+
+	for _, p := range pts {
+		if len(out) == 0 || out[len(out)-1] != p {
+			out = append(out, p)
+		}
+	}
+
+	return out, nil
+}
+
+// convexHull returns the vertices of the convex hull of the sorted points, counter-clockwise from the first and
+// without the points along its edges
+func convexHull(pts []xy) []xy {
+	if len(pts) < 3 {
+		return append([]xy{}, pts...)
+	}
+
+	hull := make([]xy, 0, len(pts)+1)
+
+	// the lower chain from left to right, then the upper chain back
+	for _, p := range pts {
+		for len(hull) >= 2 && orient(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+
+		hull = append(hull, p)
+	}
+
+	lower := len(hull) + 1
+
+	for i := len(pts) - 2; i >= 0; i-- {
+		for len(hull) >= lower && orient(hull[len(hull)-2], hull[len(hull)-1], pts[i]) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+
+		hull = append(hull, pts[i])
+	}
+
+	return hull[:len(hull)-1]
+}
+
+// hullGeometry returns the geometry with the hull as its shell, or a point or line when the hull has collapsed
+func hullGeometry(srid uint32, hull []xy) Geometry {
+	hdr := Hdr{XY, srid}
+	coords := make([]Coordinate, len(hull), len(hull)+1)
+
+	for i, p := range hull {
+		coords[i] = Coordinate{p[0], p[1]}
+	}
+
+	switch len(hull) {
+	case 0:
+		return &GeometryCollection{hdr, nil}
+	case 1:
+		return &Point{hdr, coords[0]}
+	case 2:
+		return &LineString{hdr, coords}
+	default:
+		return &Polygon{hdr, []LinearRing{{append(coords, coords[0])}}}
+	}
+}
+
+// borderTriangle is a triangle on the border of a concave hull, with the length of its border edge
+type borderTriangle struct {
+	tri    int
+	length float64
+}
+
+type borderHeap []borderTriangle
+
+func (h borderHeap) Len() int            { return len(h) }
+func (h borderHeap) Less(i, j int) bool  { return h[i].length > h[j].length }
+func (h borderHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *borderHeap) Push(x interface{}) { *h = append(*h, x.(borderTriangle)) }
+func (h *borderHeap) Pop() interface{} {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return v
+}
+
+// concaveHull triangulates the points of g and removes the triangles on the border with an edge longer than the
+// threshold, the longest first. A triangle is only removed when it has a single edge on the border and its third
+// point is not already on the border, so that no point is lost and the hull stays a simple polygon.
+func concaveHull(g Geometry, threshold func(lengths []float64) float64) (Geometry, error) {
+	pts, err := hullPoints(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := triangulate(pts)
+
+	if len(t.triangles) == 0 {
+		return hullGeometry(g.SRID(), convexHull(pts)), nil
+	}
+
+	next := func(e int) int {
+		return e - e%3 + (e+1)%3
+	}
+
+	lengths := make([]float64, len(t.triangles))
+
+	for e := range t.triangles {
+		lengths[e] = math.Sqrt(dist2(pts[t.triangles[e]], pts[t.triangles[next(e)]]))
+	}
+
+	max := threshold(lengths)
+	removed := make([]bool, len(t.triangles)/3)
+	border := make([]bool, len(pts))
+
+	isBorder := func(e int) bool {
+		return t.halfedges[e] == -1 || removed[t.halfedges[e]/3]
+	}
+
+	// borderEdge returns the single border edge of the triangle, or -1 when it has none or several
+	borderEdge := func(tri int) int {
+		edge := -1
+
+		for e := 3 * tri; e < 3*tri+3; e++ {
+			if isBorder(e) {
+				if edge >= 0 {
+					return -1
+				}
+
+				edge = e
+			}
+		}
+
+		return edge
+	}
+
+	h := &borderHeap{}
+
+	push := func(tri int) {
+		if e := borderEdge(tri); e >= 0 && lengths[e] > max {
+			heap.Push(h, borderTriangle{tri, lengths[e]})
+		}
+	}
+
+	for e := range t.triangles {
+		if t.halfedges[e] == -1 {
+			border[t.triangles[e]] = true
+			push(e / 3)
+		}
+	}
+
+	for h.Len() > 0 {
+		tri := heap.Pop(h).(borderTriangle).tri
+
+		if removed[tri] {
+			continue
+		}
+
+		e := borderEdge(tri)
+
+		if e < 0 || border[t.triangles[next(next(e))]] {
+			continue
+		}
+
+		removed[tri] = true
+		border[t.triangles[next(next(e))]] = true
+
+		for f := 3 * tri; f < 3*tri+3; f++ {
+			if o := t.halfedges[f]; o != -1 && !removed[o/3] {
+				push(o / 3)
+			}
+		}
+	}
+
+	// the triangles turn clockwise, so their border edges run back to have the hull on their left
+	var edges [][2]xy
+
+	for e := range t.triangles {
+		if !removed[e/3] && isBorder(e) {
+			edges = append(edges, [2]xy{pts[t.triangles[next(e)]], pts[t.triangles[e]]})
+		}
+	}
+
+	shell := buildPolygons(edges)[0][0]
+
+	return hullGeometry(g.SRID(), shell[:len(shell)-1]), nil
+}
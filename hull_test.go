@@ -0,0 +1,109 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func multiPoint(coords ...Coordinate) *MultiPoint {
+	mp := &MultiPoint{Hdr: Hdr{XY, 0}}
+
+	for _, c := range coords {
+		mp.Points = append(mp.Points, Point{Hdr{XY, 0}, c})
+	}
+
+	return mp
+}
+
+// uPoints is a grid of points with a notch cut down into it from the top
+func uPoints() *MultiPoint {
+	mp := &MultiPoint{Hdr: Hdr{XY, 0}}
+
+	for x := 0; x <= 4; x++ {
+		for y := 0; y <= 4; y++ {
+			if x != 2 || y < 2 {
+				mp.Points = append(mp.Points, Point{Hdr{XY, 0}, Coordinate{float64(x), float64(y)}})
+			}
+		}
+	}
+
+	return mp
+}
+
+func TestConvexHull(t *testing.T) {
+	datasets := []struct {
+		name     string
+		g        Geometry
+		expected Geometry
+	}{
+		{"points", multiPoint(Coordinate{0, 0}, Coordinate{2, 0}, Coordinate{1, 1}, Coordinate{2, 2}, Coordinate{0, 2}, Coordinate{1, 0}), box(0, 0, 2, 2)},
+		{"polygon", ushape, box(0, 0, 3, 3)},
+		{"line", &LineString{Hdr{XY, 4326}, []Coordinate{{0, 0}, {1, 1}, {3, 3}}}, &LineString{Hdr{XY, 4326}, []Coordinate{{0, 0}, {3, 3}}}},
+		{"point", multiPoint(Coordinate{1, 2}, Coordinate{1, 2}), &Point{Hdr{XY, 0}, Coordinate{1, 2}}},
+		{"empty", multiPoint(), &GeometryCollection{Hdr{XY, 0}, nil}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := ConvexHull(dataset.g)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	_, err := ConvexHull(nil)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestConcaveHull(t *testing.T) {
+	notched := &Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 2}, {0, 1}, {0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {4, 1},
+		{4, 2}, {4, 3}, {4, 4}, {3, 4}, {3, 3}, {3, 2}, {2, 1}, {1, 2}, {1, 3}, {1, 4}, {0, 4}, {0, 3}, {0, 2}}}}}
+
+	datasets := []struct {
+		name string
+		hull func(Geometry) (Geometry, error)
+		area float64
+	}{
+		{"ratio 0", func(g Geometry) (Geometry, error) { return ConcaveHull(g, 0) }, 10},
+		{"ratio 0.5", func(g Geometry) (Geometry, error) { return ConcaveHull(g, 0.5) }, 11},
+		{"ratio 1", func(g Geometry) (Geometry, error) { return ConcaveHull(g, 1) }, 16},
+		{"length", func(g Geometry) (Geometry, error) { return ConcaveHullByLength(g, 1.5) }, 11},
+	}
+
+	for _, dataset := range datasets {
+		g, err := dataset.hull(uPoints())
+		assert.NoError(t, err, dataset.name)
+		assert.NoError(t, ValidationReason(g), dataset.name)
+		assert.Equal(t, dataset.area, Area(g), dataset.name)
+
+		covers, err := Covers(g, uPoints())
+		assert.NoError(t, err, dataset.name)
+		assert.True(t, covers, dataset.name)
+	}
+
+	g, err := ConcaveHullByLength(uPoints(), 1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, notched, g)
+
+	g, err = ConcaveHull(multiPoint(Coordinate{0, 0}, Coordinate{1, 1}, Coordinate{2, 2}), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {2, 2}}}, g)
+
+	_, err = ConcaveHull(nil, 0)
+	assert.Equal(t, ErrNoGeometry, err)
+}
@@ -0,0 +1,545 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+)
+
+// DefaultSegmentsPerQuadrant is the number of segments used for each quarter circle of an arc when no tolerance is
+// given, matching Postgis
+const DefaultSegmentsPerQuadrant = 32
+
+// MaxSegmentsPerQuadrant caps the number of segments used for each quarter circle of an arc, however small the
+// tolerance or large the radius
+const MaxSegmentsPerQuadrant = 1024
+
+// arcs must be made of at least this many equal segments to be detected, with at least two segments per quadrant,
+// so that ordinary polylines are left alone
+const (
+	minArcSegments = 4
+	maxArcStep     = math.Pi/4 + 1e-9
+)
+
+// linearizer holds the tolerance used to approximate arcs with straight segments
+type linearizer struct {
+	deviation float64
+	segments  int
+}
+
+// LinearizeOption configures the tolerance used by Linearize
+type LinearizeOption func(*linearizer)
+
+// WithMaxDeviation approximates arcs so no point on the arc is further than d from the segments replacing it, using
+// no more than MaxSegmentsPerQuadrant segments for each quarter circle. Linearize returns ErrTolerance unless d is
+// greater than 0.
+func WithMaxDeviation(d float64) LinearizeOption {
+	return func(l *linearizer) {
+		l.deviation = d
+		l.segments = 0
+	}
+}
+
+// WithSegmentsPerQuadrant approximates arcs using n segments for every quarter circle, up to MaxSegmentsPerQuadrant.
+// Linearize returns ErrTolerance unless n is at least 1.
+func WithSegmentsPerQuadrant(n int) LinearizeOption {
+	return func(l *linearizer) {
+		l.segments = n
+		l.deviation = 0
+	}
+}
+
+// Linearize converts the circular arcs in g into straight segments. A CircularString or CompoundCurve becomes a
+// LineString, a CurvePolygon a Polygon, a MultiCurve a MultiLineString and a MultiSurface a MultiPolygon. The members
+// of a GeometryCollection are converted in turn and any other geometry is returned unchanged. Z and M values are
+// interpolated along each arc. A CompoundCurve or MultiSurface with a member of an unexpected type fails with
+// ErrUnsupportedOperation.
+func Linearize(g Geometry, opts ...LinearizeOption) (Geometry, error) {
+	l := &linearizer{segments: DefaultSegmentsPerQuadrant}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.segments < 1 && !(l.deviation > 0) {
+		return nil, ErrTolerance
+	}
+
+	return l.linearize(g)
+}
+
+func (l *linearizer) linearize(g Geometry) (Geometry, error) {
+	switch g := g.(type) {
+	case nil:
+		return nil, ErrNoGeometry
+	case *CircularString, *CompoundCurve:
+		coords, err := l.curve(g)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &LineString{Hdr{g.Dimension(), g.SRID()}, coords}, nil
+	case *CurvePolygon:
+		return l.polygon(g)
+	case *MultiCurve:
+		lstrings := make([]LineString, 0, len(g.Curves))
+
+		for _, c := range g.Curves {
+			coords, err := l.curve(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			lstrings = append(lstrings, LineString{Hdr{c.Dimension(), c.SRID()}, coords})
+		}
+
+		return &MultiLineString{g.Hdr, lstrings}, nil
+	case *MultiSurface:
+		polygons := make([]Polygon, 0, len(g.Surfaces))
+
+		for _, s := range g.Surfaces {
+			switch s := s.(type) {
+			case *Polygon:
+				polygons = append(polygons, *s)
+			case *CurvePolygon:
+				p, err := l.polygon(s)
+
+				if err != nil {
+					return nil, err
+				}
+
+				polygons = append(polygons, *p)
+			default:
+				return nil, ErrUnsupportedOperation
+			}
+		}
+
+		return &MultiPolygon{g.Hdr, polygons}, nil
+	case *GeometryCollection:
+		geoms := make([]Geometry, 0, len(g.Geometries))
+
+		for _, m := range g.Geometries {
+			lg, err := l.linearize(m)
+
+			if err != nil {
+				return nil, err
+			}
+
+			geoms = append(geoms, lg)
+		}
+
+		return &GeometryCollection{g.Hdr, geoms}, nil
+	default:
+		return g, nil
+	}
+}
+
+func (l *linearizer) polygon(p *CurvePolygon) (*Polygon, error) {
+	rings := make([]LinearRing, 0, len(p.Rings))
+
+	for _, r := range p.Rings {
+		coords, err := l.curve(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rings = append(rings, LinearRing{coords})
+	}
+
+	return &Polygon{p.Hdr, rings}, nil
+}
+
+// curve returns the coordinates approximating a LineString, CircularString or CompoundCurve
+func (l *linearizer) curve(g Geometry) ([]Coordinate, error) {
+	switch g := g.(type) {
+	case *LineString:
+		return g.Coordinates, nil
+	case *CircularString:
+		return l.circularString(g.Coordinates)
+	case *CompoundCurve:
+		var coords []Coordinate
+
+		for _, c := range g.Curves {
+			seg, err := l.curve(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			// each segment starts where the previous one ended
+			if len(coords) > 0 && len(seg) > 0 && equal2D(coords[len(coords)-1], seg[0]) {
+				seg = seg[1:]
+			}
+
+			coords = append(coords, seg...)
+		}
+
+		return coords, nil
+	default:
+		return nil, ErrUnsupportedOperation
+	}
+}
+
+func (l *linearizer) circularString(coords []Coordinate) ([]Coordinate, error) {
+	if len(coords) == 0 {
+		return nil, nil
+	}
+
+	if len(coords) < 3 || len(coords)%2 == 0 {
+		return nil, ErrInvalidArc
+	}
+
+	out := []Coordinate{coords[0]}
+
+	for idx := 0; idx+2 < len(coords); idx += 2 {
+		out = append(out, l.arc(coords[idx], coords[idx+1], coords[idx+2])...)
+	}
+
+	return out, nil
+}
+
+// step returns the angle subtended by each segment approximating an arc of radius r, never less than a quarter
+// circle split into MaxSegmentsPerQuadrant
+func (l *linearizer) step(r float64) float64 {
+	const minStep = math.Pi / 2 / MaxSegmentsPerQuadrant
+
+	if l.segments > 0 {
+		return math.Max(math.Pi/2/float64(l.segments), minStep)
+	}
+
+	// the sagitta of a chord subtending the angle a is r(1 - cos(a/2)), which rounds to 0 once the deviation is
+	// below the precision of the radius
+	if l.deviation >= r {
+		return math.Pi / 2
+	}
+
+	return math.Max(math.Min(2*math.Acos(1-l.deviation/r), math.Pi/2), minStep)
+}
+
+// arc returns the points approximating the arc from p0 through p1 to p2, excluding p0
+func (l *linearizer) arc(p0, p1, p2 Coordinate) []Coordinate {
+	if equal2D(p0, p1) && equal2D(p1, p2) {
+		return []Coordinate{p2}
+	}
+
+	ca, ok := newArc(p0, p1, p2)
+
+	if !ok {
+		return []Coordinate{p1, p2}
+	}
+
+	n := int(math.Ceil(math.Abs(ca.sweep) / l.step(ca.r)))
+
+	out := make([]Coordinate, 0, n)
+
+	for idx := 1; idx < n; idx++ {
+		t := ca.sweep * float64(idx) / float64(n)
+
+		c := make(Coordinate, len(p0))
+		c[0] = ca.cx + ca.r*math.Cos(ca.a0+t)
+		c[1] = ca.cy + ca.r*math.Sin(ca.a0+t)
+
+		// Z and M are interpolated by angle either side of the intermediate point
+		if math.Abs(t) <= math.Abs(ca.sweep1) {
+			lerp(c, p0, p1, t/ca.sweep1)
+		} else {
+			lerp(c, p1, p2, (t-ca.sweep1)/(ca.sweep-ca.sweep1))
+		}
+
+		out = append(out, c)
+	}
+
+	return append(out, p2)
+}
+
+// circularArc is the circle and angles of an arc. The sweeps are signed, positive for counter-clockwise arcs, and
+// measured from the start angle a0 to the end and intermediate points.
+type circularArc struct {
+	cx, cy, r     float64
+	a0            float64
+	sweep, sweep1 float64
+}
+
+// newArc returns the arc from p0 through p1 to p2, failing when the points are collinear. When p0 and p2 are the
+// same point the arc is the full circle with p1 diametrically opposite, taken counter-clockwise.
+func newArc(p0, p1, p2 Coordinate) (circularArc, bool) {
+	var ca circularArc
+
+	if equal2D(p0, p2) {
+		if equal2D(p0, p1) {
+			return ca, false
+		}
+
+		ca.cx, ca.cy = (p0[0]+p1[0])/2, (p0[1]+p1[1])/2
+		ca.r = math.Hypot(p0[0]-ca.cx, p0[1]-ca.cy)
+		ca.a0 = math.Atan2(p0[1]-ca.cy, p0[0]-ca.cx)
+		ca.sweep, ca.sweep1 = 2*math.Pi, math.Pi
+
+		return ca, true
+	}
+
+	var ok bool
+	ca.cx, ca.cy, ca.r, ok = circle(p0, p1, p2)
+
+	if !ok {
+		return ca, false
+	}
+
+	ca.a0 = math.Atan2(p0[1]-ca.cy, p0[0]-ca.cx)
+	a1 := math.Atan2(p1[1]-ca.cy, p1[0]-ca.cx)
+	a2 := math.Atan2(p2[1]-ca.cy, p2[0]-ca.cx)
+
+	if orientation(p0, p1, p2) > 0 {
+		ca.sweep1, ca.sweep = ccwAngle(ca.a0, a1), ccwAngle(ca.a0, a2)
+	} else {
+		ca.sweep1, ca.sweep = -ccwAngle(a1, ca.a0), -ccwAngle(a2, ca.a0)
+	}
+
+	return ca, true
+}
+
+// DetectArcs is the inverse of Linearize, rebuilding circular arcs from runs of at least four equal segments whose
+// vertices lie within tolerance of a circle. A LineString with arcs becomes a CircularString or CompoundCurve, a
+// Polygon a CurvePolygon, a MultiLineString a MultiCurve and a MultiPolygon a MultiSurface. Geometries without any
+// arcs are returned unchanged.
+func DetectArcs(g Geometry, tolerance float64) (Geometry, error) {
+	switch g := g.(type) {
+	case nil:
+		return nil, ErrNoGeometry
+	case *LineString:
+		return detectArcs(g.Hdr, g.Coordinates, tolerance), nil
+	case *Polygon:
+		if p, ok := detectPolygon(g, tolerance); ok {
+			return p, nil
+		}
+
+		return g, nil
+	case *MultiLineString:
+		curves := make([]Geometry, 0, len(g.LineStrings))
+		found := false
+
+		for _, ls := range g.LineStrings {
+			c := detectArcs(ls.Hdr, ls.Coordinates, tolerance)
+			_, straight := c.(*LineString)
+			found = found || !straight
+			curves = append(curves, c)
+		}
+
+		if !found {
+			return g, nil
+		}
+
+		return &MultiCurve{g.Hdr, curves}, nil
+	case *MultiPolygon:
+		surfaces := make([]Geometry, 0, len(g.Polygons))
+		found := false
+
+		for idx := range g.Polygons {
+			p := &g.Polygons[idx]
+
+			if cp, ok := detectPolygon(p, tolerance); ok {
+				surfaces = append(surfaces, cp)
+				found = true
+				continue
+			}
+
+			surfaces = append(surfaces, p)
+		}
+
+		if !found {
+			return g, nil
+		}
+
+		return &MultiSurface{g.Hdr, surfaces}, nil
+	case *GeometryCollection:
+		geoms := make([]Geometry, 0, len(g.Geometries))
+
+		for _, m := range g.Geometries {
+			dg, err := DetectArcs(m, tolerance)
+
+			if err != nil {
+				return nil, err
+			}
+
+			geoms = append(geoms, dg)
+		}
+
+		return &GeometryCollection{g.Hdr, geoms}, nil
+	default:
+		return g, nil
+	}
+}
+
+// detectPolygon returns p as a CurvePolygon if any of its rings contain an arc
+func detectPolygon(p *Polygon, tolerance float64) (*CurvePolygon, bool) {
+	rings := make([]Geometry, 0, len(p.Rings))
+	found := false
+
+	for _, r := range p.Rings {
+		c := detectArcs(p.Hdr, r.Coordinates, tolerance)
+		_, straight := c.(*LineString)
+		found = found || !straight
+		rings = append(rings, c)
+	}
+
+	return &CurvePolygon{p.Hdr, rings}, found
+}
+
+// detectArcs splits coords into straight runs and arcs, returning a LineString when there are no arcs, a
+// CircularString when the whole line is one arc, or a CompoundCurve
+func detectArcs(hdr Hdr, coords []Coordinate, tolerance float64) Geometry {
+	var curves []Geometry
+	start := 0
+
+	for idx := 0; idx+minArcSegments < len(coords); {
+		end := arcEnd(coords, idx, tolerance)
+
+		if end-idx < minArcSegments {
+			idx++
+			continue
+		}
+
+		if idx > start {
+			curves = append(curves, &LineString{hdr, coords[start : idx+1]})
+		}
+
+		// the segments are equal, so the middle vertex lies halfway round the arc
+		mid := (idx + end) / 2
+		curves = append(curves, &CircularString{hdr, []Coordinate{coords[idx], coords[mid], coords[end]}})
+
+		idx, start = end, end
+	}
+
+	if len(curves) == 0 {
+		return &LineString{hdr, coords}
+	}
+
+	if start < len(coords)-1 {
+		curves = append(curves, &LineString{hdr, coords[start:]})
+	}
+
+	if len(curves) == 1 {
+		return curves[0]
+	}
+
+	return &CompoundCurve{hdr, curves}
+}
+
+// arcEnd returns the index of the last vertex of the arc starting at coords[start], or start when the vertices that
+// follow do not form an arc
+func arcEnd(coords []Coordinate, start int, tolerance float64) int {
+	cx, cy, r, ok := circle(coords[start], coords[start+1], coords[start+2])
+
+	if !ok {
+		return start
+	}
+
+	angle := func(c Coordinate) float64 {
+		return math.Atan2(c[1]-cy, c[0]-cx)
+	}
+
+	step0 := normAngle(angle(coords[start+1]) - angle(coords[start]))
+
+	if math.Abs(step0) > maxArcStep {
+		return start
+	}
+
+	var total float64
+	end := start
+
+	for ; end+1 < len(coords); end++ {
+		c := coords[end+1]
+
+		if math.Abs(math.Hypot(c[0]-cx, c[1]-cy)-r) > tolerance {
+			break
+		}
+
+		step := normAngle(angle(c) - angle(coords[end]))
+
+		if math.Abs(step-step0)*r > tolerance || math.Abs(total+step) > 2*math.Pi+1e-9 {
+			break
+		}
+
+		total += step
+	}
+
+	return end
+}
+
+// circle returns the centre and radius of the circle through three points, failing when they are collinear
+func circle(p0, p1, p2 Coordinate) (cx, cy, r float64, ok bool) {
+	ax, ay := p0[0], p0[1]
+	bx, by := p1[0], p1[1]
+	qx, qy := p2[0], p2[1]
+
+	d := 2 * (ax*(by-qy) + bx*(qy-ay) + qx*(ay-by))
+
+	if d == 0 {
+		return 0, 0, 0, false
+	}
+
+	a2, b2, q2 := ax*ax+ay*ay, bx*bx+by*by, qx*qx+qy*qy
+	cx = (a2*(by-qy) + b2*(qy-ay) + q2*(ay-by)) / d
+	cy = (a2*(qx-bx) + b2*(ax-qx) + q2*(bx-ax)) / d
+
+	r = math.Hypot(ax-cx, ay-cy)
+
+	return cx, cy, r, !math.IsInf(r, 0) && !math.IsNaN(r)
+}
+
+// orientation is positive when p0, p1, p2 turn counter-clockwise, negative when clockwise and zero when collinear
+func orientation(p0, p1, p2 Coordinate) float64 {
+	return (p1[0]-p0[0])*(p2[1]-p0[1]) - (p1[1]-p0[1])*(p2[0]-p0[0])
+}
+
+// ccwAngle returns the counter-clockwise angle from a to b in [0, 2π)
+func ccwAngle(a, b float64) float64 {
+	d := math.Mod(b-a, 2*math.Pi)
+
+	if d < 0 {
+		d += 2 * math.Pi
+	}
+
+	return d
+}
+
+// normAngle normalises a to (-π, π]
+func normAngle(a float64) float64 {
+	for a <= -math.Pi {
+		a += 2 * math.Pi
+	}
+
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+
+	return a
+}
+
+// lerp sets the ordinates of c beyond X and Y by interpolating between a and b
+func lerp(c, a, b Coordinate, t float64) {
+	for idx := 2; idx < len(c) && idx < len(a) && idx < len(b); idx++ {
+		c[idx] = a[idx] + t*(b[idx]-a[idx])
+	}
+}
+
+func equal2D(a, b Coordinate) bool {
+	return a[0] == b[0] && a[1] == b[1]
+}
@@ -0,0 +1,242 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertCoords(t *testing.T, expected, actual []Coordinate) {
+	t.Helper()
+
+	if !assert.Equal(t, len(expected), len(actual), "coordinate count") {
+		return
+	}
+
+	for idx := range expected {
+		assert.InDeltaSlice(t, expected[idx], actual[idx], 1e-9, "coordinate %d", idx)
+	}
+}
+
+func TestLinearize(t *testing.T) {
+	h := math.Sqrt2 / 2
+	semi := []Coordinate{{-1, 0}, {-h, h}, {0, 1}, {h, h}, {1, 0}}
+
+	datasets := []struct {
+		name     string
+		data     Geometry
+		opts     []LinearizeOption
+		expected []Coordinate
+	}{
+		{
+			"segments per quadrant",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}},
+			[]LinearizeOption{WithSegmentsPerQuadrant(2)},
+			semi,
+		},
+		{
+			"max deviation",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}},
+			[]LinearizeOption{WithMaxDeviation(0.08)},
+			semi,
+		},
+		{
+			"counter-clockwise",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{1, 0}, {0, 1}, {-1, 0}}},
+			[]LinearizeOption{WithSegmentsPerQuadrant(2)},
+			[]Coordinate{{1, 0}, {h, h}, {0, 1}, {-h, h}, {-1, 0}},
+		},
+		{
+			"full circle",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{1, 0}, {-1, 0}, {1, 0}}},
+			[]LinearizeOption{WithSegmentsPerQuadrant(1)},
+			[]Coordinate{{1, 0}, {0, 1}, {-1, 0}, {0, -1}, {1, 0}},
+		},
+		{
+			"interpolated z",
+			&CircularString{Hdr{XYZ, 0}, []Coordinate{{-1, 0, 0}, {0, 1, 5}, {1, 0, 10}}},
+			[]LinearizeOption{WithSegmentsPerQuadrant(2)},
+			[]Coordinate{{-1, 0, 0}, {-h, h, 2.5}, {0, 1, 5}, {h, h, 7.5}, {1, 0, 10}},
+		},
+		{
+			"collinear",
+			&CircularString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}, {2, 2}}},
+			nil,
+			[]Coordinate{{0, 0}, {1, 1}, {2, 2}},
+		},
+		{
+			"compound curve",
+			&CompoundCurve{Hdr{XY, 0}, []Geometry{
+				&LineString{Hdr{XY, 0}, []Coordinate{{-2, 0}, {-1, 0}}},
+				&CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}},
+			}},
+			[]LinearizeOption{WithSegmentsPerQuadrant(2)},
+			append([]Coordinate{{-2, 0}}, semi...),
+		},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Linearize(dataset.data, dataset.opts...)
+
+		if err != nil {
+			t.Fatalf("Failed to linearize %s: err = %s", dataset.name, err)
+		}
+
+		ls, ok := g.(*LineString)
+
+		if !assert.True(t, ok, dataset.name) {
+			continue
+		}
+
+		assert.Equal(t, dataset.data.Dimension(), ls.Dimension(), dataset.name)
+		assertCoords(t, dataset.expected, ls.Coordinates)
+	}
+}
+
+func TestLinearizeTypes(t *testing.T) {
+	circle := &CircularString{Hdr{XY, 4326}, []Coordinate{{1, 0}, {-1, 0}, {1, 0}}}
+	square := &LineString{Hdr{XY, 4326}, []Coordinate{{-2, -2}, {2, -2}, {2, 2}, {-2, 2}, {-2, -2}}}
+
+	g, err := Linearize(&CurvePolygon{Hdr{XY, 4326}, []Geometry{square, circle}})
+	assert.Nil(t, err)
+	assert.Equal(t, "polygon", g.Type())
+	assert.Equal(t, uint32(4326), g.SRID())
+	assert.Equal(t, 2, len(g.(*Polygon).Rings))
+	assert.Equal(t, 4*DefaultSegmentsPerQuadrant+1, len(g.(*Polygon).Rings[1].Coordinates))
+
+	g, err = Linearize(&MultiCurve{Hdr{XY, 4326}, []Geometry{square, circle}})
+	assert.Nil(t, err)
+	assert.Equal(t, "multilinestring", g.Type())
+
+	g, err = Linearize(&MultiSurface{Hdr{XY, 4326}, []Geometry{&CurvePolygon{Hdr{XY, 4326}, []Geometry{circle}}}})
+	assert.Nil(t, err)
+	assert.Equal(t, "multipolygon", g.Type())
+
+	g, err = Linearize(&GeometryCollection{Hdr{XY, 4326}, []Geometry{circle, square}})
+	assert.Nil(t, err)
+	assert.Equal(t, "linestring", g.(*GeometryCollection).Geometries[0].Type())
+	assert.Equal(t, square, g.(*GeometryCollection).Geometries[1])
+
+	// geometries without arcs are returned as they are
+	g, err = Linearize(square)
+	assert.Nil(t, err)
+	assert.Equal(t, square, g)
+
+	_, err = Linearize(&CircularString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}}})
+	assert.Equal(t, ErrInvalidArc, err)
+
+	_, err = Linearize(&CompoundCurve{Hdr{XY, 0}, []Geometry{&Point{Hdr{XY, 0}, Coordinate{0, 0}}}})
+	assert.Equal(t, ErrUnsupportedOperation, err)
+
+	_, err = Linearize(nil)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestLinearizeTolerance(t *testing.T) {
+	semicircle := &CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}}
+	// nearly collinear, with a radius of about 1e12
+	flat := &CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1e-12}, {1, 0}}}
+
+	datasets := []struct {
+		name     string
+		g        Geometry
+		opt      LinearizeOption
+		expected int
+	}{
+		{"deviation below precision", semicircle, WithMaxDeviation(1e-17), 2*MaxSegmentsPerQuadrant + 1},
+		{"fine deviation", semicircle, WithMaxDeviation(1e-9), 2*MaxSegmentsPerQuadrant + 1},
+		{"large radius", flat, WithMaxDeviation(1e-3), 2},
+		{"large radius fine deviation", flat, WithMaxDeviation(1e-30), 2},
+		{"segments capped", semicircle, WithSegmentsPerQuadrant(1 << 30), 2*MaxSegmentsPerQuadrant + 1},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Linearize(dataset.g, dataset.opt)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, len(g.(*LineString).Coordinates), dataset.name)
+	}
+
+	for _, opt := range []LinearizeOption{WithMaxDeviation(0), WithMaxDeviation(-1), WithMaxDeviation(math.NaN()),
+		WithSegmentsPerQuadrant(0), WithSegmentsPerQuadrant(-2)} {
+		_, err := Linearize(semicircle, opt)
+		assert.Equal(t, ErrTolerance, err)
+	}
+}
+
+func TestDetectArcs(t *testing.T) {
+	curve := &CompoundCurve{Hdr{XY, 0}, []Geometry{
+		&LineString{Hdr{XY, 0}, []Coordinate{{-3, 0}, {-1, 0}}},
+		&CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}},
+		&LineString{Hdr{XY, 0}, []Coordinate{{1, 0}, {3, 0}}},
+	}}
+
+	lg, err := Linearize(curve, WithSegmentsPerQuadrant(8))
+
+	if err != nil {
+		t.Fatalf("Failed to linearize: err = %s", err)
+	}
+
+	g, err := DetectArcs(lg, 1e-9)
+
+	if err != nil {
+		t.Fatalf("Failed to detect arcs: err = %s", err)
+	}
+
+	cc, ok := g.(*CompoundCurve)
+
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, 3, len(cc.Curves))
+	assertCoords(t, []Coordinate{{-3, 0}, {-1, 0}}, cc.Curves[0].(*LineString).Coordinates)
+	assertCoords(t, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}, cc.Curves[1].(*CircularString).Coordinates)
+	assertCoords(t, []Coordinate{{1, 0}, {3, 0}}, cc.Curves[2].(*LineString).Coordinates)
+
+	// a circular hole is rebuilt as a full circle
+	poly, _ := Linearize(&CurvePolygon{Hdr{XY, 0}, []Geometry{
+		&LineString{Hdr{XY, 0}, []Coordinate{{-2, -2}, {2, -2}, {2, 2}, {-2, 2}, {-2, -2}}},
+		&CircularString{Hdr{XY, 0}, []Coordinate{{1, 0}, {-1, 0}, {1, 0}}},
+	}})
+
+	g, err = DetectArcs(poly, 1e-9)
+	assert.Nil(t, err)
+
+	cp := g.(*CurvePolygon)
+	assert.Equal(t, "linestring", cp.Rings[0].Type())
+	assertCoords(t, []Coordinate{{1, 0}, {-1, 0}, {1, 0}}, cp.Rings[1].(*CircularString).Coordinates)
+
+	// zig-zags and regular hexagons are not arcs
+	zigzag := &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}, {2, 0}, {3, 1}, {4, 0}, {5, 1}}}
+	g, err = DetectArcs(zigzag, 1e-9)
+	assert.Nil(t, err)
+	assert.Equal(t, zigzag, g)
+
+	hexagon := make([]Coordinate, 0, 7)
+	for idx := 0; idx <= 6; idx++ {
+		a := float64(idx) * math.Pi / 3
+		hexagon = append(hexagon, Coordinate{math.Cos(a), math.Sin(a)})
+	}
+
+	hpoly := &Polygon{Hdr{XY, 0}, []LinearRing{{hexagon}}}
+	g, err = DetectArcs(hpoly, 1e-9)
+	assert.Nil(t, err)
+	assert.Equal(t, hpoly, g)
+}
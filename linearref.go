@@ -0,0 +1,480 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// The linear referencing operations locate points along a LineString by the fraction of its length, the distance
+// from its start or its M values. Lengths are planar, arcs are linearized with the default options first and Z and M
+// values are interpolated along each segment. Each returns ErrNoGeometry when the geometry is nil and
+// ErrUnsupportedOperation when it is not of a supported type.
+
+// LineInterpolatePoint returns the point at the fraction of the length of a LineString from its start. Returns
+// ErrOutOfRange when the fraction is not between 0 and 1, and an empty point for an empty line.
+func LineInterpolatePoint(g Geometry, fraction float64) (*Point, error) {
+	l, err := newMeasuredLine(g)
+
+	if err != nil {
+		return nil, err
+	} else if !(fraction >= 0 && fraction <= 1) {
+		return nil, ErrOutOfRange
+	}
+
+	return l.point(fraction * l.length()), nil
+}
+
+// LineInterpolatePointAtDistance returns the point at the distance along a LineString from its start. Returns
+// ErrOutOfRange when the distance is negative or longer than the line, and an empty point for an empty line.
+func LineInterpolatePointAtDistance(g Geometry, distance float64) (*Point, error) {
+	l, err := newMeasuredLine(g)
+
+	if err != nil {
+		return nil, err
+	} else if !(distance >= 0 && distance <= l.length()) {
+		return nil, ErrOutOfRange
+	}
+
+	return l.point(distance), nil
+}
+
+// LineLocatePoint returns the fraction of the length of a LineString at which it comes nearest to the point, the
+// first such when there are several. Returns 0 when the line has no length and ErrEmptyGeometry when the point is
+// empty.
+func LineLocatePoint(g Geometry, p *Point) (float64, error) {
+	l, err := newMeasuredLine(g)
+
+	if err != nil {
+		return 0, err
+	} else if p == nil {
+		return 0, ErrNoGeometry
+	} else if len(p.Coordinate) < 2 || math.IsNaN(p.Coordinate[0]) || math.IsNaN(p.Coordinate[1]) {
+		return 0, ErrEmptyGeometry
+	}
+
+	total := l.length()
+
+	if total == 0 {
+		return 0, nil
+	}
+
+	q := xy{p.Coordinate[0], p.Coordinate[1]}
+	best, at := math.Inf(1), 0.0
+
+	for i := 1; i < len(l.coords); i++ {
+		a, b := xy{l.coords[i-1][0], l.coords[i-1][1]}, xy{l.coords[i][0], l.coords[i][1]}
+		n := nearestOnSegment(q, a, b, orient(a, b, q))
+
+		if d := math.Hypot(n[0]-q[0], n[1]-q[1]); d < best {
+			best, at = d, l.cum[i-1]+math.Hypot(n[0]-a[0], n[1]-a[1])
+		}
+	}
+
+	return math.Min(1, at/total), nil
+}
+
+// LineSubstring returns the part of a LineString between two fractions of its length, or a Point when they are the
+// same. An empty line gives an empty LineString, or an empty Point of its dimension when they are the same. Returns
+// ErrOutOfRange unless 0 <= start <= end <= 1.
+func LineSubstring(g Geometry, start, end float64) (Geometry, error) {
+	l, err := newMeasuredLine(g)
+
+	if err != nil {
+		return nil, err
+	} else if !(start >= 0 && start <= end && end <= 1) {
+		return nil, ErrOutOfRange
+	}
+
+	total := l.length()
+	p := l.point(start * total)
+
+	if start == end {
+		return p, nil
+	} else if len(l.coords) == 0 {
+		return &LineString{l.hdr, nil}, nil
+	}
+
+	s, e := start*total, end*total
+	coords := []Coordinate{p.Coordinate}
+
+	for i, c := range l.coords {
+		if l.cum[i] > s && l.cum[i] < e {
+			coords = append(coords, append(Coordinate{}, c...))
+		}
+	}
+
+	return &LineString{l.hdr, append(coords, l.point(e).Coordinate)}, nil
+}
+
+// LocateAlong returns the points of a LineString, MultiLineString, Point or MultiPoint with the M value, as a
+// MultiPoint. Where a line keeps the M value along a segment both of its ends are returned. Returns ErrNoMeasure
+// when the geometry has no M values.
+func LocateAlong(g Geometry, m float64) (*MultiPoint, error) {
+	runs, points, hdr, err := locate(g, m, m)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := &MultiPoint{Hdr: hdr}
+
+	for _, run := range runs {
+		for _, c := range run {
+			out.Points = append(out.Points, Point{hdr, c})
+		}
+	}
+
+	for _, c := range points {
+		out.Points = append(out.Points, Point{hdr, c})
+	}
+
+	return out, nil
+}
+
+// LocateBetween returns the parts of a LineString, MultiLineString, Point or MultiPoint with M values from one
+// measure to the other, in the order of the geometry. The lines are clipped to the range, leaving the parts of each
+// line that run along it, as a MultiLineString. Points, and lines that meet the range at a single point, give a
+// MultiPoint. The result is a GeometryCollection of the two when there are both, and otherwise empty of the type of
+// the input. Returns ErrNoMeasure when the geometry has no M values.
+func LocateBetween(g Geometry, from, to float64) (Geometry, error) {
+	runs, points, hdr, err := locate(g, math.Min(from, to), math.Max(from, to))
+
+	if err != nil {
+		return nil, err
+	}
+
+	lines := &MultiLineString{Hdr: hdr}
+	mp := &MultiPoint{Hdr: hdr}
+
+	for _, run := range runs {
+		if len(run) == 1 {
+			mp.Points = append(mp.Points, Point{hdr, run[0]})
+		} else {
+			lines.LineStrings = append(lines.LineStrings, LineString{hdr, run})
+		}
+	}
+
+	for _, c := range points {
+		mp.Points = append(mp.Points, Point{hdr, c})
+	}
+
+	switch {
+	case len(lines.LineStrings) > 0 && len(mp.Points) > 0:
+		return &GeometryCollection{hdr, []Geometry{lines, mp}}, nil
+	case len(mp.Points) > 0:
+		return mp, nil
+	case len(lines.LineStrings) > 0:
+		return lines, nil
+	}
+
+	switch g.(type) {
+	case *Point, *MultiPoint:
+		return mp, nil
+	default:
+		return lines, nil
+	}
+}
+
+// AddMeasure returns a LineString or MultiLineString with M values rising in proportion to the distance along it,
+// from the start measure at its first point to the end measure at its last, continuing from one line to the next.
+// An M dimension is added when there is none, and existing M values are replaced.
+func AddMeasure(g Geometry, start, end float64) (Geometry, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []LineString
+
+	switch lg := lg.(type) {
+	case *LineString:
+		lines = []LineString{*lg}
+	case *MultiLineString:
+		lines = lg.LineStrings
+	default:
+		return nil, ErrUnsupportedOperation
+	}
+
+	dim := g.Dimension()
+
+	switch dim {
+	case XY:
+		dim = XYM
+	case XYZ:
+		dim = XYZM
+	}
+
+	mi := measureIndex(dim)
+	hdr := Hdr{dim, g.SRID()}
+
+	var total float64
+
+	for _, l := range lines {
+		total += lineLength(l.Coordinates)
+	}
+
+	out := make([]LineString, len(lines))
+
+	var along float64
+
+	for i, l := range lines {
+		out[i] = LineString{hdr, make([]Coordinate, len(l.Coordinates))}
+
+		for k, c := range l.Coordinates {
+			if k > 0 {
+				along += math.Hypot(c[0]-l.Coordinates[k-1][0], c[1]-l.Coordinates[k-1][1])
+			}
+
+			m := make(Coordinate, mi+1)
+			copy(m[:mi], c)
+
+			if m[mi] = start; total > 0 {
+				m[mi] += (end - start) * along / total
+			}
+
+			out[i].Coordinates[k] = m
+		}
+	}
+
+	if _, ok := lg.(*LineString); ok {
+		return &out[0], nil
+	}
+
+	return &MultiLineString{hdr, out}, nil
+}
+
+// measureIndex returns the index of M in the coordinates of the dimension, or -1 when it has none
+func measureIndex(d Dimension) int {
+	switch d {
+	case XYM:
+		return 2
+	case XYZM:
+		return 3
+	default:
+		return -1
+	}
+}
+
+// locate returns the runs of the lines of g with M values from lo to hi, and its points with M values in the range
+func locate(g Geometry, lo, hi float64) ([][]Coordinate, []Coordinate, Hdr, error) {
+	if g == nil {
+		return nil, nil, Hdr{}, ErrNoGeometry
+	}
+
+	mi := measureIndex(g.Dimension())
+	hdr := Hdr{g.Dimension(), g.SRID()}
+
+	if mi < 0 {
+		return nil, nil, hdr, ErrNoMeasure
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, nil, hdr, err
+	}
+
+	var runs [][]Coordinate
+	var points []Coordinate
+
+	in := func(c Coordinate) bool {
+		return len(c) > mi && c[mi] >= lo && c[mi] <= hi
+	}
+
+	switch lg := lg.(type) {
+	case *Point:
+		if in(lg.Coordinate) {
+			points = append(points, lg.Coordinate)
+		}
+	case *MultiPoint:
+		for _, p := range lg.Points {
+			if in(p.Coordinate) {
+				points = append(points, p.Coordinate)
+			}
+		}
+	case *LineString:
+		runs = clipMeasures(lg.Coordinates, mi, lo, hi)
+	case *MultiLineString:
+		for _, l := range lg.LineStrings {
+			runs = append(runs, clipMeasures(l.Coordinates, mi, lo, hi)...)
+		}
+	default:
+		return nil, nil, hdr, ErrUnsupportedOperation
+	}
+
+	return runs, points, hdr, nil
+}
+
+// clipMeasures returns the runs of the line along which the M values, at index mi of the coordinates, lie from lo to
+// hi. A run that meets the range at a single point holds just that point.
+func clipMeasures(coords []Coordinate, mi int, lo, hi float64) [][]Coordinate {
+	var runs [][]Coordinate
+
+	// open is set while the last run continues to the end of the previous segment
+	open := false
+
+	add := func(c Coordinate, join bool) {
+		if n := len(runs); join && n > 0 {
+			if run := runs[n-1]; !equal2D(run[len(run)-1], c) || run[len(run)-1][mi] != c[mi] {
+				runs[n-1] = append(run, c)
+			}
+
+			return
+		}
+
+		runs = append(runs, []Coordinate{c})
+	}
+
+	if len(coords) == 1 && len(coords[0]) > mi && coords[0][mi] >= lo && coords[0][mi] <= hi {
+		return [][]Coordinate{{coords[0]}}
+	}
+
+	for i := 1; i < len(coords); i++ {
+		a, b := coords[i-1], coords[i]
+
+		if len(a) <= mi || len(b) <= mi {
+			open = false
+			continue
+		}
+
+		t1, t2 := 0.0, 1.0
+
+		if ma, mb := a[mi], b[mi]; ma == mb {
+			if ma < lo || ma > hi {
+				open = false
+				continue
+			}
+		} else {
+			t1, t2 = (lo-ma)/(mb-ma), (hi-ma)/(mb-ma)
+
+			if t1 > t2 {
+				t1, t2 = t2, t1
+			}
+
+			if t1, t2 = math.Max(t1, 0), math.Min(t2, 1); t1 > t2 {
+				open = false
+				continue
+			}
+		}
+
+		add(interpolate(a, b, t1, mi, lo, hi), open && t1 == 0)
+		add(interpolate(a, b, t2, mi, lo, hi), true)
+		open = t2 == 1
+	}
+
+	return runs
+}
+
+// interpolate returns the coordinate at the fraction t of the way from a to b, with its M value clamped to the range
+// so the ends of clipped runs take the measures exactly
+func interpolate(a, b Coordinate, t float64, mi int, lo, hi float64) Coordinate {
+	switch t {
+	case 0:
+		return append(Coordinate{}, a...)
+	case 1:
+		return append(Coordinate{}, b...)
+	}
+
+	c := make(Coordinate, len(a))
+	c[0], c[1] = a[0]+t*(b[0]-a[0]), a[1]+t*(b[1]-a[1])
+	lerp(c, a, b, t)
+
+	if mi >= 0 {
+		c[mi] = math.Max(lo, math.Min(hi, c[mi]))
+	}
+
+	return c
+}
+
+// measuredLine is a LineString with the distance along it to each vertex
+type measuredLine struct {
+	hdr    Hdr
+	coords []Coordinate
+	cum    []float64
+}
+
+func newMeasuredLine(g Geometry) (*measuredLine, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ls, ok := lg.(*LineString)
+
+	if !ok {
+		return nil, ErrUnsupportedOperation
+	}
+
+	l := &measuredLine{hdr: Hdr{ls.Dim, ls.Srid}, coords: ls.Coordinates, cum: make([]float64, len(ls.Coordinates))}
+
+	for i := 1; i < len(l.coords); i++ {
+		a, b := l.coords[i-1], l.coords[i]
+		l.cum[i] = l.cum[i-1] + math.Hypot(b[0]-a[0], b[1]-a[1])
+	}
+
+	return l, nil
+}
+
+func (l *measuredLine) length() float64 {
+	if len(l.cum) == 0 {
+		return 0
+	}
+
+	return l.cum[len(l.cum)-1]
+}
+
+// point returns the point at the distance along the line, which must lie within its length, or an empty point of
+// the dimension of the line when it has no coordinates
+func (l *measuredLine) point(d float64) *Point {
+	if len(l.coords) == 0 {
+		c := Coordinate{math.NaN(), math.NaN()}
+
+		switch l.hdr.Dim {
+		case XYZ, XYM:
+			c = append(c, math.NaN())
+		case XYZM:
+			c = append(c, math.NaN(), math.NaN())
+		}
+
+		return &Point{l.hdr, c}
+	}
+
+	i := sort.SearchFloat64s(l.cum, d)
+
+	switch {
+	case i >= len(l.cum):
+		i = len(l.cum) - 1
+	case i == 0 || l.cum[i] == d:
+	default:
+		t := (d - l.cum[i-1]) / (l.cum[i] - l.cum[i-1])
+		return &Point{l.hdr, interpolate(l.coords[i-1], l.coords[i], t, -1, 0, 0)}
+	}
+
+	return &Point{l.hdr, append(Coordinate{}, l.coords[i]...)}
+}
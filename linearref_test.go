@@ -0,0 +1,237 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var chainage = &LineString{Hdr{XYM, 27700}, []Coordinate{{0, 0, 100}, {10, 0, 110}, {10, 10, 120}}}
+
+func TestLineInterpolatePoint(t *testing.T) {
+	datasets := []struct {
+		name     string
+		fraction float64
+		expected Coordinate
+	}{
+		{"start", 0, Coordinate{0, 0, 100}},
+		{"quarter", 0.25, Coordinate{5, 0, 105}},
+		{"vertex", 0.5, Coordinate{10, 0, 110}},
+		{"three quarters", 0.75, Coordinate{10, 5, 115}},
+		{"end", 1, Coordinate{10, 10, 120}},
+	}
+
+	for _, dataset := range datasets {
+		p, err := LineInterpolatePoint(chainage, dataset.fraction)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, &Point{Hdr{XYM, 27700}, dataset.expected}, p, dataset.name)
+
+		p, err = LineInterpolatePointAtDistance(chainage, dataset.fraction*20)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, &Point{Hdr{XYM, 27700}, dataset.expected}, p, dataset.name)
+	}
+
+	_, err := LineInterpolatePoint(chainage, 1.5)
+	assert.Equal(t, ErrOutOfRange, err)
+
+	_, err = LineInterpolatePointAtDistance(chainage, -1)
+	assert.Equal(t, ErrOutOfRange, err)
+
+	_, err = LineInterpolatePoint(box(0, 0, 1, 1), 0.5)
+	assert.Equal(t, ErrUnsupportedOperation, err)
+
+	_, err = LineInterpolatePoint(nil, 0.5)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestLineLocatePoint(t *testing.T) {
+	datasets := []struct {
+		name     string
+		p        Coordinate
+		expected float64
+	}{
+		{"on line", Coordinate{5, 0}, 0.25},
+		{"beside", Coordinate{12, 5}, 0.75},
+		{"before", Coordinate{-3, -3}, 0},
+		{"beyond", Coordinate{10, 20}, 1},
+		{"corner", Coordinate{15, -5}, 0.5},
+	}
+
+	for _, dataset := range datasets {
+		f, err := LineLocatePoint(chainage, &Point{Hdr{XY, 0}, dataset.p})
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, f, dataset.name)
+	}
+
+	_, err := LineLocatePoint(chainage, nil)
+	assert.Equal(t, ErrNoGeometry, err)
+
+	_, err = LineLocatePoint(chainage, emptyPoint(0))
+	assert.Equal(t, ErrEmptyGeometry, err)
+
+	_, err = LineLocatePoint(chainage, &Point{Hdr{XY, 0}, nil})
+	assert.Equal(t, ErrEmptyGeometry, err)
+}
+
+func TestLineSubstring(t *testing.T) {
+	datasets := []struct {
+		name       string
+		start, end float64
+		expected   Geometry
+	}{
+		{"all", 0, 1, chainage},
+		{"across vertex", 0.25, 0.75, &LineString{Hdr{XYM, 27700}, []Coordinate{{5, 0, 105}, {10, 0, 110}, {10, 5, 115}}}},
+		{"from vertex", 0.5, 0.75, &LineString{Hdr{XYM, 27700}, []Coordinate{{10, 0, 110}, {10, 5, 115}}}},
+		{"point", 0.25, 0.25, &Point{Hdr{XYM, 27700}, Coordinate{5, 0, 105}}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := LineSubstring(chainage, dataset.start, dataset.end)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	empty := &LineString{Hdr{XYM, 27700}, nil}
+
+	g, err := LineSubstring(empty, 0.25, 0.75)
+	assert.NoError(t, err)
+	assert.Equal(t, empty, g)
+
+	g, err = LineSubstring(empty, 0.5, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, Hdr{XYM, 27700}, g.(*Point).Hdr)
+	assert.Len(t, g.(*Point).Coordinate, 3)
+	assert.True(t, math.IsNaN(g.(*Point).Coordinate[2]))
+
+	p, err := LineInterpolatePoint(&LineString{Hdr{XYZM, 4326}, nil}, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, Hdr{XYZM, 4326}, p.Hdr)
+	assert.Len(t, p.Coordinate, 4)
+
+	_, err = LineSubstring(chainage, 0.75, 0.25)
+	assert.Equal(t, ErrOutOfRange, err)
+}
+
+func TestLocateAlong(t *testing.T) {
+	line := &LineString{Hdr{XYZM, 0}, []Coordinate{{0, 0, 1, 0}, {10, 0, 3, 10}, {20, 0, 3, 10}, {30, 0, 5, 0}}}
+
+	datasets := []struct {
+		name     string
+		m        float64
+		expected []Coordinate
+	}{
+		{"twice", 5, []Coordinate{{5, 0, 2, 5}, {25, 0, 4, 5}}},
+		{"level", 10, []Coordinate{{10, 0, 3, 10}, {20, 0, 3, 10}}},
+		{"ends", 0, []Coordinate{{0, 0, 1, 0}, {30, 0, 5, 0}}},
+		{"none", 11, nil},
+	}
+
+	for _, dataset := range datasets {
+		mp, err := LocateAlong(line, dataset.m)
+		assert.NoError(t, err, dataset.name)
+
+		var coords []Coordinate
+
+		for _, p := range mp.Points {
+			coords = append(coords, p.Coordinate)
+		}
+
+		assert.Equal(t, dataset.expected, coords, dataset.name)
+	}
+
+	_, err := LocateAlong(box(0, 0, 1, 1), 0)
+	assert.Equal(t, ErrNoMeasure, err)
+}
+
+func TestLocateBetween(t *testing.T) {
+	line := &LineString{Hdr{XYM, 0}, []Coordinate{{0, 0, 0}, {10, 0, 10}, {20, 0, 0}, {30, 0, 10}}}
+
+	datasets := []struct {
+		name     string
+		g        Geometry
+		from, to float64
+		expected Geometry
+	}{
+		{"clipped", line, 2, 4, &MultiLineString{Hdr{XYM, 0}, []LineString{
+			{Hdr{XYM, 0}, []Coordinate{{2, 0, 2}, {4, 0, 4}}},
+			{Hdr{XYM, 0}, []Coordinate{{16, 0, 4}, {18, 0, 2}}},
+			{Hdr{XYM, 0}, []Coordinate{{22, 0, 2}, {24, 0, 4}}},
+		}}},
+		{"through vertex", line, 12, 5, &MultiLineString{Hdr{XYM, 0}, []LineString{
+			{Hdr{XYM, 0}, []Coordinate{{5, 0, 5}, {10, 0, 10}, {15, 0, 5}}},
+			{Hdr{XYM, 0}, []Coordinate{{25, 0, 5}, {30, 0, 10}}},
+		}}},
+		{"touching", line, 10, 12, &MultiPoint{Hdr{XYM, 0}, []Point{
+			{Hdr{XYM, 0}, Coordinate{10, 0, 10}},
+			{Hdr{XYM, 0}, Coordinate{30, 0, 10}},
+		}}},
+		{"mixed", &LineString{Hdr{XYM, 0}, []Coordinate{{0, 0, 0}, {10, 0, 10}, {10, 10, 20}, {20, 10, 10}}}, 0, 10,
+			&GeometryCollection{Hdr{XYM, 0}, []Geometry{
+				&MultiLineString{Hdr{XYM, 0}, []LineString{{Hdr{XYM, 0}, []Coordinate{{0, 0, 0}, {10, 0, 10}}}}},
+				&MultiPoint{Hdr{XYM, 0}, []Point{{Hdr{XYM, 0}, Coordinate{20, 10, 10}}}},
+			}}},
+		{"points", &MultiPoint{Hdr{XYM, 0}, []Point{{Hdr{XYM, 0}, Coordinate{0, 0, 1}}, {Hdr{XYM, 0}, Coordinate{1, 1, 5}}}},
+			0, 2, &MultiPoint{Hdr{XYM, 0}, []Point{{Hdr{XYM, 0}, Coordinate{0, 0, 1}}}}},
+		{"empty", line, 20, 30, &MultiLineString{Hdr{XYM, 0}, nil}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := LocateBetween(dataset.g, dataset.from, dataset.to)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	_, err := LocateBetween(&LineString{Hdr{XYZ, 0}, []Coordinate{{0, 0, 0}, {1, 1, 1}}}, 0, 1)
+	assert.Equal(t, ErrNoMeasure, err)
+}
+
+func TestAddMeasure(t *testing.T) {
+	datasets := []struct {
+		name       string
+		g          Geometry
+		start, end float64
+		expected   Geometry
+	}{
+		{"line", &LineString{Hdr{XY, 4326}, []Coordinate{{0, 0}, {3, 0}, {3, 1}}}, 0, 100,
+			&LineString{Hdr{XYM, 4326}, []Coordinate{{0, 0, 0}, {3, 0, 75}, {3, 1, 100}}}},
+		{"z", &LineString{Hdr{XYZ, 0}, []Coordinate{{0, 0, 5}, {2, 0, 6}}}, 10, 0,
+			&LineString{Hdr{XYZM, 0}, []Coordinate{{0, 0, 5, 10}, {2, 0, 6, 0}}}},
+		{"replaced", &LineString{Hdr{XYM, 0}, []Coordinate{{0, 0, 7}, {2, 0, 9}}}, 1, 2,
+			&LineString{Hdr{XYM, 0}, []Coordinate{{0, 0, 1}, {2, 0, 2}}}},
+		{"multi", &MultiLineString{Hdr{XY, 0}, []LineString{
+			{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 0}}},
+			{Hdr{XY, 0}, []Coordinate{{5, 5}, {5, 8}}},
+		}}, 0, 4, &MultiLineString{Hdr{XYM, 0}, []LineString{
+			{Hdr{XYM, 0}, []Coordinate{{0, 0, 0}, {1, 0, 1}}},
+			{Hdr{XYM, 0}, []Coordinate{{5, 5, 1}, {5, 8, 4}}},
+		}}},
+		{"no length", &LineString{Hdr{XY, 0}, []Coordinate{{1, 1}, {1, 1}}}, 3, 4,
+			&LineString{Hdr{XYM, 0}, []Coordinate{{1, 1, 3}, {1, 1, 3}}}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := AddMeasure(dataset.g, dataset.start, dataset.end)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	_, err := AddMeasure(&Point{Hdr{XY, 0}, Coordinate{0, 0}}, 0, 1)
+	assert.Equal(t, ErrUnsupportedOperation, err)
+}
@@ -0,0 +1,375 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"errors"
+	"math"
+)
+
+// RepairMethod selects how MakeValid rebuilds the area of an invalid polygon
+type RepairMethod int
+
+const (
+	// RepairLinework nodes the rings of all the polygons together and keeps the area enclosed by an odd number of
+	// them, so a bowtie becomes two triangles and the overlap of two polygons is cut out
+	RepairLinework RepairMethod = iota
+	// RepairStructure keeps the area of each shell less that of its holes, each ring enclosing the area it
+	// surrounds an odd number of times, and merges the polygons where they overlap
+	RepairStructure
+)
+
+// MakeValidOption configures MakeValid
+type MakeValidOption func(*repairer)
+
+// WithRepairMethod sets the method used to rebuild the area, RepairLinework by default
+func WithRepairMethod(m RepairMethod) MakeValidOption {
+	return func(r *repairer) {
+		r.method = m
+	}
+}
+
+// WithKeepCollapsed keeps the parts that enclose no area, such as spikes and rings with every point on a line, as
+// lines and points alongside the polygons rather than dropping them
+func WithKeepCollapsed(keep bool) MakeValidOption {
+	return func(r *repairer) {
+		r.keepCollapsed = keep
+	}
+}
+
+type repairer struct {
+	method        RepairMethod
+	keepCollapsed bool
+}
+
+// MakeValid repairs an invalid Polygon or MultiPolygon, see ValidationReason. Only polygonal geometries, and
+// collections of them, are repaired: any other invalid geometry, such as a LineString with a single point, returns
+// ErrUnsupportedOperation. Rings are closed, invalid coordinates and repeated points removed and the linework noded
+// wherever it crosses or touches itself, then the area is rebuilt by the method chosen with WithRepairMethod. Parts
+// that collapse to lines or points are dropped unless WithKeepCollapsed is given, in which case the result is a
+// GeometryCollection when there are any. A MultiPolygon stays a MultiPolygon when only polygons remain. The members
+// of a GeometryCollection are repaired in turn.
+//
+// A repaired geometry is always XY: its Z and M values are dropped. Valid geometries are returned unchanged, keeping
+// their Z and M values. Curves of invalid geometries are linearized with the default options and the result keeps
+// the SRID. Circular strings with an even number of points return ErrInvalidArc.
+func MakeValid(g Geometry, opts ...MakeValidOption) (Geometry, error) {
+	r := &repairer{}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r.repair(g)
+}
+
+func (r *repairer) repair(g Geometry) (Geometry, error) {
+	err := ValidationReason(g)
+
+	if err == nil {
+		return g, nil
+	}
+
+	var verr *ValidityError
+
+	if !errors.As(err, &verr) {
+		return nil, err
+	} else if verr.Reason == InvalidArc {
+		return nil, ErrInvalidArc
+	}
+
+	if gc, ok := g.(*GeometryCollection); ok {
+		out := &GeometryCollection{Hdr{XY, gc.SRID()}, make([]Geometry, len(gc.Geometries))}
+
+		for i, m := range gc.Geometries {
+			if out.Geometries[i], err = r.repair(m); err != nil {
+				return nil, err
+			}
+		}
+
+		return out, nil
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch lg := lg.(type) {
+	case *Polygon:
+		return r.polygons(lg.SRID(), Extent(lg), [][]LinearRing{lg.Rings}, false), nil
+	case *MultiPolygon:
+		polygons := make([][]LinearRing, len(lg.Polygons))
+
+		for i, p := range lg.Polygons {
+			polygons[i] = p.Rings
+		}
+
+		return r.polygons(lg.SRID(), Extent(lg), polygons, true), nil
+	default:
+		return nil, ErrUnsupportedOperation
+	}
+}
+
+func (r *repairer) polygons(srid uint32, extent Envelope, polygons [][]LinearRing, multi bool) Geometry {
+	scale := &operand{extent: extent}
+	sn := newSnapper(scale, scale)
+
+	var area *operand
+
+	collapsed := &operand{}
+
+	if r.method == RepairStructure {
+		var parts []*operand
+
+		for _, rings := range polygons {
+			if len(rings) == 0 {
+				continue
+			}
+
+			shell, c := parity([][]xy{snapRing(rings[0].Coordinates, sn)}, sn)
+			collapsed.points = append(collapsed.points, c.points...)
+			collapsed.lines = append(collapsed.lines, c.lines...)
+
+			var holes []*operand
+
+			for _, h := range rings[1:] {
+				hole, _ := parity([][]xy{snapRing(h.Coordinates, sn)}, sn)
+				holes = append(holes, hole)
+			}
+
+			parts = append(parts, overlayOperands(shell, cascade(holes), difference))
+		}
+
+		area = cascade(parts)
+	} else {
+		var all [][]xy
+
+		for _, rings := range polygons {
+			for _, ring := range rings {
+				all = append(all, snapRing(ring.Coordinates, sn))
+			}
+		}
+
+		area, collapsed = parity(all, sn)
+	}
+
+	if r.keepCollapsed && (len(collapsed.points) > 0 || len(collapsed.lines) > 0) {
+		collapsed.setExtent()
+		area = overlayOperands(area, collapsed, union)
+	}
+
+	g := area.geometry(srid, 2)
+
+	if p, ok := g.(*Polygon); ok && multi {
+		if len(p.Rings) == 0 {
+			return &MultiPolygon{p.Hdr, nil}
+		}
+
+		return &MultiPolygon{p.Hdr, []Polygon{*p}}
+	}
+
+	return g
+}
+
+// snapRing returns the snapped points of a ring, closed and without repeats or invalid coordinates. A ring that
+// collapses to a point is left with that one point.
+func snapRing(coords []Coordinate, sn *snapper) []xy {
+	var ring []xy
+
+	for _, c := range coords {
+		if len(c) < 2 || math.IsNaN(c[0]) || math.IsNaN(c[1]) || math.IsInf(c[0], 0) || math.IsInf(c[1], 0) {
+			continue
+		}
+
+		if p := sn.snap(xy{c[0], c[1]}); len(ring) == 0 || ring[len(ring)-1] != p {
+			ring = append(ring, p)
+		}
+	}
+
+	if len(ring) > 1 && ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+
+	return ring
+}
+
+// parity nodes the rings together and returns the area enclosed by an odd number of them as polygons. The edges
+// the rings run along an even number of times enclose no area and are returned as lines, and the rings collapsed to
+// a single point as points.
+func parity(rings [][]xy, sn *snapper) (*operand, *operand) {
+	collapsed := &operand{}
+
+	var segs []*segment
+
+	for _, r := range rings {
+		if len(r) == 1 {
+			collapsed.points = append(collapsed.points, r[0])
+		}
+
+		for k := 1; k < len(r); k++ {
+			segs = append(segs, newSegment(r[k-1], r[k], 0, ringSegment, false))
+		}
+	}
+
+	nodeSegments(segs, sn)
+
+	count := make(map[[2]xy]int)
+
+	var keys [][2]xy
+
+	for _, s := range segs {
+		pts := s.split()
+
+		for k := 1; k < len(pts); k++ {
+			p, q := pts[k-1], pts[k]
+
+			if p == q {
+				continue
+			}
+
+			if q[0] < p[0] || (q[0] == p[0] && q[1] < p[1]) {
+				p, q = q, p
+			}
+
+			if count[[2]xy{p, q}] == 0 {
+				keys = append(keys, [2]xy{p, q})
+			}
+
+			count[[2]xy{p, q}]++
+		}
+	}
+
+	// the edges run along an odd number of times separate faces inside the area from faces outside it
+	var odd [][2]xy
+
+	for _, k := range keys {
+		if count[k]%2 == 1 {
+			odd = append(odd, k)
+		} else {
+			collapsed.lines = append(collapsed.lines, []xy{k[0], k[1]})
+		}
+	}
+
+	rows, cols := newStripIndex(odd, 1), newStripIndex(odd, 0)
+	boundary := make([][2]xy, len(odd))
+
+	for i, e := range odd {
+		p, q := e[0], e[1]
+		m := xy{(p[0] + q[0]) / 2, (p[1] + q[1]) / 2}
+
+		// count the edges crossed by a ray from the middle of the edge to infinity, outside every ring, along X or
+		// along Y when the edge is horizontal. The face the ray leaves through is inside when the count is odd.
+		var n int
+		var left bool
+
+		if p[1] != q[1] {
+			for _, j := range rows.at(m[1]) {
+				a, b := odd[j][0], odd[j][1]
+
+				if a[1] > b[1] {
+					a, b = b, a
+				}
+
+				if j != i && a[1] <= m[1] && b[1] > m[1] && orient(a, b, m) > 0 {
+					n++
+				}
+			}
+
+			// the ray leaves an edge running up through its right side
+			left = (n%2 == 1) != (q[1] > p[1])
+		} else {
+			for _, j := range cols.at(m[0]) {
+				a, b := odd[j][0], odd[j][1]
+
+				if j != i && a[0] <= m[0] && b[0] > m[0] && orient(a, b, m) < 0 {
+					n++
+				}
+			}
+
+			left = n%2 == 1
+		}
+
+		if left {
+			boundary[i] = [2]xy{p, q}
+		} else {
+			boundary[i] = [2]xy{q, p}
+		}
+	}
+
+	area := &operand{polygons: buildPolygons(boundary)}
+	area.setExtent()
+
+	return area, collapsed
+}
+
+// stripIndex buckets edges into strips by the range they span along one axis, to find those a ray along the other
+// axis may cross
+type stripIndex struct {
+	axis      int
+	min, size float64
+	strips    [][]int
+}
+
+func newStripIndex(edges [][2]xy, axis int) *stripIndex {
+	n := int(math.Sqrt(float64(len(edges)))) + 1
+	s := &stripIndex{axis: axis, min: math.Inf(1), strips: make([][]int, n)}
+	max := math.Inf(-1)
+
+	for _, e := range edges {
+		s.min = math.Min(s.min, math.Min(e[0][axis], e[1][axis]))
+		max = math.Max(max, math.Max(e[0][axis], e[1][axis]))
+	}
+
+	s.size = (max - s.min) / float64(n)
+
+	for i, e := range edges {
+		lo, hi := s.strip(e[0][axis]), s.strip(e[1][axis])
+
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+
+		for k := lo; k <= hi; k++ {
+			s.strips[k] = append(s.strips[k], i)
+		}
+	}
+
+	return s
+}
+
+func (s *stripIndex) strip(v float64) int {
+	if !(s.size > 0) {
+		return 0
+	}
+
+	k := int((v - s.min) / s.size)
+
+	if k < 0 {
+		return 0
+	} else if k >= len(s.strips) {
+		return len(s.strips) - 1
+	}
+
+	return k
+}
+
+// at returns the edges that may span v
+func (s *stripIndex) at(v float64) []int {
+	return s.strips[s.strip(v)]
+}
@@ -0,0 +1,111 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeValid(t *testing.T) {
+	bowtie := poly([]Coordinate{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}})
+	spike := poly([]Coordinate{{0, 0}, {2, 0}, {2, 0}, {4, 0}, {2, 0}, {2, 2}, {0, 2}})
+	line := poly([]Coordinate{{0, 0}, {1, 1}, {2, 2}, {0, 0}})
+
+	datasets := []struct {
+		name     string
+		g        Geometry
+		opts     []MakeValidOption
+		expected Geometry
+	}{
+		{"valid", square, nil, square},
+		{"unclosed", poly([]Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 1}}), nil, box(0, 0, 1, 1)},
+		{"unclosed z", &Polygon{Hdr{XYZ, 0}, []LinearRing{{Coordinates: []Coordinate{{0, 0, 5}, {1, 0, 5}, {1, 1, 5},
+			{0, 1, 5}}}}}, nil, box(0, 0, 1, 1)},
+		{"bowtie", bowtie, nil, &MultiPolygon{Hdr{XY, 0}, []Polygon{
+			*poly([]Coordinate{{0, 0}, {1, 1}, {0, 2}, {0, 0}}),
+			*poly([]Coordinate{{2, 2}, {1, 1}, {2, 0}, {2, 2}}),
+		}}},
+		{"spike", spike, nil, box(0, 0, 2, 2)},
+		{"spike kept", spike, []MakeValidOption{WithKeepCollapsed(true)}, &GeometryCollection{Hdr{XY, 0}, []Geometry{
+			box(0, 0, 2, 2),
+			&LineString{Hdr{XY, 0}, []Coordinate{{2, 0}, {4, 0}}},
+		}}},
+		{"collapsed", line, nil, &Polygon{Hdr{XY, 0}, nil}},
+		{"collapsed kept", line, []MakeValidOption{WithKeepCollapsed(true)}, &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}, {2, 2}}}},
+		{"multi", &MultiPolygon{Hdr{XY, 0}, []Polygon{*poly([]Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 1}})}}, nil, &MultiPolygon{Hdr{XY, 0}, []Polygon{*box(0, 0, 1, 1)}}},
+		{"collection", &GeometryCollection{Hdr{XY, 0}, []Geometry{&Point{Hdr{XY, 0}, Coordinate{5, 5}}, line}}, nil, &GeometryCollection{Hdr{XY, 0}, []Geometry{
+			&Point{Hdr{XY, 0}, Coordinate{5, 5}},
+			&Polygon{Hdr{XY, 0}, nil},
+		}}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := MakeValid(dataset.g, dataset.opts...)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	_, err := MakeValid(nil)
+	assert.Equal(t, ErrNoGeometry, err)
+
+	_, err = MakeValid(&LineString{Hdr{XY, 0}, []Coordinate{{1, 1}}})
+	assert.Equal(t, ErrUnsupportedOperation, err)
+
+	_, err = MakeValid(&GeometryCollection{Hdr{XY, 0}, []Geometry{&LineString{Hdr{XY, 0}, []Coordinate{{1, 1}}}}})
+	assert.Equal(t, ErrUnsupportedOperation, err)
+
+	_, err = MakeValid(&CircularString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}, {2, 0}, {3, 1}}})
+	assert.Equal(t, ErrInvalidArc, err)
+}
+
+func TestMakeValidMethods(t *testing.T) {
+	overlapping := &MultiPolygon{Hdr{XY, 4326}, []Polygon{*box(0, 0, 2, 2), *box(1, 1, 3, 3)}}
+	outside := poly([]Coordinate{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, []Coordinate{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}})
+
+	datasets := []struct {
+		name   string
+		g      Geometry
+		method RepairMethod
+		area   float64
+		parts  int
+	}{
+		{"overlapping linework", overlapping, RepairLinework, 6, 2},
+		{"overlapping structure", overlapping, RepairStructure, 7, 1},
+		{"hole outside linework", outside, RepairLinework, 6, 2},
+		{"hole outside structure", outside, RepairStructure, 3, 1},
+		{"bowtie structure", poly([]Coordinate{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}), RepairStructure, 2, 2},
+	}
+
+	for _, dataset := range datasets {
+		g, err := MakeValid(dataset.g, WithRepairMethod(dataset.method))
+		assert.NoError(t, err, dataset.name)
+		assert.NoError(t, ValidationReason(g), dataset.name)
+		assert.Equal(t, dataset.g.SRID(), g.SRID(), dataset.name)
+		assert.InDelta(t, dataset.area, Area(g), 1e-12, dataset.name)
+
+		switch g := g.(type) {
+		case *Polygon:
+			assert.Equal(t, dataset.parts, 1, dataset.name)
+		case *MultiPolygon:
+			assert.Equal(t, dataset.parts, len(g.Polygons), dataset.name)
+		default:
+			t.Errorf("%s: unexpected %T", dataset.name, g)
+		}
+	}
+}
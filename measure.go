@@ -0,0 +1,652 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// Measures are planar, in the units of the coordinates, and ignore any Z or M values. Arcs are measured exactly.
+
+// Area returns the area of the polygonal parts of g, excluding holes. Points and lines have no area.
+func Area(g Geometry) float64 {
+	switch g := g.(type) {
+	case *Polygon:
+		return polygonArea(g.Rings)
+	case *MultiPolygon:
+		var area float64
+
+		for _, p := range g.Polygons {
+			area += polygonArea(p.Rings)
+		}
+
+		return area
+	case *Triangle:
+		return polygonArea(g.Rings)
+	case *PolyhedralSurface:
+		var area float64
+
+		for _, p := range g.Polygons {
+			area += polygonArea(p.Rings)
+		}
+
+		return area
+	case *TIN:
+		var area float64
+
+		for _, t := range g.Triangles {
+			area += polygonArea(t.Rings)
+		}
+
+		return area
+	case *CurvePolygon:
+		var area float64
+
+		for idx, r := range g.Rings {
+			a := math.Abs(curveArea(r))
+
+			if idx == 0 {
+				area += a
+			} else {
+				area -= a
+			}
+		}
+
+		return area
+	case *MultiSurface:
+		return sumMeasure(Area, g.Surfaces)
+	case *GeometryCollection:
+		return sumMeasure(Area, g.Geometries)
+	default:
+		return 0
+	}
+}
+
+// Length returns the length of the lineal parts of g. The boundaries of polygons are not included, see Perimeter.
+func Length(g Geometry) float64 {
+	switch g := g.(type) {
+	case *LineString:
+		return lineLength(g.Coordinates)
+	case *MultiLineString:
+		var length float64
+
+		for _, ls := range g.LineStrings {
+			length += lineLength(ls.Coordinates)
+		}
+
+		return length
+	case *CircularString, *CompoundCurve:
+		return curveLength(g)
+	case *MultiCurve:
+		return sumMeasure(Length, g.Curves)
+	case *GeometryCollection:
+		return sumMeasure(Length, g.Geometries)
+	default:
+		return 0
+	}
+}
+
+// Perimeter returns the length of the boundaries, including holes, of the polygonal parts of g
+func Perimeter(g Geometry) float64 {
+	switch g := g.(type) {
+	case *Polygon:
+		return ringsLength(g.Rings)
+	case *MultiPolygon:
+		var length float64
+
+		for _, p := range g.Polygons {
+			length += ringsLength(p.Rings)
+		}
+
+		return length
+	case *Triangle:
+		return ringsLength(g.Rings)
+	case *PolyhedralSurface:
+		var length float64
+
+		for _, p := range g.Polygons {
+			length += ringsLength(p.Rings)
+		}
+
+		return length
+	case *TIN:
+		var length float64
+
+		for _, t := range g.Triangles {
+			length += ringsLength(t.Rings)
+		}
+
+		return length
+	case *CurvePolygon:
+		var length float64
+
+		for _, r := range g.Rings {
+			length += curveLength(r)
+		}
+
+		return length
+	case *MultiSurface:
+		return sumMeasure(Perimeter, g.Surfaces)
+	case *GeometryCollection:
+		return sumMeasure(Perimeter, g.Geometries)
+	default:
+		return 0
+	}
+}
+
+func sumMeasure(measure func(Geometry) float64, geoms []Geometry) float64 {
+	var total float64
+
+	for _, g := range geoms {
+		total += measure(g)
+	}
+
+	return total
+}
+
+func polygonArea(rings []LinearRing) float64 {
+	var area float64
+
+	for idx, r := range rings {
+		a := math.Abs(signedArea(r.Coordinates))
+
+		if idx == 0 {
+			area += a
+		} else {
+			area -= a
+		}
+	}
+
+	return area
+}
+
+// signedArea returns the area of a ring, positive when it is counter-clockwise
+func signedArea(coords []Coordinate) float64 {
+	if len(coords) < 3 {
+		return 0
+	}
+
+	// measure relative to the first vertex to limit the loss of precision far from the origin
+	ox, oy := coords[0][0], coords[0][1]
+
+	var sum float64
+	for idx := 1; idx+1 < len(coords); idx++ {
+		sum += (coords[idx][0]-ox)*(coords[idx+1][1]-oy) - (coords[idx+1][0]-ox)*(coords[idx][1]-oy)
+	}
+
+	return sum / 2
+}
+
+func lineLength(coords []Coordinate) float64 {
+	var length float64
+
+	for idx := 1; idx < len(coords); idx++ {
+		length += math.Hypot(coords[idx][0]-coords[idx-1][0], coords[idx][1]-coords[idx-1][1])
+	}
+
+	return length
+}
+
+func ringsLength(rings []LinearRing) float64 {
+	var length float64
+
+	for _, r := range rings {
+		length += lineLength(r.Coordinates)
+	}
+
+	return length
+}
+
+// curveArea returns the signed area enclosed by a closed LineString, CircularString or CompoundCurve. Each arc adds
+// the circular segment between its chord and the arc to the area of the polygon formed by the chords.
+func curveArea(g Geometry) float64 {
+	var chords []Coordinate
+	var segments float64
+
+	addArcs := func(coords []Coordinate) {
+		for idx := 0; idx+2 < len(coords); idx += 2 {
+			p0, p1, p2 := coords[idx], coords[idx+1], coords[idx+2]
+
+			if ca, ok := newArc(p0, p1, p2); ok {
+				segments += ca.r * ca.r / 2 * (ca.sweep - math.Sin(ca.sweep))
+				chords = append(chords, p0)
+			} else {
+				chords = append(chords, p0, p1)
+			}
+		}
+
+		if len(coords) > 0 {
+			chords = append(chords, coords[len(coords)-1])
+		}
+	}
+
+	switch g := g.(type) {
+	case *LineString:
+		chords = g.Coordinates
+	case *CircularString:
+		addArcs(g.Coordinates)
+	case *CompoundCurve:
+		for _, c := range g.Curves {
+			switch c := c.(type) {
+			case *LineString:
+				chords = append(chords, c.Coordinates...)
+			case *CircularString:
+				addArcs(c.Coordinates)
+			}
+		}
+	}
+
+	// repeated points between the members of a compound curve add nothing to the area
+	return signedArea(chords) + segments
+}
+
+// curveLength returns the length of a LineString, CircularString or CompoundCurve
+func curveLength(g Geometry) float64 {
+	switch g := g.(type) {
+	case *LineString:
+		return lineLength(g.Coordinates)
+	case *CircularString:
+		var length float64
+
+		for idx := 0; idx+2 < len(g.Coordinates); idx += 2 {
+			p0, p1, p2 := g.Coordinates[idx], g.Coordinates[idx+1], g.Coordinates[idx+2]
+
+			if ca, ok := newArc(p0, p1, p2); ok {
+				length += ca.r * math.Abs(ca.sweep)
+			} else {
+				length += lineLength([]Coordinate{p0, p1, p2})
+			}
+		}
+
+		return length
+	case *CompoundCurve:
+		return sumMeasure(curveLength, g.Curves)
+	default:
+		return 0
+	}
+}
+
+// centroid accumulates the area, length and point weighted centres of a geometry. The OGC rules take the centroid
+// from the parts of the highest dimension that have a non-zero measure.
+type centroid struct {
+	area, ax, ay   float64
+	length, lx, ly float64
+	points, px, py float64
+}
+
+func (c *centroid) addGeometry(g Geometry) {
+	switch g := g.(type) {
+	case *Point:
+		c.addPoint(g.Coordinate)
+	case *MultiPoint:
+		for _, p := range g.Points {
+			c.addPoint(p.Coordinate)
+		}
+	case *LineString:
+		c.addLine(g.Coordinates)
+	case *MultiLineString:
+		for _, ls := range g.LineStrings {
+			c.addLine(ls.Coordinates)
+		}
+	case *Polygon:
+		c.addPolygon(g.Rings)
+	case *MultiPolygon:
+		for _, p := range g.Polygons {
+			c.addPolygon(p.Rings)
+		}
+	case *Triangle:
+		c.addPolygon(g.Rings)
+	case *PolyhedralSurface:
+		for _, p := range g.Polygons {
+			c.addPolygon(p.Rings)
+		}
+	case *TIN:
+		for _, t := range g.Triangles {
+			c.addPolygon(t.Rings)
+		}
+	case *GeometryCollection:
+		for _, m := range g.Geometries {
+			c.addGeometry(m)
+		}
+	}
+}
+
+func (c *centroid) addPoint(p Coordinate) {
+	if len(p) < 2 || math.IsNaN(p[0]) || math.IsNaN(p[1]) {
+		return
+	}
+
+	c.points++
+	c.px += p[0]
+	c.py += p[1]
+}
+
+func (c *centroid) addLine(coords []Coordinate) {
+	for idx := 1; idx < len(coords); idx++ {
+		a, b := coords[idx-1], coords[idx]
+		l := math.Hypot(b[0]-a[0], b[1]-a[1])
+
+		c.length += l
+		c.lx += l * (a[0] + b[0]) / 2
+		c.ly += l * (a[1] + b[1]) / 2
+	}
+
+	// a line of zero length counts as its points
+	for _, p := range coords {
+		c.addPoint(p)
+	}
+}
+
+func (c *centroid) addPolygon(rings []LinearRing) {
+	for idx, r := range rings {
+		a, mx, my := moments(r.Coordinates)
+
+		// shells add to the area and holes take away from it, whatever their orientation
+		sign := 1.0
+		if (a < 0) != (idx > 0) {
+			sign = -1
+		}
+
+		c.area += sign * a
+		c.ax += sign * mx
+		c.ay += sign * my
+
+		// a polygon of zero area counts as its boundary
+		c.addLine(r.Coordinates)
+	}
+}
+
+// moments returns the signed area of a ring with its first moments about the axes
+func moments(coords []Coordinate) (a, mx, my float64) {
+	if len(coords) < 3 {
+		return 0, 0, 0
+	}
+
+	ox, oy := coords[0][0], coords[0][1]
+
+	for idx := 1; idx+1 < len(coords); idx++ {
+		x0, y0 := coords[idx][0]-ox, coords[idx][1]-oy
+		x1, y1 := coords[idx+1][0]-ox, coords[idx+1][1]-oy
+		cross := x0*y1 - x1*y0
+
+		a += cross
+		mx += (x0 + x1) * cross
+		my += (y0 + y1) * cross
+	}
+
+	a /= 2
+	mx /= 6
+	my /= 6
+
+	return a, mx + ox*a, my + oy*a
+}
+
+// centre returns the centroid of the parts with the highest dimension, or false when there is nothing to measure
+func (c *centroid) centre() (x, y float64, ok bool) {
+	switch {
+	case c.area > 0:
+		return c.ax / c.area, c.ay / c.area, true
+	case c.length > 0:
+		return c.lx / c.length, c.ly / c.length, true
+	case c.points > 0:
+		return c.px / c.points, c.py / c.points, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// emptyPoint returns POINT EMPTY, whose ordinates are NaN as in WKB
+func emptyPoint(srid uint32) *Point {
+	return &Point{Hdr{XY, srid}, Coordinate{math.NaN(), math.NaN()}}
+}
+
+// Centroid returns the centre of mass of g. When g has polygonal parts with an area only those are used, otherwise
+// lines are weighted by their length and finally points are averaged. Arcs are linearized first, so the centroid of
+// a curved geometry is approximate. An empty geometry has an empty point as its centroid.
+func Centroid(g Geometry) (*Point, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var c centroid
+	c.addGeometry(lg)
+
+	x, y, ok := c.centre()
+
+	if !ok {
+		return emptyPoint(g.SRID()), nil
+	}
+
+	return &Point{Hdr{XY, g.SRID()}, Coordinate{x, y}}, nil
+}
+
+// parts is a geometry broken into its polygons, lines and points
+type parts struct {
+	polygons [][]LinearRing
+	lines    [][]Coordinate
+	points   []Coordinate
+}
+
+func (p *parts) add(g Geometry) {
+	switch g := g.(type) {
+	case *Point:
+		p.addPoint(g.Coordinate)
+	case *MultiPoint:
+		for _, pt := range g.Points {
+			p.addPoint(pt.Coordinate)
+		}
+	case *LineString:
+		p.addLine(g.Coordinates)
+	case *MultiLineString:
+		for _, ls := range g.LineStrings {
+			p.addLine(ls.Coordinates)
+		}
+	case *Polygon:
+		p.addPolygon(g.Rings)
+	case *MultiPolygon:
+		for _, poly := range g.Polygons {
+			p.addPolygon(poly.Rings)
+		}
+	case *Triangle:
+		p.addPolygon(g.Rings)
+	case *PolyhedralSurface:
+		for _, poly := range g.Polygons {
+			p.addPolygon(poly.Rings)
+		}
+	case *TIN:
+		for _, t := range g.Triangles {
+			p.addPolygon(t.Rings)
+		}
+	case *GeometryCollection:
+		for _, m := range g.Geometries {
+			p.add(m)
+		}
+	}
+}
+
+func (p *parts) addPoint(c Coordinate) {
+	if len(c) >= 2 && !math.IsNaN(c[0]) && !math.IsNaN(c[1]) {
+		p.points = append(p.points, c)
+	}
+}
+
+func (p *parts) addLine(coords []Coordinate) {
+	if len(coords) > 0 {
+		p.lines = append(p.lines, coords)
+	}
+
+	for _, c := range coords {
+		p.addPoint(c)
+	}
+}
+
+func (p *parts) addPolygon(rings []LinearRing) {
+	if len(rings) > 0 && len(rings[0].Coordinates) > 0 {
+		p.polygons = append(p.polygons, rings)
+	}
+
+	for _, r := range rings {
+		p.addLine(r.Coordinates)
+	}
+}
+
+// PointOnSurface returns a point guaranteed to lie on g: in the interior of a polygonal part when there is one,
+// otherwise on a line or at one of the points. Arcs are linearized first. An empty geometry returns an empty point.
+func PointOnSurface(g Geometry) (*Point, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var p parts
+	p.add(lg)
+
+	// the middle of the widest interior section of a horizontal line through each polygon
+	best := -1.0
+	var bx, by float64
+
+	for _, rings := range p.polygons {
+		x, y, width, ok := interiorPoint(rings)
+
+		if ok && width > best {
+			best, bx, by = width, x, y
+		}
+	}
+
+	if best >= 0 {
+		return &Point{Hdr{XY, g.SRID()}, Coordinate{bx, by}}, nil
+	}
+
+	if len(p.lines) > 0 {
+		var c centroid
+
+		for _, l := range p.lines {
+			c.addLine(l)
+		}
+
+		cx, cy, _ := c.centre()
+
+		// prefer the interior vertices, falling back to the end points
+		var candidates []Coordinate
+		for _, l := range p.lines {
+			if len(l) > 2 {
+				candidates = append(candidates, l[1:len(l)-1]...)
+			}
+		}
+
+		if len(candidates) == 0 {
+			for _, l := range p.lines {
+				candidates = append(candidates, l[0], l[len(l)-1])
+			}
+		}
+
+		x, y := nearest(candidates, cx, cy)
+
+		return &Point{Hdr{XY, g.SRID()}, Coordinate{x, y}}, nil
+	}
+
+	if len(p.points) > 0 {
+		var c centroid
+
+		for _, pt := range p.points {
+			c.addPoint(pt)
+		}
+
+		cx, cy, _ := c.centre()
+		x, y := nearest(p.points, cx, cy)
+
+		return &Point{Hdr{XY, g.SRID()}, Coordinate{x, y}}, nil
+	}
+
+	return emptyPoint(g.SRID()), nil
+}
+
+// nearest returns the coordinate closest to x, y
+func nearest(coords []Coordinate, x, y float64) (float64, float64) {
+	best := math.Inf(1)
+	var bx, by float64
+
+	for _, c := range coords {
+		d := math.Hypot(c[0]-x, c[1]-y)
+
+		if d < best {
+			best, bx, by = d, c[0], c[1]
+		}
+	}
+
+	return bx, by
+}
+
+// interiorPoint scans a polygon along a horizontal line close to the middle of its height, chosen to pass between
+// vertices, returning the centre of the widest section of the line inside the polygon
+func interiorPoint(rings []LinearRing) (x, y, width float64, ok bool) {
+	e := emptyEnvelope()
+	e = e.addCoords(rings[0].Coordinates, XY)
+
+	mid := (e.MinY + e.MaxY) / 2
+	lo, hi := e.MinY, e.MaxY
+
+	for _, r := range rings {
+		for _, c := range r.Coordinates {
+			if c[1] > mid && c[1] < hi {
+				hi = c[1]
+			}
+
+			if c[1] <= mid && c[1] > lo {
+				lo = c[1]
+			}
+		}
+	}
+
+	y = (lo + hi) / 2
+
+	var xs []float64
+	for _, r := range rings {
+		coords := r.Coordinates
+
+		for idx := 1; idx < len(coords); idx++ {
+			a, b := coords[idx-1], coords[idx]
+
+			if (a[1] > y) != (b[1] > y) {
+				xs = append(xs, a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+			}
+		}
+	}
+
+	sort.Float64s(xs)
+
+	width = -1
+	for idx := 0; idx+1 < len(xs); idx += 2 {
+		if w := xs[idx+1] - xs[idx]; w > width {
+			width, x = w, (xs[idx]+xs[idx+1])/2
+		}
+	}
+
+	return x, y, width, width >= 0
+}
@@ -0,0 +1,155 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	square = &Polygon{Hdr{XY, 0}, []LinearRing{
+		{[]Coordinate{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+		{[]Coordinate{{6, 6}, {6, 8}, {8, 8}, {8, 6}, {6, 6}}},
+	}}
+	ushape = &Polygon{Hdr{XY, 0}, []LinearRing{
+		{[]Coordinate{{0, 0}, {3, 0}, {3, 3}, {2, 3}, {2, 1}, {1, 1}, {1, 3}, {0, 3}, {0, 0}}},
+	}}
+	semicircle = &CurvePolygon{Hdr{XY, 0}, []Geometry{&CompoundCurve{Hdr{XY, 0}, []Geometry{
+		&CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}},
+		&LineString{Hdr{XY, 0}, []Coordinate{{1, 0}, {-1, 0}}},
+	}}}}
+	disc = &CurvePolygon{Hdr{XY, 0}, []Geometry{&CircularString{Hdr{XY, 0}, []Coordinate{{1, 0}, {-1, 0}, {1, 0}}}}}
+)
+
+func TestArea(t *testing.T) {
+	datasets := []struct {
+		name     string
+		data     Geometry
+		expected float64
+	}{
+		{"polygon with hole", square, 96},
+		{"multipolygon", &MultiPolygon{Hdr{XY, 0}, []Polygon{*square, *ushape}}, 103},
+		{"triangle", &Triangle{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 0}, {4, 0}, {0, 3}, {0, 0}}}}}, 6},
+		{"disc", disc, math.Pi},
+		{"semicircle", semicircle, math.Pi / 2},
+		{"collection", &GeometryCollection{Hdr{XY, 0}, []Geometry{square, &Point{Hdr{XY, 0}, Coordinate{1, 1}}}}, 96},
+		{"linestring", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}}}, 0},
+	}
+
+	for _, dataset := range datasets {
+		assert.InDelta(t, dataset.expected, Area(dataset.data), 1e-12, dataset.name)
+	}
+}
+
+func TestLengthAndPerimeter(t *testing.T) {
+	line := &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {3, 4}, {3, 10}}}
+	arc := &CircularString{Hdr{XY, 0}, []Coordinate{{-1, 0}, {0, 1}, {1, 0}}}
+
+	assert.InDelta(t, 11, Length(line), 1e-12)
+	assert.InDelta(t, math.Pi, Length(arc), 1e-12)
+	assert.InDelta(t, 22, Length(&MultiLineString{Hdr{XY, 0}, []LineString{*line, *line}}), 1e-12)
+	assert.InDelta(t, 11+math.Pi, Length(&MultiCurve{Hdr{XY, 0}, []Geometry{line, arc}}), 1e-12)
+	assert.InDelta(t, 0, Length(square), 1e-12)
+
+	assert.InDelta(t, 48, Perimeter(square), 1e-12)
+	assert.InDelta(t, 2*math.Pi, Perimeter(disc), 1e-12)
+	assert.InDelta(t, math.Pi+2, Perimeter(semicircle), 1e-12)
+	assert.InDelta(t, 0, Perimeter(line), 1e-12)
+}
+
+func TestCentroid(t *testing.T) {
+	datasets := []struct {
+		name     string
+		data     Geometry
+		expected Coordinate
+		delta    float64
+	}{
+		{"polygon with hole", square, Coordinate{(5*100 - 7*4) / 96.0, (5*100 - 7*4) / 96.0}, 1e-12},
+		{"ushape", ushape, Coordinate{1.5, (9*1.5 - 2*2) / 7}, 1e-12},
+		{"semicircle", semicircle, Coordinate{0, 4 / (3 * math.Pi)}, 1e-3},
+		{"linestring", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}, {10, 10}}}, Coordinate{7.5, 2.5}, 1e-12},
+		{"multipoint", &MultiPoint{Hdr{XY, 0}, []Point{{Hdr{XY, 0}, Coordinate{0, 0}}, {Hdr{XY, 0}, Coordinate{3, 6}}}}, Coordinate{1.5, 3}, 1e-12},
+		{"zero length line", &LineString{Hdr{XY, 0}, []Coordinate{{2, 2}, {2, 2}}}, Coordinate{2, 2}, 1e-12},
+		{
+			"highest dimension",
+			&GeometryCollection{Hdr{XY, 0}, []Geometry{ushape, &Point{Hdr{XY, 0}, Coordinate{100, 100}}}},
+			Coordinate{1.5, (9*1.5 - 2*2) / 7},
+			1e-12,
+		},
+	}
+
+	for _, dataset := range datasets {
+		p, err := Centroid(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to compute centroid of %s: err = %s", dataset.name, err)
+		}
+
+		assert.InDeltaSlice(t, dataset.expected, p.Coordinate, dataset.delta, dataset.name)
+	}
+
+	p, err := Centroid(&MultiPoint{Hdr{XY, 4326}, nil})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4326), p.SRID())
+	assert.True(t, math.IsNaN(p.Coordinate[0]))
+
+	_, err = Centroid(nil)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestPointOnSurface(t *testing.T) {
+	datasets := []struct {
+		name     string
+		data     Geometry
+		expected Coordinate
+	}{
+		// the centroid of the U lies in its notch
+		{"ushape", ushape, Coordinate{0.5, 2}},
+		{"polygon with hole", square, Coordinate{5, 3}},
+		{"disc", disc, Coordinate{0, 0}},
+		{"linestring", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 0}, {2, 0}, {10, 0}}}, Coordinate{2, 0}},
+		{"segment", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}}}, Coordinate{0, 0}},
+		{
+			"multipoint",
+			&MultiPoint{Hdr{XY, 0}, []Point{{Hdr{XY, 0}, Coordinate{0, 0}}, {Hdr{XY, 0}, Coordinate{1, 0}}, {Hdr{XY, 0}, Coordinate{5, 0}}}},
+			Coordinate{1, 0},
+		},
+		{
+			"widest polygon",
+			&MultiPolygon{Hdr{XY, 0}, []Polygon{*ushape, {Hdr{XY, 0}, []LinearRing{{[]Coordinate{{10, 0}, {20, 0}, {20, 1}, {10, 1}, {10, 0}}}}}}},
+			Coordinate{15, 0.5},
+		},
+		{
+			"flat polygon",
+			&Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 0}, {4, 0}, {2, 0}, {0, 0}}}}},
+			Coordinate{2, 0},
+		},
+	}
+
+	for _, dataset := range datasets {
+		p, err := PointOnSurface(dataset.data)
+
+		if err != nil {
+			t.Fatalf("Failed to compute point on surface of %s: err = %s", dataset.name, err)
+		}
+
+		assert.InDeltaSlice(t, dataset.expected, p.Coordinate, 1e-9, dataset.name)
+	}
+}
@@ -0,0 +1,601 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"sort"
+)
+
+// The overlay operations build the topology of the two geometries and keep the edges and nodes that lie in the result,
+// so the result is noded wherever the inputs cross and overlapping polygons are dissolved. Lower dimensional parts,
+// such as the edge shared by two adjacent polygons in an intersection, are kept alongside the polygons in a
+// GeometryCollection. Polygon shells are counter-clockwise and holes clockwise, lines are merged between the nodes
+// where they branch and keep the direction of the input. Arcs are linearized with the default options, Z and M
+// values are dropped and the result takes the SRID of a. The polygonal parts of collections are expected not to
+// overlap one another, see CascadedUnion to dissolve those. Each returns ErrNoGeometry when either geometry is nil.
+
+// Intersection returns the points shared by a and b
+func Intersection(a, b Geometry) (Geometry, error) {
+	return overlay(a, b, intersection)
+}
+
+// Union returns the points in either a or b
+func Union(a, b Geometry) (Geometry, error) {
+	return overlay(a, b, union)
+}
+
+// Difference returns the points of a that are not in b
+func Difference(a, b Geometry) (Geometry, error) {
+	return overlay(a, b, difference)
+}
+
+// SymDifference returns the points in either a or b but not in both
+func SymDifference(a, b Geometry) (Geometry, error) {
+	return overlay(a, b, symDifference)
+}
+
+// CascadedUnion returns the union of all the geometries. The parts are merged pairwise in a balanced tree of nearby
+// parts, which is much faster for large sets than adding them one at a time, and parts with separate extents are
+// combined without an overlay. Every polygon is unioned on its own, so unlike Union the polygons of a collection may
+// overlap. The result takes the SRID of the first geometry and is an empty GeometryCollection when there are none.
+func CascadedUnion(geoms []Geometry) (Geometry, error) {
+	var ops []*operand
+	var srid uint32
+
+	dim := -1
+
+	for i, g := range geoms {
+		o, err := newOperand(g)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			srid = g.SRID()
+		}
+
+		dim = maxInt(dim, o.dimension())
+		ops = append(ops, o.split()...)
+	}
+
+	return unionAll(ops).geometry(srid, dim), nil
+}
+
+// unionAll returns the union of the operands, sorting them first so the cascade merges nearby parts
+func unionAll(ops []*operand) *operand {
+	centre := func(o *operand) float64 {
+		return o.extent.MinX + o.extent.MaxX
+	}
+
+	sort.SliceStable(ops, func(i, j int) bool {
+		return centre(ops[i]) < centre(ops[j])
+	})
+
+	return cascade(ops)
+}
+
+// overlayOp is a boolean set operation, deciding whether a point is in the result from whether it is in each operand
+type overlayOp int
+
+const (
+	intersection overlayOp = iota
+	union
+	difference
+	symDifference
+)
+
+func (op overlayOp) keep(a, b bool) bool {
+	switch op {
+	case intersection:
+		return a && b
+	case union:
+		return a || b
+	case difference:
+		return a && !b
+	default:
+		return a != b
+	}
+}
+
+// dimension returns the dimension of an empty result from those of the operands
+func (op overlayOp) dimension(da, db int) int {
+	switch op {
+	case intersection:
+		if da < db {
+			return da
+		}
+
+		return db
+	case difference:
+		return da
+	default:
+		return maxInt(da, db)
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func overlay(a, b Geometry, op overlayOp) (Geometry, error) {
+	oa, err := newOperand(a)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ob, err := newOperand(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return overlayOperands(oa, ob, op).geometry(a.SRID(), op.dimension(oa.dimension(), ob.dimension())), nil
+}
+
+// overlayOperands returns the result of the operation as an operand, so it can feed further operations
+func overlayOperands(a, b *operand, op overlayOp) *operand {
+	t := newTopology(a, b)
+
+	in := func(loc [2]Location) bool {
+		return op.keep(loc[0] != Exterior, loc[1] != Exterior)
+	}
+
+	interior := func(loc [2]Location) bool {
+		return op.keep(loc[0] == Interior, loc[1] == Interior)
+	}
+
+	var boundary [][2]xy
+	var lines []*edge
+
+	// covered holds the nodes on the result linework, incident the nodes on any edge
+	covered := make(map[xy]bool)
+	incident := make(map[xy]bool)
+
+	for _, e := range t.edges {
+		incident[e.p], incident[e.q] = true, true
+		left, right := interior(e.left), interior(e.right)
+
+		switch {
+		case left && right:
+		case left:
+			boundary = append(boundary, [2]xy{e.p, e.q})
+		case right:
+			boundary = append(boundary, [2]xy{e.q, e.p})
+		case in(e.on):
+			lines = append(lines, e)
+		default:
+			continue
+		}
+
+		covered[e.p], covered[e.q] = true, true
+	}
+
+	r := &operand{polygons: buildPolygons(boundary), lines: buildLines(lines)}
+
+	for _, p := range t.order {
+		if covered[p] || !in(t.nodes[p].loc) {
+			continue
+		}
+
+		// a node on edges that are all outside the result has no area of the result around it, but a lone node may
+		// lie inside it
+		if !incident[p] && op.keep(t.ops[0].inArea(p), t.ops[1].inArea(p)) {
+			continue
+		}
+
+		r.points = append(r.points, p)
+	}
+
+	r.setExtent()
+
+	return r
+}
+
+// cascade returns the union of the operands, which are sorted so that neighbours lie near one another
+func cascade(ops []*operand) *operand {
+	switch len(ops) {
+	case 0:
+		return &operand{extent: emptyEnvelope()}
+	case 1:
+		return overlayOperands(ops[0], &operand{extent: emptyEnvelope()}, union)
+	}
+
+	a, b := cascade(ops[:len(ops)/2]), cascade(ops[len(ops)/2:])
+
+	if a.extent.Intersects(b.extent) {
+		return overlayOperands(a, b, union)
+	}
+
+	return &operand{
+		points:   append(append([]xy{}, a.points...), b.points...),
+		lines:    append(append([][]xy{}, a.lines...), b.lines...),
+		polygons: append(append([][][]xy{}, a.polygons...), b.polygons...),
+		extent:   a.extent.Union(b.extent),
+	}
+}
+
+// split breaks the operand into one operand for each polygon and one for its points and lines
+func (o *operand) split() []*operand {
+	var ops []*operand
+
+	for _, polygon := range o.polygons {
+		ops = append(ops, &operand{polygons: [][][]xy{polygon}})
+	}
+
+	if len(o.points) > 0 || len(o.lines) > 0 {
+		ops = append(ops, &operand{points: o.points, lines: o.lines})
+	}
+
+	for _, op := range ops {
+		op.setExtent()
+	}
+
+	return ops
+}
+
+func (o *operand) setExtent() {
+	o.extent = emptyEnvelope()
+
+	for _, p := range o.points {
+		o.extent = o.extent.addXY(p[0], p[1])
+	}
+
+	for _, l := range o.lines {
+		for _, p := range l {
+			o.extent = o.extent.addXY(p[0], p[1])
+		}
+	}
+
+	for _, polygon := range o.polygons {
+		for _, p := range polygon[0] {
+			o.extent = o.extent.addXY(p[0], p[1])
+		}
+	}
+}
+
+// geometry returns the simplest geometry holding the parts of the operand, or an empty geometry of the dimension
+func (o *operand) geometry(srid uint32, dim int) Geometry {
+	hdr := Hdr{XY, srid}
+	coords := func(pts []xy) []Coordinate {
+		out := make([]Coordinate, len(pts))
+
+		for i, p := range pts {
+			out[i] = Coordinate{p[0], p[1]}
+		}
+
+		return out
+	}
+	polygon := func(rings [][]xy) Polygon {
+		p := Polygon{hdr, make([]LinearRing, len(rings))}
+
+		for i, r := range rings {
+			p.Rings[i] = LinearRing{coords(r)}
+		}
+
+		return p
+	}
+
+	var geoms []Geometry
+
+	switch len(o.polygons) {
+	case 0:
+	case 1:
+		p := polygon(o.polygons[0])
+		geoms = append(geoms, &p)
+	default:
+		mp := &MultiPolygon{hdr, make([]Polygon, len(o.polygons))}
+
+		for i, rings := range o.polygons {
+			mp.Polygons[i] = polygon(rings)
+		}
+
+		geoms = append(geoms, mp)
+	}
+
+	switch len(o.lines) {
+	case 0:
+	case 1:
+		geoms = append(geoms, &LineString{hdr, coords(o.lines[0])})
+	default:
+		ml := &MultiLineString{hdr, make([]LineString, len(o.lines))}
+
+		for i, l := range o.lines {
+			ml.LineStrings[i] = LineString{hdr, coords(l)}
+		}
+
+		geoms = append(geoms, ml)
+	}
+
+	switch len(o.points) {
+	case 0:
+	case 1:
+		geoms = append(geoms, &Point{hdr, Coordinate{o.points[0][0], o.points[0][1]}})
+	default:
+		mp := &MultiPoint{hdr, make([]Point, len(o.points))}
+
+		for i, p := range o.points {
+			mp.Points[i] = Point{hdr, Coordinate{p[0], p[1]}}
+		}
+
+		geoms = append(geoms, mp)
+	}
+
+	switch len(geoms) {
+	case 0:
+	case 1:
+		return geoms[0]
+	default:
+		return &GeometryCollection{hdr, geoms}
+	}
+
+	switch dim {
+	case 0:
+		return emptyPoint(srid)
+	case 1:
+		return &LineString{hdr, nil}
+	case 2:
+		return &Polygon{hdr, nil}
+	default:
+		return &GeometryCollection{hdr, nil}
+	}
+}
+
+// buildPolygons joins the edges, each directed with the area on its left, into rings and assigns the holes to their
+// shells. At a node where several rings meet the walk takes the first edge clockwise from the one it arrived on, so
+// each ring bounds a single face of the area.
+func buildPolygons(edges [][2]xy) [][][]xy {
+	out := make(map[xy][]int)
+
+	for i, e := range edges {
+		out[e[0]] = append(out[e[0]], i)
+	}
+
+	for p, idx := range out {
+		p, idx := p, idx
+
+		sort.Slice(idx, func(i, j int) bool {
+			return angleLess(p, edges[idx[i]][1], edges[idx[j]][1])
+		})
+	}
+
+	// next returns the edge leaving the end of edge k, the first clockwise from the way back along it
+	next := func(k int) int {
+		v, back := edges[k][1], edges[k][0]
+		idx := out[v]
+
+		if len(idx) == 0 {
+			return -1
+		}
+
+		n := idx[len(idx)-1]
+
+		for _, i := range idx {
+			if !angleLess(v, edges[i][1], back) {
+				break
+			}
+
+			n = i
+		}
+
+		return n
+	}
+
+	used := make([]bool, len(edges))
+
+	var shells, holes [][]xy
+
+	for i := range edges {
+		if used[i] {
+			continue
+		}
+
+		ring := []xy{edges[i][0]}
+
+		for k := i; k >= 0 && !used[k]; k = next(k) {
+			used[k] = true
+			ring = append(ring, edges[k][1])
+		}
+
+		if ring[0] != ring[len(ring)-1] {
+			continue
+		}
+
+		for _, r := range splitRing(ring) {
+			switch area := ringArea(r); {
+			case area > 0:
+				shells = append(shells, r)
+			case area < 0:
+				holes = append(holes, r)
+			}
+		}
+	}
+
+	polygons := make([][][]xy, len(shells))
+	extents := make([]Envelope, len(shells))
+	areas := make([]float64, len(shells))
+
+	for i, s := range shells {
+		polygons[i] = [][]xy{s}
+		areas[i] = ringArea(s)
+		extents[i] = emptyEnvelope()
+
+		for _, p := range s {
+			extents[i] = extents[i].addXY(p[0], p[1])
+		}
+	}
+
+	// a hole belongs to the smallest shell around it. The middle of an edge of the hole cannot lie on another ring as
+	// the edges are noded.
+	for _, h := range holes {
+		mid := xy{(h[0][0] + h[1][0]) / 2, (h[0][1] + h[1][1]) / 2}
+		best := -1
+
+		for i, s := range shells {
+			e := extents[i]
+
+			if mid[0] < e.MinX || mid[0] > e.MaxX || mid[1] < e.MinY || mid[1] > e.MaxY {
+				continue
+			}
+
+			if (best < 0 || areas[i] < areas[best]) && inRing(mid, s) {
+				best = i
+			}
+		}
+
+		if best >= 0 {
+			polygons[best] = append(polygons[best], h)
+		}
+	}
+
+	return polygons
+}
+
+// splitRing cuts a closed ring into simple rings at the points it passes through more than once. A ring touches
+// itself where the area outside it is pinched to a point, and each loop cut off there is a hole.
+func splitRing(ring []xy) [][]xy {
+	var rings [][]xy
+	var stack []xy
+
+	seen := make(map[xy]int)
+
+	for _, p := range ring {
+		j, ok := seen[p]
+
+		if !ok {
+			seen[p] = len(stack)
+			stack = append(stack, p)
+
+			continue
+		}
+
+		rings = append(rings, append(append([]xy{}, stack[j:]...), p))
+
+		for _, q := range stack[j+1:] {
+			delete(seen, q)
+		}
+
+		stack = stack[:j+1]
+	}
+
+	return rings
+}
+
+// angleLess reports whether the direction from o to a comes before that from o to b, turning counter-clockwise from
+// the positive X axis
+func angleLess(o, a, b xy) bool {
+	half := func(p xy) int {
+		if p[1] > o[1] || (p[1] == o[1] && p[0] > o[0]) {
+			return 0
+		}
+
+		return 1
+	}
+
+	if ha, hb := half(a), half(b); ha != hb {
+		return ha < hb
+	}
+
+	return orient(o, a, b) > 0
+}
+
+// buildLines joins the edges into lines running between the nodes where the linework ends or branches. Closed loops
+// are kept whole. Each line runs the way most of its edges ran in the input.
+func buildLines(edges []*edge) [][]xy {
+	at := make(map[xy][]int)
+
+	for i, e := range edges {
+		at[e.p] = append(at[e.p], i)
+		at[e.q] = append(at[e.q], i)
+	}
+
+	used := make([]bool, len(edges))
+
+	walk := func(p xy, i int) []xy {
+		line := []xy{p}
+		score := 0
+
+		for {
+			used[i] = true
+			e := edges[i]
+			forward := e.p == p
+
+			if forward {
+				p = e.q
+			} else {
+				p = e.p
+			}
+
+			line = append(line, p)
+
+			for k := range e.line {
+				if e.line[k] {
+					if forward != e.reversed[k] {
+						score++
+					} else {
+						score--
+					}
+
+					break
+				}
+			}
+
+			if len(at[p]) != 2 {
+				break
+			}
+
+			if i = at[p][0]; used[i] {
+				i = at[p][1]
+			}
+
+			if used[i] {
+				break
+			}
+		}
+
+		if score < 0 {
+			for l, r := 0, len(line)-1; l < r; l, r = l+1, r-1 {
+				line[l], line[r] = line[r], line[l]
+			}
+		}
+
+		return line
+	}
+
+	var lines [][]xy
+
+	for i, e := range edges {
+		for _, p := range []xy{e.p, e.q} {
+			if !used[i] && len(at[p]) != 2 {
+				lines = append(lines, walk(p, i))
+			}
+		}
+	}
+
+	for i, e := range edges {
+		if !used[i] {
+			lines = append(lines, walk(e.p, i))
+		}
+	}
+
+	return lines
+}
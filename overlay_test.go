@@ -0,0 +1,167 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlay(t *testing.T) {
+	a, b := box(0, 0, 2, 2), box(1, 1, 3, 3)
+	line := &LineString{Hdr{XY, 0}, []Coordinate{{-1, 1}, {3, 1}}}
+	pt := &Point{Hdr{XY, 0}, Coordinate{1, 1}}
+
+	datasets := []struct {
+		name     string
+		op       func(a, b Geometry) (Geometry, error)
+		a, b     Geometry
+		expected Geometry
+	}{
+		{"intersection", Intersection, a, b, box(1, 1, 2, 2)},
+		{"union", Union, a, b, &Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{
+			{0, 0}, {2, 0}, {2, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 2}, {0, 2}, {0, 0}}}}}},
+		{"difference", Difference, a, b, &Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{
+			{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}, {0, 0}}}}}},
+		{"symdifference", SymDifference, a, b, &MultiPolygon{Hdr{XY, 0}, []Polygon{
+			{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}, {0, 0}}}}},
+			{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{2, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 2}, {2, 2}, {2, 1}}}}},
+		}}},
+		{"hole", Difference, box(0, 0, 4, 4), box(1, 1, 2, 2), &Polygon{Hdr{XY, 0}, []LinearRing{
+			{[]Coordinate{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}},
+			{[]Coordinate{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}}},
+		}}},
+		{"adjacent", Intersection, a, box(2, 0, 4, 2), &LineString{Hdr{XY, 0}, []Coordinate{{2, 0}, {2, 2}}}},
+		{"corner", Intersection, a, box(2, 2, 4, 4), &Point{Hdr{XY, 0}, Coordinate{2, 2}}},
+		{"clip line", Intersection, line, a, &LineString{Hdr{XY, 0}, []Coordinate{{0, 1}, {2, 1}}}},
+		{"cut line", Difference, line, a, &MultiLineString{Hdr{XY, 0}, []LineString{
+			{Hdr{XY, 0}, []Coordinate{{-1, 1}, {0, 1}}},
+			{Hdr{XY, 0}, []Coordinate{{2, 1}, {3, 1}}},
+		}}},
+		{"mixed", Union, a, line, &GeometryCollection{Hdr{XY, 0}, []Geometry{a, &MultiLineString{Hdr{XY, 0}, []LineString{
+			{Hdr{XY, 0}, []Coordinate{{-1, 1}, {0, 1}}},
+			{Hdr{XY, 0}, []Coordinate{{2, 1}, {3, 1}}},
+		}}}}},
+		{"point in polygon", Union, pt, a, a},
+		{"point on line", Intersection, line, pt, pt},
+	}
+
+	for _, dataset := range datasets {
+		g, err := dataset.op(dataset.a, dataset.b)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected.Type(), g.Type(), dataset.name)
+
+		eq, err := Equals(dataset.expected, g)
+		assert.NoError(t, err, dataset.name)
+		assert.True(t, eq, dataset.name)
+	}
+
+	// arcs are linearized with the default options
+	lg, _ := Linearize(disc)
+	g, err := Intersection(disc, box(0, -2, 2, 2))
+	assert.NoError(t, err)
+	assert.InDelta(t, Area(lg)/2, Area(g), 1e-12)
+}
+
+func TestOverlayEmpty(t *testing.T) {
+	a := &Polygon{Hdr{XY, 4326}, []LinearRing{{[]Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}}}
+	line := &LineString{Hdr{XY, 0}, []Coordinate{{5, 5}, {6, 6}}}
+
+	datasets := []struct {
+		name     string
+		op       func(a, b Geometry) (Geometry, error)
+		a, b     Geometry
+		expected Geometry
+	}{
+		{"polygons", Intersection, a, box(2, 2, 3, 3), &Polygon{Hdr{XY, 4326}, nil}},
+		{"lowest dimension", Intersection, a, line, &LineString{Hdr{XY, 4326}, nil}},
+		{"difference", Difference, a, box(-1, -1, 2, 2), &Polygon{Hdr{XY, 4326}, nil}},
+		{"empty", Union, &GeometryCollection{Hdr{XY, 4326}, nil}, &MultiPolygon{}, &GeometryCollection{Hdr{XY, 4326}, nil}},
+	}
+
+	for _, dataset := range datasets {
+		g, err := dataset.op(dataset.a, dataset.b)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	_, err := Union(a, nil)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestOverlayOrientation(t *testing.T) {
+	cw := &Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}}}}
+
+	g, err := Difference(cw, box(1, 1, 2, 2))
+	assert.NoError(t, err)
+
+	p := g.(*Polygon)
+	assert.Len(t, p.Rings, 2)
+	assert.True(t, signedArea(p.Rings[0].Coordinates) > 0)
+	assert.True(t, signedArea(p.Rings[1].Coordinates) < 0)
+}
+
+func TestOverlayLines(t *testing.T) {
+	// lines keep their direction and are merged through the nodes where they do not branch
+	line := &LineString{Hdr{XY, 0}, []Coordinate{{3, 1}, {1, 1}, {1, 3}}}
+	g, err := Union(line, &Point{Hdr{XY, 0}, Coordinate{2, 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, &LineString{Hdr{XY, 0}, []Coordinate{{3, 1}, {2, 1}, {1, 1}, {1, 3}}}, g)
+
+	g, err = Difference(line, box(0, 0, 2, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, &MultiLineString{Hdr{XY, 0}, []LineString{
+		{Hdr{XY, 0}, []Coordinate{{3, 1}, {2, 1}}},
+		{Hdr{XY, 0}, []Coordinate{{1, 2}, {1, 3}}},
+	}}, g)
+
+	// crossing lines are noded where they cross
+	cross := &LineString{Hdr{XY, 0}, []Coordinate{{2, 0}, {2, 2}}}
+	g, err = Union(line, cross)
+	assert.NoError(t, err)
+	assert.Len(t, g.(*MultiLineString).LineStrings, 4)
+}
+
+func TestCascadedUnion(t *testing.T) {
+	var geoms []Geometry
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			geoms = append(geoms, box(float64(i), float64(j), float64(i)+1.5, float64(j)+1.5))
+		}
+	}
+
+	// a separate part and a multi polygon whose parts overlap
+	geoms = append(geoms, box(20, 20, 21, 21), &MultiPolygon{Hdr{XY, 0}, []Polygon{
+		*box(30, 30, 32, 32), *box(31, 31, 33, 33),
+	}})
+
+	g, err := CascadedUnion(geoms)
+	assert.NoError(t, err)
+
+	mp := g.(*MultiPolygon)
+	assert.Len(t, mp.Polygons, 3)
+	assert.InDelta(t, 10.5*10.5+1+7, Area(g), 1e-9)
+
+	g, err = CascadedUnion(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &GeometryCollection{Hdr{XY, 0}, nil}, g)
+
+	_, err = CascadedUnion([]Geometry{square, nil})
+	assert.Equal(t, ErrNoGeometry, err)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+// The predicates follow the OGC Simple Features definitions in terms of the DE-9IM matrix, see Relate. Each returns
+// ErrNoGeometry when either geometry is nil.
+
+// Equals reports whether a and b cover the same points, regardless of how they are made up. Two empty geometries are
+// equal.
+func Equals(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		if da < 0 && db < 0 {
+			return true
+		}
+
+		return da == db && m.matches("T*F**FFF*")
+	})
+}
+
+// Disjoint reports whether a and b have no point in common
+func Disjoint(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		return m.matches("FF*FF****")
+	})
+}
+
+// Intersects reports whether a and b have at least one point in common
+func Intersects(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		return !m.matches("FF*FF****")
+	})
+}
+
+// Touches reports whether a and b meet only at their boundaries. Points have no boundary so never touch each other.
+func Touches(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		if da == 0 && db == 0 {
+			return false
+		}
+
+		return m.matches("FT*******") || m.matches("F**T*****") || m.matches("F***T****")
+	})
+}
+
+// Crosses reports whether a and b share some interior points but not all of them, and the intersection has a lower
+// dimension than the larger of the two. Only points and lines crossing lines or areas, or lines crossing each other at
+// points, can cross.
+func Crosses(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		switch {
+		case da < db && da >= 0 && db > 0:
+			return m.matches("T*T******")
+		case da > db && db >= 0:
+			return m.matches("T*****T**")
+		case da == 1 && db == 1:
+			return m.matches("0********")
+		default:
+			return false
+		}
+	})
+}
+
+// Within reports whether every point of a lies in b and their interiors meet
+func Within(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		return m.matches("T*F**F***")
+	})
+}
+
+// Contains reports whether every point of b lies in a and their interiors meet. A polygon does not contain a line
+// lying entirely along its boundary, see Covers.
+func Contains(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		return m.matches("T*****FF*")
+	})
+}
+
+// Overlaps reports whether a and b have the same dimension, share some but not all of their points, and the shared
+// points have that dimension too
+func Overlaps(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		switch {
+		case da != db:
+			return false
+		case da == 1:
+			return m.matches("1*T***T**")
+		default:
+			return m.matches("T*T***T**")
+		}
+	})
+}
+
+// Covers reports whether every point of b lies in a. Unlike Contains the interiors need not meet.
+func Covers(a, b Geometry) (bool, error) {
+	return predicate(a, b, func(m IntersectionMatrix, da, db int) bool {
+		return m.matches("T*****FF*") || m.matches("*T****FF*") || m.matches("***T**FF*") || m.matches("****T*FF*")
+	})
+}
+
+// CoveredBy reports whether every point of a lies in b, the converse of Covers
+func CoveredBy(a, b Geometry) (bool, error) {
+	return Covers(b, a)
+}
+
+func predicate(a, b Geometry, test func(m IntersectionMatrix, da, db int) bool) (bool, error) {
+	oa, err := newOperand(a)
+
+	if err != nil {
+		return false, err
+	}
+
+	ob, err := newOperand(b)
+
+	if err != nil {
+		return false, err
+	}
+
+	return test(relate(oa, ob), oa.dimension(), ob.dimension()), nil
+}
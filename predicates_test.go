@@ -0,0 +1,72 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredicates(t *testing.T) {
+	pt := &Point{Hdr{XY, 0}, Coordinate{1, 1}}
+	edge := &Point{Hdr{XY, 0}, Coordinate{0, 1}}
+	inside := &LineString{Hdr{XY, 0}, []Coordinate{{0.5, 0.5}, {1.5, 1.5}}}
+	across := &LineString{Hdr{XY, 0}, []Coordinate{{1, 1}, {3, 1}}}
+	other := &LineString{Hdr{XY, 0}, []Coordinate{{2, 0}, {2, 3}}}
+	overlap := &LineString{Hdr{XY, 0}, []Coordinate{{2, 1}, {4, 1}}}
+	a, b, c := box(0, 0, 2, 2), box(1, 1, 3, 3), box(2, 0, 4, 2)
+
+	predicates := []struct {
+		name string
+		fn   func(a, b Geometry) (bool, error)
+	}{
+		{"equals", Equals}, {"disjoint", Disjoint}, {"intersects", Intersects}, {"touches", Touches},
+		{"crosses", Crosses}, {"within", Within}, {"contains", Contains}, {"overlaps", Overlaps},
+		{"covers", Covers}, {"coveredby", CoveredBy},
+	}
+
+	datasets := []struct {
+		name     string
+		a, b     Geometry
+		expected []bool // in the order of predicates
+	}{
+		{"point in polygon", pt, a, []bool{false, false, true, false, false, true, false, false, false, true}},
+		{"polygon contains point", a, pt, []bool{false, false, true, false, false, false, true, false, true, false}},
+		{"point on boundary", edge, a, []bool{false, false, true, true, false, false, false, false, false, true}},
+		{"point outside", pt, c, []bool{false, true, false, false, false, false, false, false, false, false}},
+		{"line in polygon", inside, a, []bool{false, false, true, false, false, true, false, false, false, true}},
+		{"line across polygon", across, a, []bool{false, false, true, false, true, false, false, false, false, false}},
+		{"crossing lines", across, other, []bool{false, false, true, false, true, false, false, false, false, false}},
+		{"overlapping lines", across, overlap, []bool{false, false, true, false, false, false, false, true, false, false}},
+		{"overlapping polygons", a, b, []bool{false, false, true, false, false, false, false, true, false, false}},
+		{"adjacent polygons", a, c, []bool{false, false, true, true, false, false, false, false, false, false}},
+		{"equal polygons", a, box(0, 0, 2, 2), []bool{true, false, true, false, false, true, true, false, true, true}},
+		{"polygon in hole", box(6.5, 6.5, 7.5, 7.5), square, []bool{false, true, false, false, false, false, false, false, false, false}},
+	}
+
+	for _, dataset := range datasets {
+		for i, p := range predicates {
+			ok, err := p.fn(dataset.a, dataset.b)
+			assert.NoError(t, err, dataset.name)
+			assert.Equal(t, dataset.expected[i], ok, "%s: %s", dataset.name, p.name)
+		}
+	}
+
+	_, err := Intersects(pt, nil)
+	assert.Equal(t, ErrNoGeometry, err)
+}
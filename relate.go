@@ -0,0 +1,127 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+// Location is the part of a geometry a point lies in under the Dimensionally Extended 9 Intersection Model (DE-9IM)
+type Location int
+
+const (
+	// Interior is the geometry less its boundary
+	Interior Location = iota
+	// Boundary is the rings of a polygon and the end points of a line. Points and closed lines have no boundary.
+	Boundary
+	// Exterior is everything outside the geometry
+	Exterior
+)
+
+// IntersectionMatrix is the DE-9IM matrix of two geometries a and b. The entry m[i][j] is the dimension of the
+// intersection of location i of a with location j of b: 0 for points, 1 for lines, 2 for areas and -1 when they do not
+// meet.
+type IntersectionMatrix [3][3]int
+
+// Relate returns the DE-9IM matrix describing how a and b meet. Arcs are linearized with the default options and the
+// polygonal parts of collections are expected not to overlap one another.
+func Relate(a, b Geometry) (IntersectionMatrix, error) {
+	oa, err := newOperand(a)
+
+	if err != nil {
+		return IntersectionMatrix{}, err
+	}
+
+	ob, err := newOperand(b)
+
+	if err != nil {
+		return IntersectionMatrix{}, err
+	}
+
+	return relate(oa, ob), nil
+}
+
+func relate(a, b *operand) IntersectionMatrix {
+	m := IntersectionMatrix{{-1, -1, -1}, {-1, -1, -1}, {-1, -1, 2}}
+	t := newTopology(a, b)
+
+	for _, n := range t.nodes {
+		m.set(n.loc[0], n.loc[1], 0)
+	}
+
+	for _, e := range t.edges {
+		m.set(e.on[0], e.on[1], 1)
+		m.set(e.left[0], e.left[1], 2)
+		m.set(e.right[0], e.right[1], 2)
+	}
+
+	return m
+}
+
+func (m *IntersectionMatrix) set(i, j Location, dim int) {
+	if m[i][j] < dim {
+		m[i][j] = dim
+	}
+}
+
+// String returns the matrix in the usual row major form, such as "212101212", with F for the empty entries
+func (m IntersectionMatrix) String() string {
+	out := make([]byte, 0, 9)
+
+	for _, row := range m {
+		for _, dim := range row {
+			if dim < 0 {
+				out = append(out, 'F')
+			} else {
+				out = append(out, byte('0'+dim))
+			}
+		}
+	}
+
+	return string(out)
+}
+
+// Matches reports whether the matrix satisfies a DE-9IM pattern, such as "T*F**F***". Each of the 9 characters of
+// the pattern constrains the matching entry: T to any intersection, F to none, 0, 1 or 2 to that dimension and * to
+// anything. ErrPattern is returned for a malformed pattern.
+func (m IntersectionMatrix) Matches(pattern string) (bool, error) {
+	if len(pattern) != 9 {
+		return false, ErrPattern
+	}
+
+	matches := true
+
+	for idx := 0; idx < 9; idx++ {
+		dim := m[idx/3][idx%3]
+
+		switch pattern[idx] {
+		case '*':
+		case 'T', 't':
+			matches = matches && dim >= 0
+		case 'F', 'f':
+			matches = matches && dim < 0
+		case '0', '1', '2':
+			matches = matches && dim == int(pattern[idx]-'0')
+		default:
+			return false, ErrPattern
+		}
+	}
+
+	return matches, nil
+}
+
+// matches is Matches for the fixed patterns of the predicates
+func (m IntersectionMatrix) matches(pattern string) bool {
+	ok, _ := m.Matches(pattern)
+	return ok
+}
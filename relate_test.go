@@ -0,0 +1,88 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func box(x0, y0, x1, y1 float64) *Polygon {
+	return &Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}}}}
+}
+
+func TestRelate(t *testing.T) {
+	datasets := []struct {
+		name     string
+		a, b     Geometry
+		expected string
+	}{
+		{"point in polygon", &Point{Hdr{XY, 0}, Coordinate{1, 1}}, square, "0FFFFF212"},
+		{"point in hole", &Point{Hdr{XY, 0}, Coordinate{7, 7}}, square, "FF0FFF212"},
+		{"point on boundary", &Point{Hdr{XY, 0}, Coordinate{0, 5}}, square, "F0FFFF212"},
+		{"equal points", &Point{Hdr{XY, 0}, Coordinate{1, 1}}, &Point{Hdr{XY, 0}, Coordinate{1, 1}}, "0FFFFFFF2"},
+		{"disjoint points", &Point{Hdr{XY, 0}, Coordinate{1, 1}}, &Point{Hdr{XY, 0}, Coordinate{2, 1}}, "FF0FFF0F2"},
+		{"crossing lines", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {2, 2}}}, &LineString{Hdr{XY, 0}, []Coordinate{{0, 2}, {2, 0}}}, "0F1FF0102"},
+		{"overlapping lines", &LineString{Hdr{XY, 0}, []Coordinate{{0, 0}, {2, 0}}}, &LineString{Hdr{XY, 0}, []Coordinate{{1, 0}, {3, 0}}}, "1010F0102"},
+		{"line in polygon", &LineString{Hdr{XY, 0}, []Coordinate{{1, 1}, {2, 2}}}, square, "1FF0FF212"},
+		{"line across hole", &LineString{Hdr{XY, 0}, []Coordinate{{5, 7}, {9, 7}}}, square, "1010FF212"},
+		{"overlapping polygons", box(0, 0, 2, 2), box(1, 1, 3, 3), "212101212"},
+		{"adjacent polygons", box(0, 0, 2, 2), box(2, 0, 4, 2), "FF2F11212"},
+		{"equal polygons", box(0, 0, 2, 2), &Polygon{Hdr{XY, 0}, []LinearRing{{[]Coordinate{{0, 0}, {0, 2}, {2, 2}, {2, 0}, {0, 0}}}}}, "2FFF1FFF2"},
+		{"polygon in hole", box(6.5, 6.5, 7.5, 7.5), square, "FF2FF1212"},
+		{"curve", &Point{Hdr{XY, 0}, Coordinate{0, 0.5}}, disc, "0FFFFF212"},
+	}
+
+	for _, dataset := range datasets {
+		m, err := Relate(dataset.a, dataset.b)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, m.String(), dataset.name)
+	}
+
+	_, err := Relate(nil, square)
+	assert.Equal(t, ErrNoGeometry, err)
+}
+
+func TestMatches(t *testing.T) {
+	m, err := Relate(box(0, 0, 2, 2), box(1, 1, 3, 3))
+	assert.NoError(t, err)
+
+	datasets := []struct {
+		pattern  string
+		expected bool
+	}{
+		{"212101212", true},
+		{"T*T***T**", true},
+		{"t*t***t**", true},
+		{"*********", true},
+		{"FF*FF****", false},
+		{"2**1*****", true},
+		{"0********", false},
+	}
+
+	for _, dataset := range datasets {
+		ok, err := m.Matches(dataset.pattern)
+		assert.NoError(t, err, dataset.pattern)
+		assert.Equal(t, dataset.expected, ok, dataset.pattern)
+	}
+
+	for _, pattern := range []string{"", "T*T***T*", "T*T***T**F", "X********"} {
+		_, err := m.Matches(pattern)
+		assert.Equal(t, ErrPattern, err, pattern)
+	}
+}
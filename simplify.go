@@ -0,0 +1,608 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Simplification applies to the lines and polygon rings of a geometry, removing vertices while keeping the end points
+// of each line and the start of each ring. The vertices kept are copied unchanged, with any Z and M values. A closed
+// line or ring also keeps the vertex farthest from its start, so it never collapses to a single point. A ring left
+// with fewer than four points is dropped, taking its polygon with it when it is the shell, so the result may be empty.
+// Points are returned unchanged, the members of a GeometryCollection are simplified in turn and arcs are linearized
+// with the default options. Each returns ErrNoGeometry when g is nil and ErrUnsupportedOperation for triangles and
+// surfaces.
+
+// Simplify removes vertices with the Douglas-Peucker algorithm, keeping every vertex farther than tolerance from the
+// simplified line. The result may cross itself, see SimplifyPreserveTopology.
+func Simplify(g Geometry, tolerance float64) (Geometry, error) {
+	return simplify(g, tolerance, douglasPeucker)
+}
+
+// SimplifyVW removes vertices with the Visvalingam-Whyatt algorithm, repeatedly dropping the vertex that forms the
+// smallest triangle with its neighbours while that triangle has less than the given area. The result may cross
+// itself.
+func SimplifyVW(g Geometry, area float64) (Geometry, error) {
+	return simplify(g, area, visvalingam)
+}
+
+// SimplifyPreserveTopology removes vertices as Simplify does, but keeps any vertex whose removal would make a line
+// or ring cross or touch itself or another, move a ring or line to the other side of another, or leave a ring with
+// fewer than four points. A valid geometry stays valid and keeps all of its rings.
+func SimplifyPreserveTopology(g Geometry, tolerance float64) (Geometry, error) {
+	return simplify(g, tolerance, preserveTopology)
+}
+
+// simpleLine is a line or ring being simplified, with the vertices to keep
+type simpleLine struct {
+	coords []Coordinate
+	pts    []xy
+	keep   []bool
+	ring   bool
+}
+
+func newSimpleLine(coords []Coordinate, ring bool) *simpleLine {
+	l := &simpleLine{coords: coords, pts: make([]xy, len(coords)), keep: make([]bool, len(coords)), ring: ring}
+
+	for i, c := range coords {
+		if len(c) < 2 {
+			l.pts[i] = xy{math.NaN(), math.NaN()}
+		} else {
+			l.pts[i] = xy{c[0], c[1]}
+		}
+	}
+
+	return l
+}
+
+func (l *simpleLine) closed() bool {
+	return len(l.pts) > 1 && l.pts[0] == l.pts[len(l.pts)-1]
+}
+
+// anchors returns the vertices that are always kept, in order: the ends and, for a closed line, the vertex farthest
+// from the start
+func (l *simpleLine) anchors() []int {
+	n := len(l.pts)
+
+	if n < 3 {
+		anchors := make([]int, n)
+
+		for i := range anchors {
+			anchors[i] = i
+		}
+
+		return anchors
+	}
+
+	if !l.closed() {
+		return []int{0, n - 1}
+	}
+
+	f, d := 0, -1.0
+
+	for i := 1; i < n-1; i++ {
+		if di := math.Hypot(l.pts[i][0]-l.pts[0][0], l.pts[i][1]-l.pts[0][1]); di > d {
+			f, d = i, di
+		}
+	}
+
+	return []int{0, f, n - 1}
+}
+
+// farthest returns the vertex between i and j farthest from the segment joining them, and its distance
+func (l *simpleLine) farthest(i, j int) (int, float64) {
+	k, d := -1, -1.0
+
+	for m := i + 1; m < j; m++ {
+		if dm := segmentDistance(l.pts[m], l.pts[i], l.pts[j]); dm > d {
+			k, d = m, dm
+		}
+	}
+
+	return k, d
+}
+
+func (l *simpleLine) simplified() []Coordinate {
+	var out []Coordinate
+
+	for i, c := range l.coords {
+		if l.keep[i] {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
+
+// simplification holds the lines of a geometry in the order they are met, so they can be simplified together and
+// then put back
+type simplification struct {
+	lines []*simpleLine
+	next  int
+}
+
+func simplify(g Geometry, tolerance float64, method func([]*simpleLine, float64)) (Geometry, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &simplification{}
+
+	if err := s.collect(lg); err != nil {
+		return nil, err
+	}
+
+	method(s.lines, tolerance)
+
+	return s.build(lg), nil
+}
+
+func (s *simplification) collect(g Geometry) error {
+	switch g := g.(type) {
+	case *Point, *MultiPoint:
+	case *LineString:
+		s.lines = append(s.lines, newSimpleLine(g.Coordinates, false))
+	case *MultiLineString:
+		for _, ls := range g.LineStrings {
+			s.lines = append(s.lines, newSimpleLine(ls.Coordinates, false))
+		}
+	case *Polygon:
+		s.collectRings(g.Rings)
+	case *MultiPolygon:
+		for _, p := range g.Polygons {
+			s.collectRings(p.Rings)
+		}
+	case *GeometryCollection:
+		for _, m := range g.Geometries {
+			if err := s.collect(m); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrUnsupportedOperation
+	}
+
+	return nil
+}
+
+func (s *simplification) collectRings(rings []LinearRing) {
+	for _, r := range rings {
+		s.lines = append(s.lines, newSimpleLine(r.Coordinates, true))
+	}
+}
+
+func (s *simplification) line() []Coordinate {
+	l := s.lines[s.next]
+	s.next++
+
+	return l.simplified()
+}
+
+// rings returns the simplified rings, or nil when the shell has collapsed
+func (s *simplification) rings(rings []LinearRing) []LinearRing {
+	var out []LinearRing
+
+	for idx := range rings {
+		coords := s.line()
+
+		if len(coords) < 4 && len(rings[idx].Coordinates) >= 4 {
+			if idx == 0 {
+				s.next += len(rings) - 1
+				return nil
+			}
+
+			continue
+		}
+
+		out = append(out, LinearRing{coords})
+	}
+
+	return out
+}
+
+func (s *simplification) build(g Geometry) Geometry {
+	switch g := g.(type) {
+	case *LineString:
+		return &LineString{g.Hdr, s.line()}
+	case *MultiLineString:
+		out := &MultiLineString{g.Hdr, make([]LineString, len(g.LineStrings))}
+
+		for i, ls := range g.LineStrings {
+			out.LineStrings[i] = LineString{ls.Hdr, s.line()}
+		}
+
+		return out
+	case *Polygon:
+		return &Polygon{g.Hdr, s.rings(g.Rings)}
+	case *MultiPolygon:
+		out := &MultiPolygon{Hdr: g.Hdr}
+
+		for _, p := range g.Polygons {
+			if rings := s.rings(p.Rings); rings != nil {
+				out.Polygons = append(out.Polygons, Polygon{p.Hdr, rings})
+			}
+		}
+
+		return out
+	case *GeometryCollection:
+		out := &GeometryCollection{g.Hdr, make([]Geometry, len(g.Geometries))}
+
+		for i, m := range g.Geometries {
+			out.Geometries[i] = s.build(m)
+		}
+
+		return out
+	default:
+		return g
+	}
+}
+
+func douglasPeucker(lines []*simpleLine, tolerance float64) {
+	var dp func(l *simpleLine, i, j int)
+
+	dp = func(l *simpleLine, i, j int) {
+		if k, d := l.farthest(i, j); k >= 0 && d > tolerance {
+			l.keep[k] = true
+			dp(l, i, k)
+			dp(l, k, j)
+		}
+	}
+
+	for _, l := range lines {
+		anchors := l.anchors()
+
+		for k, a := range anchors {
+			l.keep[a] = true
+
+			if k > 0 {
+				dp(l, anchors[k-1], a)
+			}
+		}
+	}
+}
+
+// vertexArea is a vertex waiting to be removed, with the area of the triangle it forms with its neighbours
+type vertexArea struct {
+	idx  int
+	area float64
+	gen  int
+}
+
+type vertexHeap []vertexArea
+
+func (h vertexHeap) Len() int            { return len(h) }
+func (h vertexHeap) Less(i, j int) bool  { return h[i].area < h[j].area }
+func (h vertexHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *vertexHeap) Push(x interface{}) { *h = append(*h, x.(vertexArea)) }
+func (h *vertexHeap) Pop() interface{} {
+	old := *h
+	v := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return v
+}
+
+func visvalingam(lines []*simpleLine, area float64) {
+	for _, l := range lines {
+		n := len(l.pts)
+		prev, next, gen := make([]int, n), make([]int, n), make([]int, n)
+		anchor := make([]bool, n)
+
+		for _, a := range l.anchors() {
+			anchor[a] = true
+		}
+
+		for i := range l.pts {
+			prev[i], next[i] = i-1, i+1
+			l.keep[i] = true
+		}
+
+		triangle := func(i int) float64 {
+			a, b, c := l.pts[prev[i]], l.pts[i], l.pts[next[i]]
+			return math.Abs((b[0]-a[0])*(c[1]-a[1])-(c[0]-a[0])*(b[1]-a[1])) / 2
+		}
+
+		h := &vertexHeap{}
+
+		for i := range l.pts {
+			if !anchor[i] {
+				heap.Push(h, vertexArea{i, triangle(i), 0})
+			}
+		}
+
+		// entries left behind when a neighbour is removed are skipped by their generation
+		for h.Len() > 0 {
+			v := heap.Pop(h).(vertexArea)
+
+			if v.gen != gen[v.idx] {
+				continue
+			}
+
+			if !(v.area < area) {
+				break
+			}
+
+			l.keep[v.idx] = false
+			p, q := prev[v.idx], next[v.idx]
+			next[p], prev[q] = q, p
+
+			for _, k := range []int{p, q} {
+				if !anchor[k] {
+					gen[k]++
+					heap.Push(h, vertexArea{k, triangle(k), gen[k]})
+				}
+			}
+		}
+	}
+}
+
+// simpleSegment is a segment of a line being simplified, joining the kept vertices i and j
+type simpleSegment struct {
+	line, i, j int
+	a, b       xy
+	alive      bool
+
+	// seen and scanned mark the segments already visited by the current query and row scan
+	seen, scanned int
+}
+
+// segmentGrid finds the segments near a box, bucketing them into square cells
+type segmentGrid struct {
+	cell  float64
+	cells map[[2]int64][]*simpleSegment
+	query int
+	scan  int
+}
+
+func (g *segmentGrid) key(x, y float64) [2]int64 {
+	return [2]int64{int64(math.Floor(x / g.cell)), int64(math.Floor(y / g.cell))}
+}
+
+// add files the segment under the cells its box covers. Segments with coordinates that are not finite are left out.
+func (g *segmentGrid) add(s *simpleSegment) {
+	for _, v := range []float64{s.a[0], s.a[1], s.b[0], s.b[1]} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return
+		}
+	}
+
+	lo := g.key(math.Min(s.a[0], s.b[0]), math.Min(s.a[1], s.b[1]))
+	hi := g.key(math.Max(s.a[0], s.b[0]), math.Max(s.a[1], s.b[1]))
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			g.cells[[2]int64{x, y}] = append(g.cells[[2]int64{x, y}], s)
+		}
+	}
+}
+
+// each calls fn once for every live segment in the cells the box covers until fn returns false
+func (g *segmentGrid) each(e Envelope, fn func(*simpleSegment) bool) bool {
+	g.query++
+	lo, hi := g.key(e.MinX, e.MinY), g.key(e.MaxX, e.MaxY)
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for _, s := range g.cells[[2]int64{x, y}] {
+				if s.alive && s.seen != g.query {
+					s.seen = g.query
+
+					if !fn(s) {
+						return false
+					}
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// row calls fn once for every live segment in the cells of the row holding v, from v up to maxX
+func (g *segmentGrid) row(v xy, maxX float64, fn func(*simpleSegment)) {
+	g.scan++
+	lo, hi := g.key(v[0], v[1]), g.key(maxX, v[1])
+
+	for x := lo[0]; x <= hi[0]; x++ {
+		for _, s := range g.cells[[2]int64{x, lo[1]}] {
+			if s.alive && s.scanned != g.scan {
+				s.scanned = g.scan
+				fn(s)
+			}
+		}
+	}
+}
+
+// topologySimplifier runs Douglas-Peucker over all the lines together, only replacing a run of vertices by a single
+// segment when nothing else is caught between them
+type topologySimplifier struct {
+	lines     []*simpleLine
+	segments  [][]*simpleSegment
+	grid      *segmentGrid
+	tolerance float64
+}
+
+func preserveTopology(lines []*simpleLine, tolerance float64) {
+	t := &topologySimplifier{lines: lines, segments: make([][]*simpleSegment, len(lines)), tolerance: tolerance}
+	extent := emptyEnvelope()
+	n := 0
+
+	for _, l := range lines {
+		for _, p := range l.pts {
+			if !math.IsNaN(p[0]) && !math.IsNaN(p[1]) && !math.IsInf(p[0], 0) && !math.IsInf(p[1], 0) {
+				extent = extent.addXY(p[0], p[1])
+			}
+		}
+
+		n += len(l.pts)
+	}
+
+	t.grid = &segmentGrid{cells: make(map[[2]int64][]*simpleSegment)}
+
+	if !extent.IsEmpty() {
+		t.grid.cell = math.Max(extent.MaxX-extent.MinX, extent.MaxY-extent.MinY) / math.Ceil(math.Sqrt(float64(n)))
+	}
+
+	if !(t.grid.cell > 0) {
+		t.grid.cell = 1
+	}
+
+	for li, l := range lines {
+		for i := 1; i < len(l.pts); i++ {
+			s := &simpleSegment{line: li, i: i - 1, j: i, a: l.pts[i-1], b: l.pts[i], alive: true}
+			t.segments[li] = append(t.segments[li], s)
+			t.grid.add(s)
+		}
+	}
+
+	for li, l := range lines {
+		size := len(l.pts)
+		min := 2
+
+		if l.ring || l.closed() {
+			min = 4
+		}
+
+		anchors := l.anchors()
+
+		for k, a := range anchors {
+			l.keep[a] = true
+
+			if k > 0 {
+				t.simplify(li, anchors[k-1], a, &size, min)
+			}
+		}
+	}
+}
+
+// simplify works on the vertices of line li between i and j, size being the number of vertices the line has left
+func (t *topologySimplifier) simplify(li, i, j int, size *int, min int) {
+	l := t.lines[li]
+	k, d := l.farthest(i, j)
+
+	if k < 0 {
+		return
+	}
+
+	if d <= t.tolerance && *size-(j-i-1) >= min && t.flattens(li, i, j, d) {
+		for _, s := range t.segments[li][i:j] {
+			s.alive = false
+		}
+
+		t.grid.add(&simpleSegment{line: li, i: i, j: j, a: l.pts[i], b: l.pts[j], alive: true})
+		*size -= j - i - 1
+
+		return
+	}
+
+	l.keep[k] = true
+	t.simplify(li, i, k, size, min)
+	t.simplify(li, k, j, size, min)
+}
+
+// flattens reports whether the vertices of line li between i and j can be replaced by the segment joining them:
+// the segment must not meet any other, and no other vertex may lie in the area between the segment and the
+// vertices it replaces, where the line would pass over it. That area lies within d, the greatest distance of the
+// vertices from the segment, of the segment.
+func (t *topologySimplifier) flattens(li, i, j int, d float64) bool {
+	pts := t.lines[li].pts
+	a, b := pts[i], pts[j]
+
+	if a == b {
+		return false
+	}
+
+	own := func(s *simpleSegment) bool {
+		return s.line == li && s.i >= i && s.j <= j
+	}
+
+	chord := emptyEnvelope().addXY(a[0], a[1]).addXY(b[0], b[1])
+
+	ok := t.grid.each(chord, func(s *simpleSegment) bool {
+		return own(s) || !segmentsMeet(a, b, s.a, s.b)
+	})
+
+	if !ok {
+		return false
+	}
+
+	extent := chord.Expand(d)
+
+	// inside casts a ray from v along X through the cells of its row, counting the crossings of the vertices being
+	// replaced and of the segment replacing them
+	inside := func(v xy) bool {
+		if v == a || v == b || segmentDistance(v, a, b) > d*(1+1e-9) {
+			return false
+		}
+
+		n := 0
+		on := false
+
+		cross := func(p, q xy) {
+			if p[1] > q[1] {
+				p, q = q, p
+			}
+
+			if o := orient(p, q, v); o == 0 && between(v, p, q) {
+				on = true
+			} else if p[1] <= v[1] && q[1] > v[1] && o > 0 {
+				n++
+			}
+		}
+
+		cross(a, b)
+		t.grid.row(v, extent.MaxX, func(s *simpleSegment) {
+			if own(s) {
+				cross(s.a, s.b)
+			}
+		})
+
+		return on || n%2 == 1
+	}
+
+	return t.grid.each(extent, func(s *simpleSegment) bool {
+		return own(s) || (!inside(s.a) && !inside(s.b))
+	})
+}
+
+// segmentsMeet reports whether the segments ab and cd share any point other than an end point common to both
+func segmentsMeet(a, b, c, d xy) bool {
+	if (a == c && b == d) || (a == d && b == c) {
+		return true
+	}
+
+	o1, o2 := orient(a, b, c), orient(a, b, d)
+	o3, o4 := orient(c, d, a), orient(c, d, b)
+
+	if o1*o2 < 0 && o3*o4 < 0 {
+		return true
+	}
+
+	on := func(p, q, r xy, o int) bool {
+		return o == 0 && p != q && p != r && between(p, q, r)
+	}
+
+	return on(c, a, b, o1) || on(d, a, b, o2) || on(a, c, d, o3) || on(b, c, d, o4)
+}
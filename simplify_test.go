@@ -0,0 +1,70 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimplify(t *testing.T) {
+	line := &LineString{Hdr{XYZM, 0}, []Coordinate{{0, 0, 1, 2}, {1, 0.1, 3, 4}, {2, 0, 5, 6}, {3, 1, 7, 8}, {4, 0, 9, 10}}}
+	simplified := &LineString{Hdr{XYZM, 0}, []Coordinate{{0, 0, 1, 2}, {2, 0, 5, 6}, {3, 1, 7, 8}, {4, 0, 9, 10}}}
+	crossing := &MultiLineString{Hdr{XY, 0}, []LineString{
+		{Hdr{XY, 0}, []Coordinate{{0, 0}, {5, 1}, {10, 0}}},
+		{Hdr{XY, 0}, []Coordinate{{4, 0.5}, {6, 0.5}}},
+	}}
+
+	datasets := []struct {
+		name      string
+		g         Geometry
+		tolerance float64
+		simplify  func(Geometry, float64) (Geometry, error)
+		expected  Geometry
+	}{
+		{"douglas-peucker", line, 0.5, Simplify, simplified},
+		{"visvalingam", line, 0.5, SimplifyVW, simplified},
+		{"preserve topology", line, 0.5, SimplifyPreserveTopology, simplified},
+		{"point", &Point{Hdr{XY, 0}, Coordinate{1, 2}}, 1, Simplify, &Point{Hdr{XY, 0}, Coordinate{1, 2}}},
+		{"hole dropped", square, 2, Simplify, &Polygon{Hdr{XY, 0}, square.Rings[:1]}},
+		{"hole kept", square, 2, SimplifyPreserveTopology, &Polygon{Hdr{XY, 0}, []LinearRing{
+			square.Rings[0],
+			{[]Coordinate{{6, 6}, {8, 8}, {8, 6}, {6, 6}}},
+		}}},
+		{"collapsed", box(0, 0, 1, 1), 10, Simplify, &Polygon{Hdr{XY, 0}, nil}},
+		{"collapsed multi", &MultiPolygon{Hdr{XY, 0}, []Polygon{*box(0, 0, 1, 1), *box(5, 5, 50, 50)}}, 10, SimplifyVW,
+			&MultiPolygon{Hdr{XY, 0}, []Polygon{*box(5, 5, 50, 50)}}},
+		{"crossing", crossing, 2, Simplify, &MultiLineString{Hdr{XY, 0}, []LineString{
+			{Hdr{XY, 0}, []Coordinate{{0, 0}, {10, 0}}},
+			{Hdr{XY, 0}, []Coordinate{{4, 0.5}, {6, 0.5}}},
+		}}},
+		{"crossing preserved", crossing, 2, SimplifyPreserveTopology, crossing},
+	}
+
+	for _, dataset := range datasets {
+		g, err := dataset.simplify(dataset.g, dataset.tolerance)
+		assert.NoError(t, err, dataset.name)
+		assert.Equal(t, dataset.expected, g, dataset.name)
+	}
+
+	_, err := Simplify(nil, 1)
+	assert.Equal(t, ErrNoGeometry, err)
+
+	_, err = SimplifyPreserveTopology(&Triangle{}, 1)
+	assert.Equal(t, ErrUnsupportedOperation, err)
+}
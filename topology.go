@@ -0,0 +1,749 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// The topological operations build a planar graph from the linework of two geometries, noded at every point where
+// they meet. Each edge of the graph records where it lies relative to each geometry and where the faces on either side
+// of it lie, and each node where it lies. Z and M values are ignored and arcs are linearized with the default options.
+
+// xy is a coordinate reduced to the plane, comparable so it can key the nodes of the graph
+type xy [2]float64
+
+// operand is a geometry reduced to its points, lines and polygons
+type operand struct {
+	points   []xy
+	lines    [][]xy
+	polygons [][][]xy
+	extent   Envelope
+}
+
+func newOperand(g Geometry) (*operand, error) {
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o := &operand{extent: Extent(lg)}
+	o.add(lg)
+
+	return o, nil
+}
+
+func (o *operand) add(g Geometry) {
+	switch g := g.(type) {
+	case *Point:
+		o.addLine([]Coordinate{g.Coordinate})
+	case *MultiPoint:
+		for _, pt := range g.Points {
+			o.addLine([]Coordinate{pt.Coordinate})
+		}
+	case *LineString:
+		o.addLine(g.Coordinates)
+	case *MultiLineString:
+		for _, ls := range g.LineStrings {
+			o.addLine(ls.Coordinates)
+		}
+	case *Polygon:
+		o.addPolygon(g.Rings)
+	case *MultiPolygon:
+		for _, p := range g.Polygons {
+			o.addPolygon(p.Rings)
+		}
+	case *Triangle:
+		o.addPolygon(g.Rings)
+	case *PolyhedralSurface:
+		for _, p := range g.Polygons {
+			o.addPolygon(p.Rings)
+		}
+	case *TIN:
+		for _, t := range g.Triangles {
+			o.addPolygon(t.Rings)
+		}
+	case *GeometryCollection:
+		for _, m := range g.Geometries {
+			o.add(m)
+		}
+	}
+}
+
+// addLine adds a line, or a point when all of its coordinates are the same
+func (o *operand) addLine(coords []Coordinate) {
+	line := toXY(coords)
+
+	switch len(line) {
+	case 0:
+	case 1:
+		o.points = append(o.points, line[0])
+	default:
+		o.lines = append(o.lines, line)
+	}
+}
+
+// addPolygon adds a polygon with its rings closed. A shell that has collapsed to a line is added as a line and
+// collapsed holes are dropped.
+func (o *operand) addPolygon(rings []LinearRing) {
+	var polygon [][]xy
+
+	for idx, r := range rings {
+		ring := toXY(r.Coordinates)
+
+		if len(ring) > 1 && ring[0] != ring[len(ring)-1] {
+			ring = append(ring, ring[0])
+		}
+
+		if len(ring) < 4 || ringArea(ring) == 0 {
+			if idx == 0 {
+				o.addLine(r.Coordinates)
+				return
+			}
+
+			continue
+		}
+
+		polygon = append(polygon, ring)
+	}
+
+	if len(polygon) > 0 {
+		o.polygons = append(o.polygons, polygon)
+	}
+}
+
+// dimension returns the largest dimension of the parts of the operand, or -1 when it is empty
+func (o *operand) dimension() int {
+	switch {
+	case len(o.polygons) > 0:
+		return 2
+	case len(o.lines) > 0:
+		return 1
+	case len(o.points) > 0:
+		return 0
+	default:
+		return -1
+	}
+}
+
+// inArea reports whether p, which must not lie on a ring, is inside one of the polygons
+func (o *operand) inArea(p xy) bool {
+	if len(o.polygons) == 0 || p[0] < o.extent.MinX || p[0] > o.extent.MaxX || p[1] < o.extent.MinY ||
+		p[1] > o.extent.MaxY {
+		return false
+	}
+
+	for _, polygon := range o.polygons {
+		if inRing(p, polygon[0]) {
+			inside := true
+
+			for _, hole := range polygon[1:] {
+				if inRing(p, hole) {
+					inside = false
+					break
+				}
+			}
+
+			if inside {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// lineBoundary returns the boundary of the lines by the mod 2 rule: the end points shared by an odd number of lines
+func (o *operand) lineBoundary() map[xy]bool {
+	count := make(map[xy]int)
+
+	for _, l := range o.lines {
+		count[l[0]]++
+		count[l[len(l)-1]]++
+	}
+
+	boundary := make(map[xy]bool)
+
+	for p, n := range count {
+		if n%2 == 1 {
+			boundary[p] = true
+		}
+	}
+
+	return boundary
+}
+
+// snap returns a copy of the operand with its vertices snapped, dropping the repeated points and collapsed rings this
+// leaves
+func (o *operand) snap(sn *snapper) *operand {
+	c := &operand{extent: o.extent}
+
+	snapAll := func(pts []xy) []xy {
+		out := make([]xy, 0, len(pts))
+
+		for _, p := range pts {
+			if p = sn.snap(p); len(out) == 0 || out[len(out)-1] != p {
+				out = append(out, p)
+			}
+		}
+
+		return out
+	}
+
+	for _, p := range o.points {
+		c.points = append(c.points, sn.snap(p))
+	}
+
+	for _, l := range o.lines {
+		if l = snapAll(l); len(l) > 1 {
+			c.lines = append(c.lines, l)
+		} else {
+			c.points = append(c.points, l[0])
+		}
+	}
+
+	for _, polygon := range o.polygons {
+		var rings [][]xy
+
+		for idx, r := range polygon {
+			if r = snapAll(r); len(r) < 4 || ringArea(r) == 0 {
+				// a collapsed shell takes its holes with it
+				if idx == 0 {
+					break
+				}
+
+				continue
+			}
+
+			rings = append(rings, r)
+		}
+
+		if len(rings) > 0 {
+			c.polygons = append(c.polygons, rings)
+		}
+	}
+
+	return c
+}
+
+// toXY drops Z and M, empty coordinates and repeated points
+func toXY(coords []Coordinate) []xy {
+	out := make([]xy, 0, len(coords))
+
+	for _, c := range coords {
+		if len(c) < 2 || math.IsNaN(c[0]) || math.IsNaN(c[1]) {
+			continue
+		}
+
+		p := xy{c[0], c[1]}
+
+		if len(out) == 0 || out[len(out)-1] != p {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// ringArea returns the signed area of a closed ring, positive when it is counter-clockwise
+func ringArea(ring []xy) float64 {
+	var area float64
+
+	for i := 1; i < len(ring); i++ {
+		area += (ring[i-1][0] - ring[0][0]) * (ring[i][1] - ring[0][1])
+		area -= (ring[i][0] - ring[0][0]) * (ring[i-1][1] - ring[0][1])
+	}
+
+	return area / 2
+}
+
+// inRing reports whether p is inside the closed ring by counting the crossings of a ray to the east
+func inRing(p xy, ring []xy) bool {
+	inside := false
+
+	for i := 1; i < len(ring); i++ {
+		a, b := ring[i-1], ring[i]
+
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// orient returns 1 when c lies to the left of the line through a and b, -1 when it lies to the right and 0 when the
+// three points are collinear. Cases too close to call in floating point are decided exactly.
+func orient(a, b, c xy) int {
+	dx1, dy1 := b[0]-a[0], b[1]-a[1]
+	dx2, dy2 := c[0]-a[0], c[1]-a[1]
+	l, r := dx1*dy2, dy1*dx2
+
+	// the error bound of the floating point determinant from Shewchuk's orient2d
+	bound := 3.3306690738754716e-16 * (math.Abs(l) + math.Abs(r))
+
+	switch det := l - r; {
+	case det > bound:
+		return 1
+	case det < -bound:
+		return -1
+	}
+
+	// when the differences are exact, as they are for coordinates on a grid, rounding keeps the products in order and
+	// when they round to the same value their rounding errors decide
+	if exact(b[0], a[0], dx1) && exact(b[1], a[1], dy1) && exact(c[0], a[0], dx2) && exact(c[1], a[1], dy2) {
+		if l == r {
+			l, r = math.FMA(dx1, dy2, -l), math.FMA(dy1, dx2, -r)
+		}
+
+		switch {
+		case l > r:
+			return 1
+		case l < r:
+			return -1
+		default:
+			return 0
+		}
+	}
+
+	rat := func(f float64) *big.Rat {
+		return new(big.Rat).SetFloat64(f)
+	}
+
+	x1 := new(big.Rat).Sub(rat(b[0]), rat(a[0]))
+	y1 := new(big.Rat).Sub(rat(b[1]), rat(a[1]))
+	x2 := new(big.Rat).Sub(rat(c[0]), rat(a[0]))
+	y2 := new(big.Rat).Sub(rat(c[1]), rat(a[1]))
+
+	return new(big.Rat).Sub(x1.Mul(x1, y2), y1.Mul(y1, x2)).Sign()
+}
+
+// exact reports whether the difference d = a - b was computed without rounding
+func exact(a, b, d float64) bool {
+	bv := a - d
+	av := d + bv
+
+	return (a-av)+(bv-b) == 0
+}
+
+// between reports whether p lies in the box spanned by a and b, so on the segment when the three are collinear
+func between(p, a, b xy) bool {
+	return p[0] >= math.Min(a[0], b[0]) && p[0] <= math.Max(a[0], b[0]) &&
+		p[1] >= math.Min(a[1], b[1]) && p[1] <= math.Max(a[1], b[1])
+}
+
+// segment kinds, in the order their parts take precedence when locating a point
+type segmentKind uint8
+
+const (
+	pointSegment segmentKind = iota
+	lineSegment
+	ringSegment
+)
+
+// segment is a piece of the linework of an operand waiting to be noded. Points are segments of zero length.
+type segment struct {
+	p, q xy
+	op   int
+	kind segmentKind
+
+	// left is set for a ring segment with the polygon interior on its left
+	left bool
+
+	minX, minY, maxX, maxY float64
+	nodes                  []xy
+}
+
+func newSegment(p, q xy, op int, kind segmentKind, left bool) *segment {
+	return &segment{p: p, q: q, op: op, kind: kind, left: left,
+		minX: math.Min(p[0], q[0]), minY: math.Min(p[1], q[1]),
+		maxX: math.Max(p[0], q[0]), maxY: math.Max(p[1], q[1])}
+}
+
+// snapper merges nearly coincident points into a single node. The vertices of the operands snap to one another and
+// the points where segments cross snap to the vertices and to each other, so that the crossings of three or more
+// segments through the same point meet at one node.
+type snapper struct {
+	tol   float64
+	cells map[[2]int64][]xy
+}
+
+// newSnapper returns a snapper with a tolerance relative to the magnitude of the coordinates of the operands
+func newSnapper(a, b *operand) *snapper {
+	var scale float64
+
+	for _, o := range []*operand{a, b} {
+		if e := o.extent; !e.IsEmpty() {
+			scale = math.Max(scale, math.Max(math.Max(math.Abs(e.MinX), math.Abs(e.MaxX)),
+				math.Max(math.Abs(e.MinY), math.Abs(e.MaxY))))
+		}
+	}
+
+	sn := &snapper{tol: 1e-11 * scale, cells: make(map[[2]int64][]xy)}
+
+	if sn.tol == 0 {
+		sn.tol = 1
+	}
+
+	return sn
+}
+
+func (sn *snapper) cell(p xy) [2]int64 {
+	return [2]int64{int64(math.Floor(p[0] / sn.tol)), int64(math.Floor(p[1] / sn.tol))}
+}
+
+func (sn *snapper) add(p xy) {
+	c := sn.cell(p)
+	sn.cells[c] = append(sn.cells[c], p)
+}
+
+// snap returns a known point within the tolerance of p, or adds p when there is none
+func (sn *snapper) snap(p xy) xy {
+	c := sn.cell(p)
+
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for _, q := range sn.cells[[2]int64{c[0] + dx, c[1] + dy}] {
+				if math.Abs(q[0]-p[0]) <= sn.tol && math.Abs(q[1]-p[1]) <= sn.tol {
+					return q
+				}
+			}
+		}
+	}
+
+	sn.add(p)
+
+	return p
+}
+
+// nodeSegments finds every point where two segments meet, sweeping across the segments sorted by X
+func nodeSegments(segs []*segment, sn *snapper) {
+	sort.SliceStable(segs, func(i, j int) bool {
+		return segs[i].minX < segs[j].minX
+	})
+
+	for i, s := range segs {
+		for _, t := range segs[i+1:] {
+			if t.minX > s.maxX {
+				break
+			}
+
+			if t.minY <= s.maxY && t.maxY >= s.minY {
+				intersect(s, t, sn)
+			}
+		}
+	}
+}
+
+// intersect records the points where s and t meet as nodes on each
+func intersect(s, t *segment, sn *snapper) {
+	switch {
+	case s.kind == pointSegment && t.kind == pointSegment:
+		return
+	case s.kind == pointSegment:
+		if sn.touches(s.p, t, orient(t.p, t.q, s.p)) {
+			t.nodes = append(t.nodes, s.p)
+		}
+
+		return
+	case t.kind == pointSegment:
+		if sn.touches(t.p, s, orient(s.p, s.q, t.p)) {
+			s.nodes = append(s.nodes, t.p)
+		}
+
+		return
+	}
+
+	o1, o2 := orient(s.p, s.q, t.p), orient(s.p, s.q, t.q)
+	o3, o4 := orient(t.p, t.q, s.p), orient(t.p, t.q, s.q)
+
+	if o1*o2 < 0 && o3*o4 < 0 {
+		x := sn.snap(crossing(s, t))
+		s.nodes = append(s.nodes, x)
+		t.nodes = append(t.nodes, x)
+
+		return
+	}
+
+	// the end points of each that touch the other, which covers collinear overlaps
+	if sn.touches(t.p, s, o1) {
+		s.nodes = append(s.nodes, t.p)
+	}
+
+	if sn.touches(t.q, s, o2) {
+		s.nodes = append(s.nodes, t.q)
+	}
+
+	if sn.touches(s.p, t, o3) {
+		t.nodes = append(t.nodes, s.p)
+	}
+
+	if sn.touches(s.q, t, o4) {
+		t.nodes = append(t.nodes, s.q)
+	}
+}
+
+// touches reports whether p, with orientation o relative to the segment, lies on it. A point within the tolerance of
+// the segment also touches it, so that nearly collinear segments are noded together rather than leaving a sliver
+// too thin to label between them.
+func (sn *snapper) touches(p xy, s *segment, o int) bool {
+	if o == 0 {
+		return between(p, s.p, s.q)
+	}
+
+	return segmentDistance(p, s.p, s.q) <= sn.tol
+}
+
+// segmentDistance returns the distance from p to the nearest point of the segment from a to b
+func segmentDistance(p, a, b xy) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	f := 0.0
+
+	if l := dx*dx + dy*dy; l > 0 {
+		f = math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/l))
+	}
+
+	return math.Hypot(p[0]-a[0]-f*dx, p[1]-a[1]-f*dy)
+}
+
+// crossing returns the point where two properly crossing segments meet, clamped to the boxes of both
+func crossing(s, t *segment) xy {
+	rx, ry := s.q[0]-s.p[0], s.q[1]-s.p[1]
+	sx, sy := t.q[0]-t.p[0], t.q[1]-t.p[1]
+	f := ((t.p[0]-s.p[0])*sy - (t.p[1]-s.p[1])*sx) / (rx*sy - ry*sx)
+
+	x := s.p[0] + f*rx
+	y := s.p[1] + f*ry
+
+	x = math.Max(math.Max(s.minX, t.minX), math.Min(x, math.Min(s.maxX, t.maxX)))
+	y = math.Max(math.Max(s.minY, t.minY), math.Min(y, math.Min(s.maxY, t.maxY)))
+
+	return xy{x, y}
+}
+
+// split returns the points of the segment in order along it, from p to q through its nodes
+func (s *segment) split() []xy {
+	dx, dy := s.q[0]-s.p[0], s.q[1]-s.p[1]
+	along := func(n xy) float64 {
+		return (n[0]-s.p[0])*dx + (n[1]-s.p[1])*dy
+	}
+
+	sort.Slice(s.nodes, func(i, j int) bool {
+		return along(s.nodes[i]) < along(s.nodes[j])
+	})
+
+	pts := []xy{s.p}
+	end := along(s.q)
+
+	for _, n := range s.nodes {
+		if a := along(n); a > 0 && a < end && n != pts[len(pts)-1] {
+			pts = append(pts, n)
+		}
+	}
+
+	return append(pts, s.q)
+}
+
+// edge is a section of the noded linework between two nodes, directed from the lesser to the greater coordinate.
+// on holds the location of its interior relative to each operand, and left and right those of the faces either side.
+// reversed is set when a line of the operand runs from q to p.
+type edge struct {
+	p, q            xy
+	on, left, right [2]Location
+	line, ring      [2]bool
+	inLeft, inRight [2]bool
+	reversed        [2]bool
+}
+
+// graphNode is a node of the graph with its location relative to each operand
+type graphNode struct {
+	loc               [2]Location
+	point, line, ring [2]bool
+	boundary          [2]bool
+}
+
+// topology is the noded and labelled linework of two operands
+type topology struct {
+	ops   [2]*operand
+	edges []*edge
+	nodes map[xy]*graphNode
+	order []xy
+}
+
+func newTopology(a, b *operand) *topology {
+	sn := newSnapper(a, b)
+	t := &topology{ops: [2]*operand{a.snap(sn), b.snap(sn)}, nodes: make(map[xy]*graphNode)}
+
+	var segs []*segment
+
+	for i, o := range t.ops {
+		for _, p := range o.points {
+			segs = append(segs, newSegment(p, p, i, pointSegment, false))
+		}
+
+		for _, l := range o.lines {
+			for k := 1; k < len(l); k++ {
+				segs = append(segs, newSegment(l[k-1], l[k], i, lineSegment, false))
+			}
+		}
+
+		for _, polygon := range o.polygons {
+			for idx, r := range polygon {
+				// shells turning counter-clockwise and holes turning clockwise have the interior on their left
+				left := (idx == 0) == (ringArea(r) > 0)
+
+				for k := 1; k < len(r); k++ {
+					segs = append(segs, newSegment(r[k-1], r[k], i, ringSegment, left))
+				}
+			}
+		}
+	}
+
+	nodeSegments(segs, sn)
+
+	edges := make(map[[2]xy]*edge)
+
+	for _, s := range segs {
+		if s.kind == pointSegment {
+			t.node(s.p).point[s.op] = true
+			continue
+		}
+
+		pts := s.split()
+
+		for k := 1; k < len(pts); k++ {
+			p, q := pts[k-1], pts[k]
+
+			if p == q {
+				continue
+			}
+
+			forward := p[0] < q[0] || (p[0] == q[0] && p[1] < q[1])
+
+			if !forward {
+				p, q = q, p
+			}
+
+			e, ok := edges[[2]xy{p, q}]
+
+			if !ok {
+				e = &edge{p: p, q: q}
+				edges[[2]xy{p, q}] = e
+				t.edges = append(t.edges, e)
+			}
+
+			switch {
+			case s.kind == lineSegment:
+				e.line[s.op] = true
+				e.reversed[s.op] = !forward
+			case s.left == forward:
+				e.ring[s.op] = true
+				e.inLeft[s.op] = true
+			default:
+				e.ring[s.op] = true
+				e.inRight[s.op] = true
+			}
+		}
+	}
+
+	t.label()
+
+	return t
+}
+
+func (t *topology) node(p xy) *graphNode {
+	n, ok := t.nodes[p]
+
+	if !ok {
+		n = &graphNode{}
+		t.nodes[p] = n
+		t.order = append(t.order, p)
+	}
+
+	return n
+}
+
+// label works out the locations of the edges, the faces either side of them and the nodes
+func (t *topology) label() {
+	location := func(in bool) Location {
+		if in {
+			return Interior
+		}
+
+		return Exterior
+	}
+
+	for _, e := range t.edges {
+		mid := xy{(e.p[0] + e.q[0]) / 2, (e.p[1] + e.q[1]) / 2}
+		np, nq := t.node(e.p), t.node(e.q)
+
+		for i, o := range t.ops {
+			if e.ring[i] {
+				// an edge shared by two polygons of a collection or surface has the interior on both sides
+				e.left[i], e.right[i] = location(e.inLeft[i]), location(e.inRight[i])
+
+				if e.inLeft[i] && e.inRight[i] {
+					e.on[i] = Interior
+				} else {
+					e.on[i] = Boundary
+				}
+
+				np.ring[i], nq.ring[i] = true, true
+				np.boundary[i] = np.boundary[i] || e.on[i] == Boundary
+				nq.boundary[i] = nq.boundary[i] || e.on[i] == Boundary
+
+				continue
+			}
+
+			loc := location(o.inArea(mid))
+			e.left[i], e.right[i], e.on[i] = loc, loc, loc
+
+			if e.line[i] {
+				e.on[i] = Interior
+				np.line[i], nq.line[i] = true, true
+			}
+		}
+	}
+
+	for i, o := range t.ops {
+		boundary := o.lineBoundary()
+
+		for _, p := range t.order {
+			n := t.nodes[p]
+
+			switch {
+			case n.ring[i] && n.boundary[i]:
+				n.loc[i] = Boundary
+			case n.ring[i] || o.inArea(p):
+				n.loc[i] = Interior
+			case boundary[p]:
+				n.loc[i] = Boundary
+			case n.line[i] || n.point[i]:
+				n.loc[i] = Interior
+			default:
+				n.loc[i] = Exterior
+			}
+		}
+	}
+}
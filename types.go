@@ -132,6 +132,90 @@ func (l *GeometryCollection) Type() string {
 	return "geometrycollection"
 }
 
+// CircularString is a sequence of circular arcs, each defined by a start, an intermediate and an end point. The end
+// point of one arc is the start point of the next, so a CircularString has an odd number of coordinates.
+type CircularString struct {
+	Hdr
+	Coordinates []Coordinate
+}
+
+func (c *CircularString) Type() string {
+	return "circularstring"
+}
+
+// CompoundCurve is a continuous curve made of LineString and CircularString segments, each starting where the
+// previous one ends
+type CompoundCurve struct {
+	Hdr
+	Curves []Geometry
+}
+
+func (c *CompoundCurve) Type() string {
+	return "compoundcurve"
+}
+
+// CurvePolygon is a polygon whose rings may be a LineString, CircularString or CompoundCurve. The first ring is the
+// shell and any others are holes.
+type CurvePolygon struct {
+	Hdr
+	Rings []Geometry
+}
+
+func (c *CurvePolygon) Type() string {
+	return "curvepolygon"
+}
+
+// MultiCurve is a collection of LineString, CircularString and CompoundCurve geometries
+type MultiCurve struct {
+	Hdr
+	Curves []Geometry
+}
+
+func (m *MultiCurve) Type() string {
+	return "multicurve"
+}
+
+// MultiSurface is a collection of Polygon and CurvePolygon geometries
+type MultiSurface struct {
+	Hdr
+	Surfaces []Geometry
+}
+
+func (m *MultiSurface) Type() string {
+	return "multisurface"
+}
+
+// PolyhedralSurface is a contiguous collection of polygons sharing common boundary segments, such as the faces of a
+// 3D solid
+type PolyhedralSurface struct {
+	Hdr
+	Polygons []Polygon
+}
+
+func (p *PolyhedralSurface) Type() string {
+	return "polyhedralsurface"
+}
+
+// TIN is a triangulated irregular network, a polyhedral surface made only of triangles
+type TIN struct {
+	Hdr
+	Triangles []Triangle
+}
+
+func (t *TIN) Type() string {
+	return "tin"
+}
+
+// Triangle is a polygon with a single ring of three distinct, closed points
+type Triangle struct {
+	Hdr
+	Rings []LinearRing
+}
+
+func (t *Triangle) Type() string {
+	return "triangle"
+}
+
 // LinearRing
 type LinearRing struct {
 	Coordinates []Coordinate
@@ -0,0 +1,625 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Reason is a rule of the OGC Simple Features specification that an invalid geometry breaks
+type Reason int
+
+const (
+	// InvalidCoordinate is a coordinate that is not finite or has fewer than two values
+	InvalidCoordinate Reason = iota + 1
+	// TooFewPoints is a line with fewer than two distinct points or a ring with fewer than three
+	TooFewPoints
+	// InvalidArc is a circular string with an even number of points
+	InvalidArc
+	// RingNotClosed is a ring that ends away from where it starts
+	RingNotClosed
+	// Spike is a ring that turns back along itself
+	Spike
+	// RingSelfIntersection is a ring that touches itself
+	RingSelfIntersection
+	// SelfIntersection is two rings, or two parts of a ring, that cross or overlap
+	SelfIntersection
+	// HoleOutsideShell is a hole that is not within the shell of its polygon
+	HoleOutsideShell
+	// NestedHoles is a hole within another hole of the same polygon
+	NestedHoles
+	// DisconnectedInterior is a polygon whose rings touch so as to split its interior in two
+	DisconnectedInterior
+	// NestedShells is a polygon of a multi polygon within the interior of another
+	NestedShells
+)
+
+func (r Reason) String() string {
+	switch r {
+	case InvalidCoordinate:
+		return "invalid coordinate"
+	case TooFewPoints:
+		return "too few points"
+	case InvalidArc:
+		return "invalid arc"
+	case RingNotClosed:
+		return "ring not closed"
+	case Spike:
+		return "spike"
+	case RingSelfIntersection:
+		return "ring self-intersection"
+	case SelfIntersection:
+		return "self-intersection"
+	case HoleOutsideShell:
+		return "hole outside shell"
+	case NestedHoles:
+		return "nested holes"
+	case DisconnectedInterior:
+		return "disconnected interior"
+	case NestedShells:
+		return "nested shells"
+	default:
+		return "unknown"
+	}
+}
+
+// ValidityError describes why a geometry is invalid and the location of the problem. The location is nil when there
+// is no single point to blame.
+type ValidityError struct {
+	Reason   Reason
+	Location Coordinate
+}
+
+func (e *ValidityError) Error() string {
+	if len(e.Location) < 2 {
+		return e.Reason.String()
+	}
+
+	return fmt.Sprintf("%s at (%v %v)", e.Reason, e.Location[0], e.Location[1])
+}
+
+// IsValid reports whether g follows the validity rules of the OGC Simple Features specification, see
+// ValidationReason. The error is only set when g cannot be checked.
+func IsValid(g Geometry) (bool, error) {
+	err := ValidationReason(g)
+
+	var verr *ValidityError
+
+	if errors.As(err, &verr) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// ValidationReason checks g against the validity rules of the OGC Simple Features specification and returns nil when
+// it is valid, or a *ValidityError with the first rule it breaks. Coordinates must be finite, lines need two distinct
+// points and rings must be closed with three distinct points. Rings must not turn back on themselves, touch
+// themselves or cross one another. The rings of a polygon may touch at single points as long as its interior stays
+// connected, its holes must lie inside the shell and not inside one another, and the polygons of a multi polygon may
+// only touch at points. The polygons of surfaces and collections are checked on their own. Arcs are linearized with
+// the default options and Z and M values are ignored. ErrNoGeometry is returned when g is nil.
+func ValidationReason(g Geometry) error {
+	if g == nil {
+		return ErrNoGeometry
+	}
+
+	lg, err := Linearize(g)
+
+	if err == ErrInvalidArc {
+		return &ValidityError{Reason: InvalidArc}
+	} else if err != nil {
+		return err
+	}
+
+	if verr := validate(lg); verr != nil {
+		return verr
+	}
+
+	return nil
+}
+
+func validate(g Geometry) *ValidityError {
+	switch g := g.(type) {
+	case *Point:
+		return validPoint(g.Coordinate)
+	case *MultiPoint:
+		for _, pt := range g.Points {
+			if err := validPoint(pt.Coordinate); err != nil {
+				return err
+			}
+		}
+	case *LineString:
+		return validLine(g.Coordinates)
+	case *MultiLineString:
+		for _, ls := range g.LineStrings {
+			if err := validLine(ls.Coordinates); err != nil {
+				return err
+			}
+		}
+	case *Polygon:
+		return validPolygons([][]LinearRing{g.Rings})
+	case *MultiPolygon:
+		polygons := make([][]LinearRing, len(g.Polygons))
+
+		for i, p := range g.Polygons {
+			polygons[i] = p.Rings
+		}
+
+		return validPolygons(polygons)
+	case *Triangle:
+		return validPolygons([][]LinearRing{g.Rings})
+	case *PolyhedralSurface:
+		for _, p := range g.Polygons {
+			if err := validPolygons([][]LinearRing{p.Rings}); err != nil {
+				return err
+			}
+		}
+	case *TIN:
+		for _, t := range g.Triangles {
+			if err := validPolygons([][]LinearRing{t.Rings}); err != nil {
+				return err
+			}
+		}
+	case *GeometryCollection:
+		for _, m := range g.Geometries {
+			if err := validate(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validPoint accepts a finite coordinate or the empty point
+func validPoint(c Coordinate) *ValidityError {
+	if len(c) == 0 || (len(c) >= 2 && math.IsNaN(c[0]) && math.IsNaN(c[1])) {
+		return nil
+	}
+
+	_, err := validCoords([]Coordinate{c})
+
+	return err
+}
+
+func validLine(coords []Coordinate) *ValidityError {
+	pts, err := validCoords(coords)
+
+	if err != nil {
+		return err
+	}
+
+	if len(pts) == 1 {
+		return &ValidityError{TooFewPoints, Coordinate{pts[0][0], pts[0][1]}}
+	}
+
+	return nil
+}
+
+// validCoords checks the coordinates are finite and returns them in the plane with repeated points dropped
+func validCoords(coords []Coordinate) ([]xy, *ValidityError) {
+	pts := make([]xy, 0, len(coords))
+
+	for _, c := range coords {
+		if len(c) < 2 || math.IsNaN(c[0]) || math.IsNaN(c[1]) || math.IsInf(c[0], 0) || math.IsInf(c[1], 0) {
+			return nil, &ValidityError{InvalidCoordinate, c}
+		}
+
+		p := xy{c[0], c[1]}
+
+		if len(pts) == 0 || pts[len(pts)-1] != p {
+			pts = append(pts, p)
+		}
+	}
+
+	return pts, nil
+}
+
+// validRing checks a ring on its own: closed, with enough points and no spikes
+func validRing(coords []Coordinate) ([]xy, *ValidityError) {
+	pts, err := validCoords(coords)
+
+	switch {
+	case err != nil:
+		return nil, err
+	case len(pts) == 0:
+		return nil, &ValidityError{Reason: TooFewPoints}
+	case pts[0] != pts[len(pts)-1]:
+		return nil, &ValidityError{RingNotClosed, Coordinate{pts[0][0], pts[0][1]}}
+	case len(pts) < 4:
+		return nil, &ValidityError{TooFewPoints, Coordinate{pts[0][0], pts[0][1]}}
+	}
+
+	n := len(pts) - 1
+
+	for i := 0; i < n; i++ {
+		a, b, c := pts[(i+n-1)%n], pts[i], pts[i+1]
+
+		if orient(a, b, c) == 0 && (a[0]-b[0])*(c[0]-b[0])+(a[1]-b[1])*(c[1]-b[1]) > 0 {
+			return nil, &ValidityError{Spike, Coordinate{b[0], b[1]}}
+		}
+	}
+
+	return pts, nil
+}
+
+// validRingInfo is a ring of a polygon being validated
+type validRingInfo struct {
+	pts    []xy
+	poly   int
+	id     int
+	extent Envelope
+}
+
+// ringSeg is a segment of a ring being validated, the idx'th of the ring
+type ringSeg struct {
+	*segment
+	ring *validRingInfo
+	idx  int
+}
+
+// sides returns the neighbours of p along the ring, where p lies on the segment
+func (s ringSeg) sides(p xy) [2]xy {
+	pts := s.ring.pts
+	n := len(pts) - 1
+
+	switch p {
+	case s.p:
+		return [2]xy{pts[(s.idx+n-1)%n], s.q}
+	case s.q:
+		return [2]xy{s.p, pts[(s.idx+2)%n]}
+	default:
+		return [2]xy{s.p, s.q}
+	}
+}
+
+// touchNode is a point where the rings of a polygon touch
+type touchNode struct {
+	p    xy
+	poly int
+}
+
+// touch is a point where rings meet, with the neighbours of the point along each ring
+type touch struct {
+	p     xy
+	rings map[*validRingInfo][2]xy
+}
+
+// validPolygons checks the polygons, which are the members of a multi polygon or a single polygon
+func validPolygons(polygons [][]LinearRing) *ValidityError {
+	var rings []*validRingInfo
+
+	shells := make([]*validRingInfo, len(polygons))
+	holes := make([][]*validRingInfo, len(polygons))
+
+	for i, polygon := range polygons {
+		for idx, r := range polygon {
+			pts, err := validRing(r.Coordinates)
+
+			if err != nil {
+				return err
+			}
+
+			ring := &validRingInfo{pts: pts, poly: i, id: len(rings), extent: emptyEnvelope()}
+
+			for _, p := range pts {
+				ring.extent = ring.extent.addXY(p[0], p[1])
+			}
+
+			rings = append(rings, ring)
+
+			if idx == 0 {
+				shells[i] = ring
+			} else {
+				holes[i] = append(holes[i], ring)
+			}
+		}
+	}
+
+	touches, err := validIntersections(rings)
+
+	if err != nil {
+		return err
+	}
+
+	if err := validTouches(touches, len(rings)); err != nil {
+		return err
+	}
+
+	for i, shell := range shells {
+		for j, h := range holes[i] {
+			if p := sample(h, shell); ringLocation(p, shell) == Exterior {
+				return &ValidityError{HoleOutsideShell, Coordinate{p[0], p[1]}}
+			}
+
+			for _, other := range holes[i][j+1:] {
+				for _, pair := range [][2]*validRingInfo{{h, other}, {other, h}} {
+					if p := sample(pair[0], pair[1]); ringLocation(p, pair[1]) == Interior {
+						return &ValidityError{NestedHoles, Coordinate{p[0], p[1]}}
+					}
+				}
+			}
+		}
+	}
+
+	for i, shell := range shells {
+		for j, other := range shells {
+			if i == j || shell == nil || other == nil || !shell.extent.Contains(other.extent) {
+				continue
+			}
+
+			// a shell inside another must lie in one of its holes
+			p := sample(other, append([]*validRingInfo{shell}, holes[i]...)...)
+
+			if ringLocation(p, shell) != Interior {
+				continue
+			}
+
+			inHole := false
+
+			for _, h := range holes[i] {
+				if ringLocation(p, h) == Interior {
+					inHole = true
+					break
+				}
+			}
+
+			if !inHole {
+				return &ValidityError{NestedShells, Coordinate{p[0], p[1]}}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validIntersections finds where the rings cross, overlap or touch themselves and returns the points where different
+// rings touch
+func validIntersections(rings []*validRingInfo) ([]*touch, *ValidityError) {
+	var segs []ringSeg
+
+	for _, r := range rings {
+		for k := 1; k < len(r.pts); k++ {
+			segs = append(segs, ringSeg{newSegment(r.pts[k-1], r.pts[k], 0, ringSegment, false), r, k - 1})
+		}
+	}
+
+	sort.SliceStable(segs, func(i, j int) bool {
+		return segs[i].minX < segs[j].minX
+	})
+
+	var touches []*touch
+
+	found := make(map[xy]*touch)
+
+	record := func(p xy, s, t ringSeg) *ValidityError {
+		if s.ring == t.ring {
+			return &ValidityError{RingSelfIntersection, Coordinate{p[0], p[1]}}
+		}
+
+		tp, ok := found[p]
+
+		if !ok {
+			tp = &touch{p: p, rings: make(map[*validRingInfo][2]xy)}
+			found[p] = tp
+			touches = append(touches, tp)
+		}
+
+		tp.rings[s.ring] = s.sides(p)
+		tp.rings[t.ring] = t.sides(p)
+
+		return nil
+	}
+
+	for i, s := range segs {
+		for _, t := range segs[i+1:] {
+			if t.minX > s.maxX {
+				break
+			}
+
+			if t.minY > s.maxY || t.maxY < s.minY {
+				continue
+			}
+
+			if n := len(s.ring.pts) - 1; s.ring == t.ring && ((s.idx-t.idx+n)%n == 1 || (t.idx-s.idx+n)%n == 1) {
+				continue
+			}
+
+			o1, o2 := orient(s.p, s.q, t.p), orient(s.p, s.q, t.q)
+			o3, o4 := orient(t.p, t.q, s.p), orient(t.p, t.q, s.q)
+
+			if o1*o2 < 0 && o3*o4 < 0 {
+				x := crossing(s.segment, t.segment)
+				return nil, &ValidityError{SelfIntersection, Coordinate{x[0], x[1]}}
+			}
+
+			if o1 == 0 && o2 == 0 {
+				if p, ok := overlap(s.segment, t.segment); ok {
+					return nil, &ValidityError{SelfIntersection, Coordinate{p[0], p[1]}}
+				}
+			}
+
+			for _, c := range []struct {
+				p    xy
+				o    int
+				s, t ringSeg
+			}{{t.p, o1, s, t}, {t.q, o2, s, t}, {s.p, o3, t, s}, {s.q, o4, t, s}} {
+				if c.o == 0 && between(c.p, c.s.p, c.s.q) {
+					if err := record(c.p, c.s, c.t); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	return touches, nil
+}
+
+// overlap returns a point that collinear segments share when they share more than a single point
+func overlap(s, t *segment) (xy, bool) {
+	within := func(p xy, s *segment) bool {
+		return between(p, s.p, s.q) && p != s.p && p != s.q
+	}
+
+	switch {
+	case within(t.p, s):
+		return t.p, true
+	case within(t.q, s):
+		return t.q, true
+	case within(s.p, t):
+		return s.p, true
+	case within(s.q, t):
+		return s.q, true
+	case (s.p == t.p && s.q == t.q) || (s.p == t.q && s.q == t.p):
+		return s.p, true
+	default:
+		return xy{}, false
+	}
+}
+
+// validTouches checks that rings meeting at a point do not cross there, and that the rings of each polygon do not
+// cut off part of its interior by touching one another in a cycle
+func validTouches(touches []*touch, n int) *ValidityError {
+	// the rings of a polygon and the points where they touch form a graph, which must not have a cycle. Rings take
+	// the first n ids and each point touched by a polygon the next.
+	var parent []int
+
+	ids := make(map[touchNode]int)
+
+	for i := 0; i < n; i++ {
+		parent = append(parent, i)
+	}
+
+	var find func(i int) int
+
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+
+		return parent[i]
+	}
+
+	for _, tp := range touches {
+		rings := make([]*validRingInfo, 0, len(tp.rings))
+
+		for r := range tp.rings {
+			rings = append(rings, r)
+		}
+
+		sort.Slice(rings, func(i, j int) bool {
+			return rings[i].id < rings[j].id
+		})
+
+		for i, a := range rings {
+			for _, b := range rings[i+1:] {
+				if crosses(tp.p, tp.rings[a], tp.rings[b]) {
+					return &ValidityError{SelfIntersection, Coordinate{tp.p[0], tp.p[1]}}
+				}
+			}
+		}
+
+		for _, r := range rings {
+			id, ok := ids[touchNode{tp.p, r.poly}]
+
+			if !ok {
+				id = len(parent)
+				ids[touchNode{tp.p, r.poly}] = id
+				parent = append(parent, id)
+			}
+
+			if find(r.id) == find(id) {
+				return &ValidityError{DisconnectedInterior, Coordinate{tp.p[0], tp.p[1]}}
+			}
+
+			parent[find(r.id)] = find(id)
+		}
+	}
+
+	return nil
+}
+
+// crosses reports whether the ring passing through p between the neighbours b crosses the one passing between the
+// neighbours a, rather than just touching it
+func crosses(p xy, a, b [2]xy) bool {
+	// between reports whether x comes after a[0] and before a[1] turning counter-clockwise around p
+	between := func(x xy) bool {
+		if angleLess(p, a[0], a[1]) {
+			return angleLess(p, a[0], x) && angleLess(p, x, a[1])
+		}
+
+		return angleLess(p, a[0], x) || angleLess(p, x, a[1])
+	}
+
+	return between(b[0]) != between(b[1])
+}
+
+// sample returns a point of the ring r that is not on any of the others: a vertex, or failing that the middle of a
+// segment. As the rings do not cross, r is inside or outside each of the others by where this point lies.
+func sample(r *validRingInfo, others ...*validRingInfo) xy {
+	off := func(p xy) bool {
+		for _, o := range others {
+			if ringLocation(p, o) == Boundary {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, p := range r.pts {
+		if off(p) {
+			return p
+		}
+	}
+
+	for k := 1; k < len(r.pts); k++ {
+		if p := (xy{(r.pts[k-1][0] + r.pts[k][0]) / 2, (r.pts[k-1][1] + r.pts[k][1]) / 2}); off(p) {
+			return p
+		}
+	}
+
+	return r.pts[0]
+}
+
+// ringLocation returns where p lies relative to the area enclosed by the ring
+func ringLocation(p xy, r *validRingInfo) Location {
+	e := r.extent
+
+	if p[0] < e.MinX || p[0] > e.MaxX || p[1] < e.MinY || p[1] > e.MaxY {
+		return Exterior
+	}
+
+	for k := 1; k < len(r.pts); k++ {
+		a, b := r.pts[k-1], r.pts[k]
+
+		if between(p, a, b) && orient(a, b, p) == 0 {
+			return Boundary
+		}
+	}
+
+	if inRing(p, r.pts) {
+		return Interior
+	}
+
+	return Exterior
+}
@@ -0,0 +1,97 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package geom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func poly(rings ...[]Coordinate) *Polygon {
+	p := &Polygon{Hdr: Hdr{XY, 0}}
+
+	for _, r := range rings {
+		p.Rings = append(p.Rings, LinearRing{r})
+	}
+
+	return p
+}
+
+func TestValidationReason(t *testing.T) {
+	datasets := []struct {
+		name     string
+		g        Geometry
+		reason   Reason
+		location Coordinate
+	}{
+		{"point", &Point{Hdr{XY, 0}, Coordinate{1, 1}}, 0, nil},
+		{"empty point", &Point{Hdr{XY, 0}, Coordinate{}}, 0, nil},
+		{"polygon with hole", square, 0, nil},
+		{"hole touching shell", poly([]Coordinate{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, []Coordinate{{0, 0}, {1, 2}, {2, 1}, {0, 0}}), 0, nil},
+		{"touching polygons", &MultiPolygon{Hdr{XY, 0}, []Polygon{*box(0, 0, 1, 1), *box(1, 1, 2, 2)}}, 0, nil},
+		{"curve", disc, 0, nil},
+		{"infinite", &Point{Hdr{XY, 0}, Coordinate{math.Inf(1), 1}}, InvalidCoordinate, Coordinate{math.Inf(1), 1}},
+		{"one point line", &LineString{Hdr{XY, 0}, []Coordinate{{1, 1}, {1, 1}}}, TooFewPoints, Coordinate{1, 1}},
+		{"short ring", poly([]Coordinate{{0, 0}, {1, 1}, {0, 0}}), TooFewPoints, Coordinate{0, 0}},
+		{"even arc", &CircularString{Hdr{XY, 0}, []Coordinate{{0, 0}, {1, 1}, {2, 0}, {3, 1}}}, InvalidArc, nil},
+		{"open ring", poly([]Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 1}}), RingNotClosed, Coordinate{0, 0}},
+		{"spike", poly([]Coordinate{{0, 0}, {2, 0}, {4, 0}, {2, 0}, {2, 2}, {0, 0}}), Spike, Coordinate{4, 0}},
+		{"bowtie", poly([]Coordinate{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}), SelfIntersection, Coordinate{1, 1}},
+		{"self touching ring", poly([]Coordinate{{0, 0}, {4, 0}, {4, 4}, {2, 0}, {0, 4}, {0, 0}}), RingSelfIntersection, Coordinate{2, 0}},
+		{"crossing hole", poly([]Coordinate{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, []Coordinate{{3, 1}, {5, 1}, {5, 2}, {3, 2}, {3, 1}}), SelfIntersection, Coordinate{4, 1}},
+		{"hole outside shell", poly([]Coordinate{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {0, 0}}, []Coordinate{{0, 2}, {1, 5}, {4, 5}, {0, 2}}), HoleOutsideShell, Coordinate{1, 5}},
+		{"nested holes", poly([]Coordinate{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, []Coordinate{{1, 1}, {1, 9}, {9, 9}, {9, 1}, {1, 1}}, []Coordinate{{2, 2}, {2, 3}, {3, 3}, {3, 2}, {2, 2}}), NestedHoles, Coordinate{2, 2}},
+		{"split interior", poly([]Coordinate{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, []Coordinate{{2, 0}, {3, 2}, {2, 4}, {1, 2}, {2, 0}}), DisconnectedInterior, Coordinate{2, 4}},
+		{"nested shells", &MultiPolygon{Hdr{XY, 0}, []Polygon{*box(0, 0, 4, 4), *box(1, 1, 2, 2)}}, NestedShells, Coordinate{1, 1}},
+		{"overlapping shells", &MultiPolygon{Hdr{XY, 0}, []Polygon{*box(0, 0, 2, 2), *box(1, 1, 3, 3)}}, SelfIntersection, Coordinate{1, 2}},
+	}
+
+	for _, dataset := range datasets {
+		err := ValidationReason(dataset.g)
+
+		if dataset.reason == 0 {
+			assert.NoError(t, err, dataset.name)
+			continue
+		}
+
+		if assert.IsType(t, &ValidityError{}, err, dataset.name) {
+			verr := err.(*ValidityError)
+			assert.Equal(t, dataset.reason, verr.Reason, dataset.name)
+			assert.Equal(t, dataset.location, verr.Location, dataset.name)
+		}
+	}
+
+	assert.Equal(t, ErrNoGeometry, ValidationReason(nil))
+}
+
+func TestIsValid(t *testing.T) {
+	valid, err := IsValid(square)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = IsValid(box(0, 0, 0, 0))
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = IsValid(nil)
+	assert.Equal(t, ErrNoGeometry, err)
+
+	assert.Equal(t, "self-intersection at (1 1)", (&ValidityError{SelfIntersection, Coordinate{1, 1}}).Error())
+	assert.Equal(t, "nested shells", (&ValidityError{Reason: NestedShells}).Error())
+}
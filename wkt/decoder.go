@@ -0,0 +1,628 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wkt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/devork/geom"
+)
+
+// MaxDepth is the deepest nesting of geometry collections accepted by Decode, matching ewkb.DefaultMaxDepth. A
+// simple geometry has a depth of 1 and the members of a collection a depth of 2.
+const MaxDepth = 64
+
+// decoder holds the WKT text and the current read position
+type decoder struct {
+	data  []byte
+	pos   int
+	srid  uint32
+	depth int
+}
+
+// dims tracks the dimension of a geometry as it is decoded. When the text carries no Z/M suffix the dimension
+// is inferred from the number of ordinates in the first coordinate and enforced for the remainder.
+type dims struct {
+	dim   geom.Dimension
+	fixed bool
+}
+
+func (s *dims) check(d *decoder, n int) error {
+	if !s.fixed {
+		switch n {
+		case 2:
+			s.dim = geom.XY
+		case 3:
+			s.dim = geom.XYZ
+		case 4:
+			s.dim = geom.XYZM
+		default:
+			return d.errorf("unsupported coordinate with %d ordinates", n)
+		}
+		s.fixed = true
+	}
+
+	if n != size(s.dim) {
+		return d.errorf("expected %d ordinates for %s coordinate, but got %d", size(s.dim), s.dim, n)
+	}
+
+	return nil
+}
+
+func (d *decoder) hdr(s *dims) geom.Hdr {
+	return geom.Hdr{Dim: s.dim, Srid: d.srid}
+}
+
+func (d *decoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("wkt: "+format+" at offset %d", append(args, d.pos)...)
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non whitespace character without consuming it, or 0 at the end of input
+func (d *decoder) peek() byte {
+	d.skipSpace()
+
+	if d.pos >= len(d.data) {
+		return 0
+	}
+
+	return d.data[d.pos]
+}
+
+// consume the next character if it matches c
+func (d *decoder) consume(c byte) bool {
+	if d.peek() == c {
+		d.pos++
+		return true
+	}
+
+	return false
+}
+
+func (d *decoder) expect(c byte) error {
+	if !d.consume(c) {
+		if d.pos >= len(d.data) {
+			return d.errorf("expected '%c' but reached end of input", c)
+		}
+		return d.errorf("expected '%c' but got '%c'", c, d.data[d.pos])
+	}
+
+	return nil
+}
+
+// word reads the next keyword, returning it in upper case
+func (d *decoder) word() string {
+	d.skipSpace()
+
+	start := d.pos
+	for d.pos < len(d.data) && isLetter(d.data[d.pos]) {
+		d.pos++
+	}
+
+	buf := make([]byte, d.pos-start)
+	for idx, c := range d.data[start:d.pos] {
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		buf[idx] = c
+	}
+
+	return string(buf)
+}
+
+// peekWord returns the next keyword without consuming it
+func (d *decoder) peekWord() string {
+	pos := d.pos
+	w := d.word()
+	d.pos = pos
+
+	return w
+}
+
+func (d *decoder) number() (float64, error) {
+	d.skipSpace()
+
+	start := d.pos
+	for d.pos < len(d.data) && isNumeric(d.data[d.pos]) {
+		d.pos++
+	}
+
+	if start == d.pos {
+		return 0, d.errorf("expected number")
+	}
+
+	v, err := strconv.ParseFloat(string(d.data[start:d.pos]), 64)
+
+	if err != nil {
+		d.pos = start
+		return 0, d.errorf("invalid number %q", string(d.data[start:d.pos]))
+	}
+
+	return v, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// Decode converts the WKT read from the given reader to a geom type. Postgis EWKT is also accepted, in which case
+// the SRID=n; prefix is applied to every geometry and POINTM style keywords are read as geom.XYM.
+func Decode(r io.Reader) (geom.Geometry, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{data: data}
+
+	err = unmarshalSrid(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := unmarshal(d, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if d.peek() != 0 {
+		return nil, d.errorf("unexpected trailing data")
+	}
+
+	return g, nil
+}
+
+// unmarshalSrid reads the optional EWKT SRID=n; prefix
+func unmarshalSrid(d *decoder) error {
+	if d.peekWord() != sridKeyword {
+		return nil
+	}
+
+	d.word()
+
+	err := d.expect('=')
+
+	if err != nil {
+		return err
+	}
+
+	d.skipSpace()
+
+	start := d.pos
+	for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+		d.pos++
+	}
+
+	srid, err := strconv.ParseUint(string(d.data[start:d.pos]), 10, 32)
+
+	if err != nil {
+		d.pos = start
+		return d.errorf("invalid SRID")
+	}
+
+	d.srid = uint32(srid)
+
+	return d.expect(';')
+}
+
+// unmarshalHdr reads the optional dimension suffix that follows a geometry keyword
+func unmarshalHdr(d *decoder) *dims {
+	switch d.peekWord() {
+	case z:
+		d.word()
+		return &dims{geom.XYZ, true}
+	case m:
+		d.word()
+		return &dims{geom.XYM, true}
+	case zm:
+		d.word()
+		return &dims{geom.XYZM, true}
+	default:
+		return &dims{geom.XY, false}
+	}
+}
+
+// isEmpty consumes the EMPTY keyword if it is next in the input
+func isEmpty(d *decoder) bool {
+	if d.peekWord() == empty {
+		d.word()
+		return true
+	}
+
+	return false
+}
+
+func unmarshalPoint(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.Point{d.hdr(s), nil}, nil
+	}
+
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	coord, err := unmarshalCoord(d, s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.expect(')')
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &geom.Point{d.hdr(s), coord}, nil
+}
+
+func unmarshalMultiPoint(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.MultiPoint{d.hdr(s), nil}, nil
+	}
+
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	var points []geom.Point
+	for {
+		var coord geom.Coordinate
+
+		switch {
+		case isEmpty(d):
+		case d.consume('('):
+			coord, err = unmarshalCoord(d, s)
+
+			if err != nil {
+				return nil, err
+			}
+
+			err = d.expect(')')
+		default:
+			// MULTIPOINT (10 40, 40 30) is accepted as well as MULTIPOINT ((10 40), (40 30))
+			coord, err = unmarshalCoord(d, s)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, geom.Point{Coordinate: coord})
+
+		if !d.consume(',') {
+			break
+		}
+	}
+
+	err = d.expect(')')
+
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range points {
+		points[idx].Hdr = d.hdr(s)
+	}
+
+	return &geom.MultiPoint{d.hdr(s), points}, nil
+}
+
+func unmarshalLineString(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.LineString{d.hdr(s), nil}, nil
+	}
+
+	coords, err := unmarshalCoords(d, s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &geom.LineString{d.hdr(s), coords}, nil
+}
+
+func unmarshalMultiLineString(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.MultiLineString{d.hdr(s), nil}, nil
+	}
+
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lstrings []geom.LineString
+	for {
+		var coords []geom.Coordinate
+
+		if !isEmpty(d) {
+			coords, err = unmarshalCoords(d, s)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		lstrings = append(lstrings, geom.LineString{Coordinates: coords})
+
+		if !d.consume(',') {
+			break
+		}
+	}
+
+	err = d.expect(')')
+
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range lstrings {
+		lstrings[idx].Hdr = d.hdr(s)
+	}
+
+	return &geom.MultiLineString{d.hdr(s), lstrings}, nil
+}
+
+func unmarshalPolygon(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.Polygon{d.hdr(s), nil}, nil
+	}
+
+	rings, err := unmarshalRings(d, s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &geom.Polygon{d.hdr(s), rings}, nil
+}
+
+func unmarshalMultiPolygon(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.MultiPolygon{d.hdr(s), nil}, nil
+	}
+
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	var polys []geom.Polygon
+	for {
+		var rings []geom.LinearRing
+
+		if !isEmpty(d) {
+			rings, err = unmarshalRings(d, s)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		polys = append(polys, geom.Polygon{Rings: rings})
+
+		if !d.consume(',') {
+			break
+		}
+	}
+
+	err = d.expect(')')
+
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range polys {
+		polys[idx].Hdr = d.hdr(s)
+	}
+
+	return &geom.MultiPolygon{d.hdr(s), polys}, nil
+}
+
+func unmarshalGeometryCollection(d *decoder, s *dims) (geom.Geometry, error) {
+	if isEmpty(d) {
+		return &geom.GeometryCollection{d.hdr(s), nil}, nil
+	}
+
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	var geoms []geom.Geometry
+	for {
+		g, err := unmarshal(d, s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		geoms = append(geoms, g)
+
+		if !d.consume(',') {
+			break
+		}
+	}
+
+	err = d.expect(')')
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &geom.GeometryCollection{d.hdr(s), geoms}, nil
+}
+
+func unmarshalRings(d *decoder, s *dims) ([]geom.LinearRing, error) {
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rings []geom.LinearRing
+	for {
+		coords, err := unmarshalCoords(d, s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rings = append(rings, geom.LinearRing{coords})
+
+		if !d.consume(',') {
+			break
+		}
+	}
+
+	return rings, d.expect(')')
+}
+
+func unmarshalCoords(d *decoder, s *dims) ([]geom.Coordinate, error) {
+	err := d.expect('(')
+
+	if err != nil {
+		return nil, err
+	}
+
+	var coords []geom.Coordinate
+	for {
+		coord, err := unmarshalCoord(d, s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		coords = append(coords, coord)
+
+		if !d.consume(',') {
+			break
+		}
+	}
+
+	return coords, d.expect(')')
+}
+
+func unmarshalCoord(d *decoder, s *dims) (geom.Coordinate, error) {
+	var coord geom.Coordinate
+
+	for {
+		v, err := d.number()
+
+		if err != nil {
+			return nil, err
+		}
+
+		coord = append(coord, v)
+
+		switch d.peek() {
+		case ',', ')':
+			return coord, s.check(d, len(coord))
+		}
+	}
+}
+
+// Resolves a geometry keyword to its unmarshaller instance. The members of a collection are decoded with the
+// dimension of the collection as parent: a member without a suffix shares it, so its coordinates are checked against
+// (or fix) the dimension of the collection, and a member with a suffix must agree with it.
+func unmarshal(d *decoder, parent *dims) (geom.Geometry, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+
+	if d.depth > MaxDepth {
+		return nil, ErrTooDeep
+	}
+
+	keyword := d.word()
+
+	s := unmarshalHdr(d)
+
+	// Postgis EWKT marks measured geometries by appending M to the keyword, e.g. POINTM (1 2 3)
+	if base := strings.TrimSuffix(keyword, m); base != keyword && !s.fixed && isKeyword(base) {
+		keyword = base
+		s = &dims{geom.XYM, true}
+	}
+
+	if parent != nil {
+		switch {
+		case !s.fixed:
+			s = parent
+		case parent.fixed && parent.dim != s.dim:
+			return nil, d.errorf("%s member in %s geometry collection", s.dim, parent.dim)
+		default:
+			*parent = *s
+		}
+	}
+
+	switch keyword {
+	case point:
+		return unmarshalPoint(d, s)
+	case linestring:
+		return unmarshalLineString(d, s)
+	case polygon:
+		return unmarshalPolygon(d, s)
+	case multipoint:
+		return unmarshalMultiPoint(d, s)
+	case multilinestring:
+		return unmarshalMultiLineString(d, s)
+	case multipolygon:
+		return unmarshalMultiPolygon(d, s)
+	case geometrycollection:
+		return unmarshalGeometryCollection(d, s)
+	case "":
+		return nil, d.errorf("expected geometry keyword")
+	default:
+		return nil, geom.ErrUnsupportedGeom
+	}
+}
+
+func isKeyword(keyword string) bool {
+	switch keyword {
+	case point, linestring, polygon, multipoint, multilinestring, multipolygon, geometrycollection:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,355 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wkt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/devork/geom/ewkb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodePoint(t *testing.T) {
+	datasets := []struct {
+		data     string
+		dim      geom.Dimension
+		expected geom.Coordinate
+	}{
+		{"POINT (30 10)", geom.XY, geom.Coordinate{30, 10}},
+		{"point(30 10)", geom.XY, geom.Coordinate{30, 10}},
+		{"POINT Z (1 2 3)", geom.XYZ, geom.Coordinate{1, 2, 3}},
+		{"POINT (1 2 3)", geom.XYZ, geom.Coordinate{1, 2, 3}},
+		{"POINT M (1 2 3)", geom.XYM, geom.Coordinate{1, 2, 3}},
+		{"POINT ZM (1 2 3 4)", geom.XYZM, geom.Coordinate{1, 2, 3, 4}},
+		{"POINT (-1.5e3 +2.25)", geom.XY, geom.Coordinate{-1500, 2.25}},
+		{"POINT EMPTY", geom.XY, nil},
+		{"POINT Z EMPTY", geom.XYZ, nil},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset.data))
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset.data, err)
+		}
+
+		assert.Equal(t, "point", g.Type())
+
+		p := g.(*geom.Point)
+		assert.Equal(t, dataset.dim, p.Dimension())
+		assert.Equal(t, dataset.expected, p.Coordinate)
+	}
+}
+
+func TestDecodeLineString(t *testing.T) {
+	g, err := Decode(strings.NewReader("LINESTRING (30 10, 10 30, 40 40)"))
+
+	if err != nil {
+		t.Fatalf("Failed to decode LineString: err = %s", err)
+	}
+
+	assert.Equal(t, &geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{30, 10}, {10, 30}, {40, 40}}}, g)
+}
+
+func TestDecodePolygon(t *testing.T) {
+	g, err := Decode(strings.NewReader("POLYGON ((35 10, 45 45, 15 40, 10 20, 35 10),(20 30, 35 35, 30 20, 20 30))"))
+
+	if err != nil {
+		t.Fatalf("Failed to decode Polygon: err = %s", err)
+	}
+
+	polygon := g.(*geom.Polygon)
+
+	assert.Equal(t, geom.XY, polygon.Dimension())
+	assert.Equal(t, 2, len(polygon.Rings))
+	assert.Equal(t, 5, len(polygon.Rings[0].Coordinates))
+	assert.Equal(t, geom.Coordinate{20, 30}, polygon.Rings[1].Coordinates[0])
+}
+
+func TestDecodeMultiPoint(t *testing.T) {
+	datasets := []string{
+		"MULTIPOINT ((10 40), (40 30), (20 20), (30 10))",
+		"MULTIPOINT (10 40, 40 30, 20 20, 30 10)",
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset))
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset, err)
+		}
+
+		mpoint := g.(*geom.MultiPoint)
+
+		assert.Equal(t, 4, len(mpoint.Points))
+		assert.Equal(t, geom.Coordinate{10, 40}, mpoint.Points[0].Coordinate)
+		assert.Equal(t, geom.Coordinate{30, 10}, mpoint.Points[3].Coordinate)
+		assert.Equal(t, geom.XY, mpoint.Points[3].Dimension())
+	}
+}
+
+func TestDecodeMultiLineString(t *testing.T) {
+	g, err := Decode(strings.NewReader("MULTILINESTRING ZM ((10 10 1 2, 20 20 1 2), (40 40 1 2, 30 30 1 2, 40 20 1 2))"))
+
+	if err != nil {
+		t.Fatalf("Failed to decode MultiLineString: err = %s", err)
+	}
+
+	mlstring := g.(*geom.MultiLineString)
+
+	assert.Equal(t, geom.XYZM, mlstring.Dimension())
+	assert.Equal(t, 2, len(mlstring.LineStrings))
+	assert.Equal(t, geom.XYZM, mlstring.LineStrings[1].Dimension())
+	assert.Equal(t, 3, len(mlstring.LineStrings[1].Coordinates))
+}
+
+func TestDecodeMultiPolygon(t *testing.T) {
+	g, err := Decode(strings.NewReader("MULTIPOLYGON (((40 40, 20 45, 45 30, 40 40)),((20 35, 10 30, 10 10, 30 5, 45 20, 20 35),(30 20, 20 15, 20 25, 30 20)))"))
+
+	if err != nil {
+		t.Fatalf("Failed to decode MultiPolygon: err = %s", err)
+	}
+
+	mpolygon := g.(*geom.MultiPolygon)
+
+	assert.Equal(t, 2, len(mpolygon.Polygons))
+	assert.Equal(t, 1, len(mpolygon.Polygons[0].Rings))
+	assert.Equal(t, 2, len(mpolygon.Polygons[1].Rings))
+	assert.Equal(t, 6, len(mpolygon.Polygons[1].Rings[0].Coordinates))
+}
+
+func TestDecodeGeometryCollection(t *testing.T) {
+	g, err := Decode(strings.NewReader("GEOMETRYCOLLECTION(POINT(4 6),LINESTRING(4 6,7 10), POLYGON EMPTY)"))
+
+	if err != nil {
+		t.Fatalf("Failed to decode GeometryCollection: err = %s", err)
+	}
+
+	gcol := g.(*geom.GeometryCollection)
+
+	assert.Equal(t, 3, len(gcol.Geometries))
+	assert.Equal(t, &geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{4, 6}}, gcol.Geometries[0])
+	assert.Equal(t, &geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{4, 6}, {7, 10}}}, gcol.Geometries[1])
+	assert.Equal(t, "polygon", gcol.Geometries[2].Type())
+}
+
+func TestDecodeGeometryCollectionDims(t *testing.T) {
+	datasets := []struct {
+		wkt string
+		dim geom.Dimension
+		err bool
+	}{
+		{"GEOMETRYCOLLECTION (POINT (1 2), POINT (3 4))", geom.XY, false},
+		{"GEOMETRYCOLLECTION (POINT Z (1 2 3), POINT (1 2 3))", geom.XYZ, false},
+		{"GEOMETRYCOLLECTION Z (POINT (1 2 3), POINT Z (4 5 6))", geom.XYZ, false},
+		{"GEOMETRYCOLLECTION (POINT EMPTY, POINTM (1 2 3))", geom.XYM, false},
+		{"GEOMETRYCOLLECTION (POINT Z (1 2 3), POINT (1 2))", 0, true},
+		{"GEOMETRYCOLLECTION (POINT (1 2), POINT Z (1 2 3))", 0, true},
+		{"GEOMETRYCOLLECTION (POINT (1 2), POINT (1 2 3))", 0, true},
+		{"GEOMETRYCOLLECTION Z (POINT M (1 2 3))", 0, true},
+		{"GEOMETRYCOLLECTION (POINT (1 2), GEOMETRYCOLLECTION (POINT (3 4 5)))", 0, true},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset.wkt))
+
+		if dataset.err {
+			assert.Error(t, err, "Expected error decoding %q", dataset.wkt)
+			continue
+		}
+
+		if !assert.NoError(t, err, dataset.wkt) {
+			continue
+		}
+
+		gcol := g.(*geom.GeometryCollection)
+		assert.Equal(t, dataset.dim, gcol.Dimension(), dataset.wkt)
+
+		assert.Equal(t, dataset.dim, gcol.Geometries[len(gcol.Geometries)-1].Dimension(), dataset.wkt)
+	}
+}
+
+func TestDecodeMaxDepth(t *testing.T) {
+	nest := func(n int) string {
+		return strings.Repeat("GEOMETRYCOLLECTION (", n-1) + "POINT (1 2)" + strings.Repeat(")", n-1)
+	}
+
+	_, err := Decode(strings.NewReader(nest(MaxDepth)))
+	assert.NoError(t, err)
+
+	_, err = Decode(strings.NewReader(nest(MaxDepth + 1)))
+	assert.Equal(t, ErrTooDeep, err)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	datasets := []string{
+		"",
+		"POINT",
+		"POINT (1)",
+		"POINT (1 2",
+		"POINT Z (1 2)",
+		"LINESTRING (1 2, 3 4 5)",
+		"POLYGON ((1 2, 3 4, 5 6, 1 2)) extra",
+		"POINT (1 x)",
+	}
+
+	for _, dataset := range datasets {
+		_, err := Decode(strings.NewReader(dataset))
+
+		assert.Error(t, err, "Expected error decoding %q", dataset)
+	}
+
+	_, err := Decode(strings.NewReader("CURVE (1 2)"))
+	assert.Equal(t, geom.ErrUnsupportedGeom, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	datasets := []string{
+		"POINT ZM (1 2 3 4)",
+		"MULTIPOINT M ((10 40 1), (40 30 2))",
+		"POLYGON Z ((35 10 1, 45 45 2, 15 40 3, 35 10 1), (20 30 1, 35 35 2, 30 20 3, 20 30 1))",
+		"GEOMETRYCOLLECTION (POINT (4 6), MULTILINESTRING ((10 10, 20 20), EMPTY))",
+		"POINT EMPTY",
+		"MULTIPOINT ((1 2), EMPTY)",
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset))
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset, err)
+		}
+
+		var w = new(bytes.Buffer)
+		err = Encode(g, w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s: err = %s", dataset, err)
+		}
+
+		assert.Equal(t, dataset, w.String())
+	}
+}
+
+func TestRoundTripNotFinite(t *testing.T) {
+	datasets := []geom.Geometry{
+		&geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{0, 0}, {1, math.NaN()}}},
+		&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{math.Inf(1), 2}},
+		&geom.Point{geom.Hdr{geom.XYZ, 0}, geom.Coordinate{1, 2, math.NaN()}},
+		&geom.MultiPoint{geom.Hdr{geom.XY, 0}, []geom.Point{{geom.Hdr{geom.XY, 0}, geom.Coordinate{math.Inf(-1), 0}}}},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+
+		assert.Equal(t, ErrNotFinite, Encode(dataset, w))
+		assert.Equal(t, ErrNotFinite, EncodeEWKT(dataset, w))
+		assert.Equal(t, 0, w.Len())
+	}
+}
+
+func TestDecodeEWKT(t *testing.T) {
+	datasets := []struct {
+		data string
+		srid uint32
+		dim  geom.Dimension
+	}{
+		{"SRID=4326;POINT(1 2)", 4326, geom.XY},
+		{"srid=4326; POINT Z (1 2 3)", 4326, geom.XYZ},
+		{"SRID=27700;POINTM(1 2 3)", 27700, geom.XYM},
+		{"POINTM EMPTY", 0, geom.XYM},
+		{"SRID=27700;POINT(1 2 3 4)", 27700, geom.XYZM},
+		{"SRID=27700;MULTIPOINTM(1 2 3,4 5 6)", 27700, geom.XYM},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset.data))
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset.data, err)
+		}
+
+		assert.Equal(t, dataset.srid, g.SRID(), "Expected srid %v, but got %v ", dataset.srid, g.SRID())
+		assert.Equal(t, dataset.dim, g.Dimension(), "Expected dim %v, but got %v ", dataset.dim, g.Dimension())
+	}
+
+	datasets2 := []string{
+		"SRID=;POINT(1 2)",
+		"SRID=4326 POINT(1 2)",
+		"SRID=-1;POINT(1 2)",
+		"POINTM(1 2)",
+	}
+
+	for _, dataset := range datasets2 {
+		_, err := Decode(strings.NewReader(dataset))
+
+		assert.Error(t, err, "Expected error decoding %q", dataset)
+	}
+}
+
+// EWKT and EWKB test cases were generated from Postgis, see ewkb/decoder_test.go
+func TestEWKBRoundTrip(t *testing.T) {
+	datasets := []struct {
+		ewkt string
+		ewkb string
+	}{
+		{"SRID=27700;LINESTRING(30 10,10 30,40 40)", "002000000200006c3400000003403e00000000000040240000000000004024000000000000403e00000000000040440000000000004044000000000000"},
+		{"SRID=27700;POLYGON((30 10,40 40,20 40,10 20,30 10))", "002000000300006c340000000100000005403e0000000000004024000000000000404400000000000040440000000000004034000000000000404400000000000040240000000000004034000000000000403e0000000000004024000000000000"},
+		{"SRID=27700;POLYGON((35 10,45 45,15 40,10 20,35 10),(20 30,35 35,30 20,20 30))", "002000000300006c3400000002000000054041800000000000402400000000000040468000000000004046800000000000402e00000000000040440000000000004024000000000000403400000000000040418000000000004024000000000000000000044034000000000000403e00000000000040418000000000004041800000000000403e00000000000040340000000000004034000000000000403e000000000000"},
+		{"SRID=27700;MULTIPOINT(10 40,40 30,20 20,30 10)", "002000000400006c340000000400000000014024000000000000404400000000000000000000014044000000000000403e0000000000000000000001403400000000000040340000000000000000000001403e0000000000004024000000000000"},
+		{"SRID=27700;MULTILINESTRING((10 10,20 20,10 40),(40 40,30 30,40 20,30 10))", "002000000500006c340000000200000000020000000340240000000000004024000000000000403400000000000040340000000000004024000000000000404400000000000000000000020000000440440000000000004044000000000000403e000000000000403e00000000000040440000000000004034000000000000403e0000000000004024000000000000"},
+		{"SRID=27700;MULTIPOLYGON(((40 40,20 45,45 30,40 40)),((20 35,10 30,10 10,30 5,45 20,20 35),(30 20,20 15,20 25,30 20)))", "002000000600006c34000000020000000003000000010000000440440000000000004044000000000000403400000000000040468000000000004046800000000000403e0000000000004044000000000000404400000000000000000000030000000200000006403400000000000040418000000000004024000000000000403e00000000000040240000000000004024000000000000403e0000000000004014000000000000404680000000000040340000000000004034000000000000404180000000000000000004403e00000000000040340000000000004034000000000000402e00000000000040340000000000004039000000000000403e0000000000004034000000000000"},
+		{"SRID=27700;GEOMETRYCOLLECTION(POINT(4 6),LINESTRING(4 6,7 10))", "002000000700006c340000000200000000014010000000000000401800000000000000000000020000000240100000000000004018000000000000401c0000000000004024000000000000"},
+	}
+
+	for _, dataset := range datasets {
+		g, err := Decode(strings.NewReader(dataset.ewkt))
+
+		if err != nil {
+			t.Fatalf("Failed to decode %s: err = %s", dataset.ewkt, err)
+		}
+
+		var w = new(bytes.Buffer)
+		err = ewkb.Encode(g, w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s as EWKB: err = %s", dataset.ewkt, err)
+		}
+
+		assert.Equal(t, dataset.ewkb, hex.EncodeToString(w.Bytes()))
+
+		g, err = ewkb.Decode(w)
+
+		if err != nil {
+			t.Fatalf("Failed to decode EWKB for %s: err = %s", dataset.ewkt, err)
+		}
+
+		var sb = new(bytes.Buffer)
+		err = EncodeEWKT(g, sb)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s as EWKT: err = %s", dataset.ewkt, err)
+		}
+
+		assert.Equal(t, dataset.ewkt, sb.String())
+	}
+}
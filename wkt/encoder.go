@@ -0,0 +1,401 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wkt
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/devork/geom"
+)
+
+// snippets of wkt
+var (
+	space      = []byte(` `)
+	comma      = []byte(`, `)
+	lparen     = []byte(`(`)
+	rparen     = []byte(`)`)
+	semi       = []byte(`;`)
+	sridPrefix = []byte(`SRID=`)
+)
+
+// encoder wraps the output buffer and the formatting rules of the WKT dialect being written
+type encoder struct {
+	sb   bytes.Buffer
+	ewkt bool
+	err  error
+}
+
+// sep writes the separator between coordinates and members of a geometry
+func (e *encoder) sep() {
+	if e.ewkt {
+		e.sb.WriteByte(',')
+		return
+	}
+
+	e.sb.Write(comma)
+}
+
+// gap writes the space between the keyword and the geometry text
+func (e *encoder) gap() {
+	if !e.ewkt {
+		e.sb.Write(space)
+	}
+}
+
+// open writes the opening paren of the geometry text that follows the keyword
+func (e *encoder) open() {
+	e.gap()
+	e.sb.Write(lparen)
+}
+
+// Encode will take the given geometry and write its WKT representation to the specified writer. Returns ErrNotFinite
+// when an ordinate is NaN or infinite, other than the NaN coordinate of an empty point.
+func Encode(g geom.Geometry, w io.Writer) error {
+	return encode(g, w, &encoder{})
+}
+
+// EncodeEWKT will take the given geometry and write its Postgis EWKT representation to the specified writer. The
+// SRID is written as a SRID=n; prefix when set and measured geometries use the POINTM style keywords.
+func EncodeEWKT(g geom.Geometry, w io.Writer) error {
+	return encode(g, w, &encoder{ewkt: true})
+}
+
+func encode(g geom.Geometry, w io.Writer, e *encoder) error {
+	if g == nil {
+		return geom.ErrNoGeometry
+	}
+
+	if e.ewkt && g.SRID() != 0 {
+		e.sb.Write(sridPrefix)
+		e.sb.WriteString(strconv.FormatUint(uint64(g.SRID()), 10))
+		e.sb.Write(semi)
+	}
+
+	err := marshal(g, e)
+
+	if err != nil {
+		return err
+	} else if e.err != nil {
+		return e.err
+	}
+
+	_, err = w.Write(e.sb.Bytes())
+
+	return err
+}
+
+// marshalHdr writes the geometry keyword and dimension suffix, e.g. POINT ZM, or POINTM for EWKT
+func marshalHdr(keyword string, d geom.Dimension, e *encoder) error {
+	sfx, err := suffix(d)
+
+	if err != nil {
+		return err
+	}
+
+	e.sb.WriteString(keyword)
+
+	if e.ewkt {
+		// EWKT implies Z from the coordinates, so only M is marked
+		if d == geom.XYM {
+			e.sb.WriteString(m)
+		}
+		return nil
+	}
+
+	if sfx != "" {
+		e.sb.Write(space)
+		e.sb.WriteString(sfx)
+	}
+
+	return nil
+}
+
+func marshalEmpty(e *encoder) {
+	e.sb.Write(space)
+	e.sb.WriteString(empty)
+}
+
+func marshalPoint(p *geom.Point, e *encoder) error {
+	err := marshalHdr(point, p.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if isEmptyCoord(p.Coordinate) {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.open()
+	marshalCoord(p.Coordinate, e)
+	e.sb.Write(rparen)
+
+	return nil
+}
+
+func marshalMultiPoint(mp *geom.MultiPoint, e *encoder) error {
+	err := marshalHdr(multipoint, mp.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if len(mp.Points) == 0 {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.open()
+
+	limit := len(mp.Points) - 1
+	for idx, point := range mp.Points {
+		switch {
+		case isEmptyCoord(point.Coordinate):
+			e.sb.WriteString(empty)
+		case e.ewkt:
+			marshalCoord(point.Coordinate, e)
+		default:
+			e.sb.Write(lparen)
+			marshalCoord(point.Coordinate, e)
+			e.sb.Write(rparen)
+		}
+
+		if idx < limit {
+			e.sep()
+		}
+	}
+
+	e.sb.Write(rparen)
+
+	return nil
+}
+
+func marshalLineString(ls *geom.LineString, e *encoder) error {
+	err := marshalHdr(linestring, ls.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if len(ls.Coordinates) == 0 {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.gap()
+	marshalCoords(ls.Coordinates, e)
+
+	return nil
+}
+
+func marshalMultiLineString(ml *geom.MultiLineString, e *encoder) error {
+	err := marshalHdr(multilinestring, ml.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if len(ml.LineStrings) == 0 {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.open()
+
+	limit := len(ml.LineStrings) - 1
+	for idx, ls := range ml.LineStrings {
+		if len(ls.Coordinates) == 0 {
+			e.sb.WriteString(empty)
+		} else {
+			marshalCoords(ls.Coordinates, e)
+		}
+
+		if idx < limit {
+			e.sep()
+		}
+	}
+
+	e.sb.Write(rparen)
+
+	return nil
+}
+
+func marshalPolygon(p *geom.Polygon, e *encoder) error {
+	err := marshalHdr(polygon, p.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if len(p.Rings) == 0 {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.gap()
+	marshalRings(p.Rings, e)
+
+	return nil
+}
+
+func marshalMultiPolygon(mp *geom.MultiPolygon, e *encoder) error {
+	err := marshalHdr(multipolygon, mp.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if len(mp.Polygons) == 0 {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.open()
+
+	limit := len(mp.Polygons) - 1
+	for idx, p := range mp.Polygons {
+		if len(p.Rings) == 0 {
+			e.sb.WriteString(empty)
+		} else {
+			marshalRings(p.Rings, e)
+		}
+
+		if idx < limit {
+			e.sep()
+		}
+	}
+
+	e.sb.Write(rparen)
+
+	return nil
+}
+
+func marshalGeometryCollection(gc *geom.GeometryCollection, e *encoder) error {
+	err := marshalHdr(geometrycollection, gc.Dimension(), e)
+
+	if err != nil {
+		return err
+	}
+
+	if len(gc.Geometries) == 0 {
+		marshalEmpty(e)
+		return nil
+	}
+
+	e.open()
+
+	limit := len(gc.Geometries) - 1
+	for idx, g := range gc.Geometries {
+		err = marshal(g, e)
+
+		if err != nil {
+			return err
+		}
+
+		if idx < limit {
+			e.sep()
+		}
+	}
+
+	e.sb.Write(rparen)
+
+	return nil
+}
+
+func marshalRings(rings []geom.LinearRing, e *encoder) {
+	e.sb.Write(lparen)
+
+	limit := len(rings) - 1
+	for idx, ring := range rings {
+		marshalCoords(ring.Coordinates, e)
+
+		if idx < limit {
+			e.sep()
+		}
+	}
+
+	e.sb.Write(rparen)
+}
+
+func marshalCoords(coords []geom.Coordinate, e *encoder) {
+	e.sb.Write(lparen)
+
+	limit := len(coords) - 1
+	for idx, coord := range coords {
+		marshalCoord(coord, e)
+
+		if idx < limit {
+			e.sep()
+		}
+	}
+
+	e.sb.Write(rparen)
+}
+
+// marshalCoord writes the ordinates of c. WKT has no text for NaN or infinite ordinates, so they fail the encoding
+// with ErrNotFinite, apart from the NaN coordinate of an empty point.
+func marshalCoord(c geom.Coordinate, e *encoder) {
+	limit := len(c) - 1
+	for idx, comp := range c {
+		if e.err == nil && (math.IsNaN(comp) || math.IsInf(comp, 0)) {
+			e.err = ErrNotFinite
+		}
+
+		e.sb.WriteString(strconv.FormatFloat(comp, 'f', -1, 64))
+
+		if idx < limit {
+			e.sb.Write(space)
+		}
+	}
+}
+
+// isEmptyCoord reports whether a point coordinate represents an empty point. The WKB encoding of POINT EMPTY
+// uses NaN for every ordinate, so that is treated the same as a missing coordinate.
+func isEmptyCoord(c geom.Coordinate) bool {
+	for _, v := range c {
+		if !math.IsNaN(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func marshal(g geom.Geometry, e *encoder) error {
+	switch g := g.(type) {
+	case *geom.Point:
+		return marshalPoint(g, e)
+	case *geom.LineString:
+		return marshalLineString(g, e)
+	case *geom.Polygon:
+		return marshalPolygon(g, e)
+	case *geom.MultiPoint:
+		return marshalMultiPoint(g, e)
+	case *geom.MultiLineString:
+		return marshalMultiLineString(g, e)
+	case *geom.MultiPolygon:
+		return marshalMultiPolygon(g, e)
+	case *geom.GeometryCollection:
+		return marshalGeometryCollection(g, e)
+	default:
+		return geom.ErrUnsupportedGeom
+	}
+}
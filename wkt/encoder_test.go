@@ -0,0 +1,131 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wkt
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/devork/geom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	datasets := []struct {
+		data     geom.Geometry
+		expected string
+	}{
+		{&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{30, 10}}, "POINT (30 10)"},
+		{&geom.Point{geom.Hdr{geom.XYZ, 0}, geom.Coordinate{1, 2, 3}}, "POINT Z (1 2 3)"},
+		{&geom.Point{geom.Hdr{geom.XYM, 0}, geom.Coordinate{1, 2, 3}}, "POINT M (1 2 3)"},
+		{&geom.Point{geom.Hdr{geom.XYZM, 0}, geom.Coordinate{1, 2, 3, 4.5}}, "POINT ZM (1 2 3 4.5)"},
+		{&geom.Point{geom.Hdr{geom.XY, 0}, nil}, "POINT EMPTY"},
+		{&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{math.NaN(), math.NaN()}}, "POINT EMPTY"},
+		{&geom.LineString{geom.Hdr{geom.XY, 27700}, []geom.Coordinate{{30, 10}, {10, 30}, {40, 40}}}, "LINESTRING (30 10, 10 30, 40 40)"},
+		{&geom.LineString{geom.Hdr{geom.XYZ, 0}, nil}, "LINESTRING Z EMPTY"},
+		{
+			&geom.Polygon{geom.Hdr{geom.XY, 0}, []geom.LinearRing{
+				{[]geom.Coordinate{{35, 10}, {45, 45}, {15, 40}, {10, 20}, {35, 10}}},
+				{[]geom.Coordinate{{20, 30}, {35, 35}, {30, 20}, {20, 30}}},
+			}},
+			"POLYGON ((35 10, 45 45, 15 40, 10 20, 35 10), (20 30, 35 35, 30 20, 20 30))",
+		},
+		{&geom.Polygon{geom.Hdr{geom.XY, 0}, nil}, "POLYGON EMPTY"},
+		{
+			&geom.MultiPoint{geom.Hdr{geom.XY, 0}, []geom.Point{
+				{geom.Hdr{geom.XY, 0}, geom.Coordinate{10, 40}},
+				{geom.Hdr{geom.XY, 0}, geom.Coordinate{40, 30}},
+			}},
+			"MULTIPOINT ((10 40), (40 30))",
+		},
+		{
+			&geom.MultiLineString{geom.Hdr{geom.XYM, 0}, []geom.LineString{
+				{geom.Hdr{geom.XYM, 0}, []geom.Coordinate{{10, 10, 1}, {20, 20, 2}}},
+				{geom.Hdr{geom.XYM, 0}, []geom.Coordinate{{40, 40, 3}, {30, 30, 4}}},
+			}},
+			"MULTILINESTRING M ((10 10 1, 20 20 2), (40 40 3, 30 30 4))",
+		},
+		{
+			&geom.MultiPolygon{geom.Hdr{geom.XY, 0}, []geom.Polygon{
+				{geom.Hdr{geom.XY, 0}, []geom.LinearRing{{[]geom.Coordinate{{40, 40}, {20, 45}, {45, 30}, {40, 40}}}}},
+				{geom.Hdr{geom.XY, 0}, nil},
+			}},
+			"MULTIPOLYGON (((40 40, 20 45, 45 30, 40 40)), EMPTY)",
+		},
+		{
+			&geom.GeometryCollection{geom.Hdr{geom.XY, 0}, []geom.Geometry{
+				&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{4, 6}},
+				&geom.LineString{geom.Hdr{geom.XY, 0}, []geom.Coordinate{{4, 6}, {7, 10}}},
+			}},
+			"GEOMETRYCOLLECTION (POINT (4 6), LINESTRING (4 6, 7 10))",
+		},
+		{&geom.GeometryCollection{geom.Hdr{geom.XY, 0}, nil}, "GEOMETRYCOLLECTION EMPTY"},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+		err := Encode(dataset.data, w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", dataset.data.Type(), err)
+		}
+
+		assert.Equal(t, dataset.expected, w.String())
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	var w = new(bytes.Buffer)
+
+	assert.Equal(t, geom.ErrNoGeometry, Encode(nil, w))
+	assert.Equal(t, geom.ErrUnknownDim, Encode(&geom.Point{geom.Hdr{geom.UNKNOWN, 0}, geom.Coordinate{1, 2}}, w))
+}
+
+func TestEncodeEWKT(t *testing.T) {
+	datasets := []struct {
+		data     geom.Geometry
+		expected string
+	}{
+		{&geom.Point{geom.Hdr{geom.XY, 0}, geom.Coordinate{30, 10}}, "POINT(30 10)"},
+		{&geom.Point{geom.Hdr{geom.XYZM, 4326}, geom.Coordinate{1, 2, 3, 4}}, "SRID=4326;POINT(1 2 3 4)"},
+		{&geom.Point{geom.Hdr{geom.XYM, 4326}, geom.Coordinate{1, 2, 3}}, "SRID=4326;POINTM(1 2 3)"},
+		{&geom.Point{geom.Hdr{geom.XYM, 0}, nil}, "POINTM EMPTY"},
+		{&geom.LineString{geom.Hdr{geom.XY, 27700}, []geom.Coordinate{{30, 10}, {10, 30}, {40, 40}}}, "SRID=27700;LINESTRING(30 10,10 30,40 40)"},
+		{
+			&geom.GeometryCollection{geom.Hdr{geom.XYM, 27700}, []geom.Geometry{
+				&geom.Point{geom.Hdr{geom.XYM, 27700}, geom.Coordinate{4, 6, 1}},
+				&geom.MultiPoint{geom.Hdr{geom.XYM, 27700}, []geom.Point{
+					{geom.Hdr{geom.XYM, 27700}, geom.Coordinate{10, 40, 2}},
+					{geom.Hdr{geom.XYM, 27700}, geom.Coordinate{40, 30, 3}},
+				}},
+			}},
+			"SRID=27700;GEOMETRYCOLLECTIONM(POINTM(4 6 1),MULTIPOINTM(10 40 2,40 30 3))",
+		},
+	}
+
+	for _, dataset := range datasets {
+		var w = new(bytes.Buffer)
+		err := EncodeEWKT(dataset.data, w)
+
+		if err != nil {
+			t.Fatalf("Failed to encode %s geometry: err = %s", dataset.data.Type(), err)
+		}
+
+		assert.Equal(t, dataset.expected, w.String())
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright [2015] Alex Davies-Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wkt
+
+import (
+	"errors"
+
+	"github.com/devork/geom"
+)
+
+// Common error types
+var (
+	ErrTooDeep   = errors.New("wkt: geometry exceeds the maximum nesting depth")
+	ErrNotFinite = errors.New("wkt: cannot encode a NaN or infinite ordinate")
+)
+
+// Geometry tagged text keywords
+const (
+	point              = "POINT"
+	linestring         = "LINESTRING"
+	polygon            = "POLYGON"
+	multipoint         = "MULTIPOINT"
+	multilinestring    = "MULTILINESTRING"
+	multipolygon       = "MULTIPOLYGON"
+	geometrycollection = "GEOMETRYCOLLECTION"
+	empty              = "EMPTY"
+	sridKeyword        = "SRID"
+)
+
+// Dimension suffixes applied after the geometry keyword, e.g. POINT ZM (1 2 3 4)
+const (
+	z  = "Z"
+	m  = "M"
+	zm = "ZM"
+)
+
+// suffix returns the dimension modifier for the given dimension
+func suffix(d geom.Dimension) (string, error) {
+	switch d {
+	case geom.XY:
+		return "", nil
+	case geom.XYZ:
+		return z, nil
+	case geom.XYM:
+		return m, nil
+	case geom.XYZM:
+		return zm, nil
+	default:
+		return "", geom.ErrUnknownDim
+	}
+}
+
+// size returns the number of ordinates expected for each coordinate of the given dimension
+func size(d geom.Dimension) int {
+	switch d {
+	case geom.XYZ, geom.XYM:
+		return 3
+	case geom.XYZM:
+		return 4
+	default:
+		return 2
+	}
+}